@@ -0,0 +1,319 @@
+package user_management
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type AccessPolicyHandler struct {
+	accessPolicyService *services.AccessPolicyService
+}
+
+func NewAccessPolicyHandler(accessPolicyService *services.AccessPolicyService) *AccessPolicyHandler {
+	return &AccessPolicyHandler{
+		accessPolicyService: accessPolicyService,
+	}
+}
+
+// ListAccessPolicies godoc
+// @Summary List access policies
+// @Description List the attribute-based access policies of the current tenant
+// @Tags access-policies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} AccessPolicyResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/access-policies [get]
+func (h *AccessPolicyHandler) ListAccessPolicies(c *gin.Context) {
+	policies, err := h.accessPolicyService.ListPolicies(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list access policies"})
+		return
+	}
+
+	response := make([]AccessPolicyResponse, 0, len(policies))
+	for _, policy := range policies {
+		item, err := newAccessPolicyResponse(policy)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list access policies"})
+			return
+		}
+		response = append(response, item)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetAccessPolicy godoc
+// @Summary Get an access policy
+// @Description Get an access policy of the current tenant by ID
+// @Tags access-policies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Access policy ID"
+// @Success 200 {object} AccessPolicyResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/access-policies/{id} [get]
+func (h *AccessPolicyHandler) GetAccessPolicy(c *gin.Context) {
+	policy, err := h.accessPolicyService.GetPolicy(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondAccessPolicyError(c, err, "Failed to load access policy")
+		return
+	}
+
+	h.respondPolicy(c, http.StatusOK, policy)
+}
+
+// CreateAccessPolicy godoc
+// @Summary Create an access policy
+// @Description Create an attribute-based access policy in the current tenant. A matching deny policy refuses an action even if a role grants it; a matching allow policy grants an action no role grants.
+// @Tags access-policies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param policy body user_management.AccessPolicyInput true "Access policy"
+// @Success 201 {object} AccessPolicyResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/access-policies [post]
+func (h *AccessPolicyHandler) CreateAccessPolicy(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	var input services.AccessPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := h.accessPolicyService.CreatePolicy(c.Request.Context(), actor, input, clientInfo(c))
+	if err != nil {
+		respondAccessPolicyError(c, err, "Failed to create access policy")
+		return
+	}
+
+	h.respondPolicy(c, http.StatusCreated, policy)
+}
+
+// UpdateAccessPolicy godoc
+// @Summary Replace an access policy
+// @Description Replace an access policy of the current tenant
+// @Tags access-policies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Access policy ID"
+// @Param policy body user_management.AccessPolicyInput true "Access policy"
+// @Success 200 {object} AccessPolicyResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/access-policies/{id} [put]
+func (h *AccessPolicyHandler) UpdateAccessPolicy(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	var input services.AccessPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := h.accessPolicyService.UpdatePolicy(c.Request.Context(), actor, c.Param("id"), input, clientInfo(c))
+	if err != nil {
+		respondAccessPolicyError(c, err, "Failed to update access policy")
+		return
+	}
+
+	h.respondPolicy(c, http.StatusOK, policy)
+}
+
+// DeleteAccessPolicy godoc
+// @Summary Delete an access policy
+// @Description Delete an access policy of the current tenant
+// @Tags access-policies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Access policy ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/access-policies/{id} [delete]
+func (h *AccessPolicyHandler) DeleteAccessPolicy(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	if err := h.accessPolicyService.DeletePolicy(c.Request.Context(), actor, c.Param("id"), clientInfo(c)); err != nil {
+		respondAccessPolicyError(c, err, "Failed to delete access policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Access policy deleted successfully"})
+}
+
+// ExplainAccessDecision godoc
+// @Summary Explain an access decision
+// @Description Evaluate whether a user may perform an action without performing it, and show the attributes, role check and policy conditions that led to the decision
+// @Tags access-policies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ExplainAccessRequest true "Access request to evaluate"
+// @Success 200 {object} user_management.AccessDecision
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/access-policies/explain [post]
+func (h *AccessPolicyHandler) ExplainAccessDecision(c *gin.Context) {
+	var req ExplainAccessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accessRequest := services.AccessRequest{
+		Action:   req.Action,
+		Resource: req.Resource,
+		Context: services.AccessContext{
+			IP:            req.IP,
+			Time:          time.Now(),
+			ViaAPIKey:     req.ViaAPIKey,
+			MFAVerifiedAt: req.MFAVerifiedAt,
+		},
+	}
+	if accessRequest.Context.IP == "" {
+		accessRequest.Context.IP = c.ClientIP()
+	}
+	if req.Time != nil {
+		accessRequest.Context.Time = *req.Time
+	}
+
+	decision, err := h.accessPolicyService.Explain(c.Request.Context(), req.UserID, accessRequest)
+	if err != nil {
+		respondAccessPolicyError(c, err, "Failed to evaluate access request")
+		return
+	}
+
+	c.JSON(http.StatusOK, decision)
+}
+
+// SetUserAttributes godoc
+// @Summary Replace a user's attributes
+// @Description Replace the attributes, such as department, that access policies see as subject.attributes and resource.attributes
+// @Tags access-policies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param attributes body object true "Attributes"
+// @Success 200 {object} UserAttributesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/users/{id}/attributes [put]
+func (h *AccessPolicyHandler) SetUserAttributes(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	attributes := map[string]interface{}{}
+	if err := c.ShouldBindJSON(&attributes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.accessPolicyService.SetUserAttributes(c.Request.Context(), actor, c.Param("id"), attributes, clientInfo(c))
+	if err != nil {
+		respondAccessPolicyError(c, err, "Failed to update user attributes")
+		return
+	}
+
+	c.JSON(http.StatusOK, UserAttributesResponse{UserID: user.ID, Attributes: attributes})
+}
+
+func (h *AccessPolicyHandler) respondPolicy(c *gin.Context, status int, policy *models.AccessPolicy) {
+	response, err := newAccessPolicyResponse(policy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load access policy"})
+		return
+	}
+
+	c.JSON(status, response)
+}
+
+// respondAccessPolicyError maps AccessPolicyService errors to responses;
+// anything unexpected is reported as a 500 with fallback as the message.
+func respondAccessPolicyError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidAccessPolicy), err == services.ErrInvalidID, err == services.ErrInvalidUserAttribute:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err == services.ErrAccessPolicyNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Access policy not found"})
+	case err == services.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func newAccessPolicyResponse(policy *models.AccessPolicy) (AccessPolicyResponse, error) {
+	actions, conditions, err := services.DecodeAccessPolicy(policy)
+	if err != nil {
+		return AccessPolicyResponse{}, err
+	}
+
+	return AccessPolicyResponse{
+		ID:          policy.ID,
+		Name:        policy.Name,
+		Description: policy.Description,
+		Effect:      policy.Effect,
+		Actions:     actions,
+		Conditions:  conditions,
+		Enabled:     policy.Enabled,
+		CreatedAt:   policy.CreatedAt,
+		UpdatedAt:   policy.UpdatedAt,
+	}, nil
+}
+
+type ExplainAccessRequest struct {
+	UserID    string                 `json:"user_id" binding:"required"`
+	Action    string                 `json:"action" binding:"required"`
+	Resource  map[string]interface{} `json:"resource"`
+	IP        string                 `json:"ip"`
+	Time      *time.Time             `json:"time"`
+	ViaAPIKey bool                   `json:"via_api_key"`
+	// MFAVerifiedAt simulates a session whose second factor was verified at
+	// the given time; without it mfa_age_seconds is absent.
+	MFAVerifiedAt *time.Time `json:"mfa_verified_at"`
+}
+
+type AccessPolicyResponse struct {
+	ID          uuid.UUID                  `json:"id"`
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	Effect      models.AccessPolicyEffect  `json:"effect"`
+	Actions     []string                   `json:"actions"`
+	Conditions  []services.PolicyCondition `json:"conditions" swaggertype:"array,object"`
+	Enabled     bool                       `json:"enabled"`
+	CreatedAt   time.Time                  `json:"created_at"`
+	UpdatedAt   time.Time                  `json:"updated_at"`
+}
+
+type UserAttributesResponse struct {
+	UserID     uuid.UUID              `json:"user_id"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
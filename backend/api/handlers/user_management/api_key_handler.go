@@ -0,0 +1,185 @@
+package user_management
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create an API key limited to a subset of the caller's permissions. The key is only returned once.
+// @Tags API keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateAPIKeyRequest true "API key details"
+// @Success 201 {object} APIKeyCreatedResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rawKey, apiKey, err := h.apiKeyService.CreateAPIKey(c.Request.Context(), user, req.Name, req.Permissions, req.ExpiresAt, clientInfo(c))
+	if err != nil {
+		switch err {
+		case services.ErrAPIKeyPermissionDenied:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case services.ErrInvalidAPIKeyExpiration:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, APIKeyCreatedResponse{
+		APIKeyResponse: newAPIKeyResponse(apiKey),
+		Key:            rawKey,
+	})
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description List the caller's API keys without their secrets
+// @Tags API keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} APIKeyResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	apiKeys, err := h.apiKeyService.ListAPIKeys(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list API keys"})
+		return
+	}
+
+	response := make([]APIKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		response = append(response, newAPIKeyResponse(apiKey))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RotateAPIKey godoc
+// @Summary Rotate an API key
+// @Description Replace the secret of an API key. The previous secret stops working immediately and the new key is only returned once.
+// @Tags API keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "API key ID"
+// @Success 200 {object} APIKeyCreatedResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api-keys/{id}/rotate [post]
+func (h *APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	rawKey, apiKey, err := h.apiKeyService.RotateAPIKey(c.Request.Context(), user, c.Param("id"), clientInfo(c))
+	if err != nil {
+		if err == services.ErrAPIKeyNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate API key"})
+		return
+	}
+
+	c.JSON(http.StatusOK, APIKeyCreatedResponse{
+		APIKeyResponse: newAPIKeyResponse(apiKey),
+		Key:            rawKey,
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Permanently revoke one of the caller's API keys
+// @Tags API keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "API key ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	if err := h.apiKeyService.RevokeAPIKey(c.Request.Context(), user, c.Param("id"), clientInfo(c)); err != nil {
+		if err == services.ErrAPIKeyNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "API key revoked successfully"})
+}
+
+func newAPIKeyResponse(apiKey *models.APIKey) APIKeyResponse {
+	var permissions []string
+	_ = json.Unmarshal([]byte(apiKey.Permissions), &permissions)
+
+	return APIKeyResponse{
+		ID:          apiKey.ID,
+		Name:        apiKey.Name,
+		Prefix:      apiKey.Prefix,
+		Permissions: permissions,
+		ExpiresAt:   apiKey.ExpiresAt,
+		LastUsedAt:  apiKey.LastUsedAt,
+		CreatedAt:   apiKey.CreatedAt,
+	}
+}
+
+type CreateAPIKeyRequest struct {
+	Name        string     `json:"name" binding:"required,max=50"`
+	Permissions []string   `json:"permissions"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+type APIKeyResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Permissions []string   `json:"permissions"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type APIKeyCreatedResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
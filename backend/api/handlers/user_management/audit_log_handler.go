@@ -0,0 +1,140 @@
+package user_management
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type AuditLogHandler struct {
+	auditService *services.AuditService
+}
+
+func NewAuditLogHandler(auditService *services.AuditService) *AuditLogHandler {
+	return &AuditLogHandler{
+		auditService: auditService,
+	}
+}
+
+// ListAuditLogs godoc
+// @Summary List audit logs
+// @Description List audit log entries of the current tenant, newest first, using cursor pagination
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query string false "Filter by acting user ID"
+// @Param action query string false "Filter by action, e.g. auth.login"
+// @Param resource query string false "Filter by resource, e.g. user"
+// @Param from query string false "Only entries at or after this RFC 3339 time"
+// @Param to query string false "Only entries before this RFC 3339 time"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Success 200 {object} AuditLogListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/audit-logs [get]
+func (h *AuditLogHandler) ListAuditLogs(c *gin.Context) {
+	query := services.AuditLogQuery{
+		Action:   c.Query("action"),
+		Resource: c.Query("resource"),
+		Cursor:   c.Query("cursor"),
+	}
+
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id"})
+			return
+		}
+		query.UserID = &id
+	}
+
+	var err error
+	if query.From, err = parseTimeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from, expected RFC 3339 time"})
+		return
+	}
+	if query.To, err = parseTimeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to, expected RFC 3339 time"})
+		return
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		query.Limit = n
+	}
+
+	logs, nextCursor, err := h.auditService.Query(c.Request.Context(), query)
+	if err != nil {
+		if err == services.ErrInvalidAuditCursor {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load audit logs"})
+		return
+	}
+
+	items := make([]AuditLogResponse, 0, len(logs))
+	for _, log := range logs {
+		items = append(items, AuditLogResponse{
+			ID:         log.ID,
+			UserID:     log.UserID,
+			TenantID:   log.TenantID,
+			Action:     log.Action,
+			Resource:   log.Resource,
+			ResourceID: log.ResourceID,
+			Details:    json.RawMessage(log.Details),
+			IP:         log.IP,
+			RequestID:  log.RequestID,
+			CreatedAt:  log.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, AuditLogListResponse{
+		Items:      items,
+		NextCursor: nextCursor,
+	})
+}
+
+func parseTimeQuery(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+type AuditLogResponse struct {
+	ID         uuid.UUID       `json:"id"`
+	UserID     uuid.UUID       `json:"user_id"`
+	TenantID   uuid.UUID       `json:"tenant_id"`
+	Action     string          `json:"action"`
+	Resource   string          `json:"resource"`
+	ResourceID string          `json:"resource_id"`
+	Details    json.RawMessage `json:"details" swaggertype:"object"`
+	IP         string          `json:"ip"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type AuditLogListResponse struct {
+	Items      []AuditLogResponse `json:"items"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
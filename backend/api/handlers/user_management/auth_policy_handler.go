@@ -0,0 +1,84 @@
+package user_management
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type AuthPolicyHandler struct {
+	policyService *services.AuthPolicyService
+}
+
+func NewAuthPolicyHandler(policyService *services.AuthPolicyService) *AuthPolicyHandler {
+	return &AuthPolicyHandler{
+		policyService: policyService,
+	}
+}
+
+// GetAuthPolicy godoc
+// @Summary Get the tenant's auth policy
+// @Description Get the authentication policy of the current tenant, with defaults filled in for settings that were never configured
+// @Tags auth-policy
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} user_management.AuthPolicy
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/auth-policy [get]
+func (h *AuthPolicyHandler) GetAuthPolicy(c *gin.Context) {
+	tenant := currentTenant(c)
+
+	policy, err := h.policyService.GetPolicy(c.Request.Context(), tenant.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load auth policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// UpdateAuthPolicy godoc
+// @Summary Replace the tenant's auth policy
+// @Description Replace the authentication policy of the current tenant. Omitted settings are reset to their defaults. Changes apply to subsequent logins, registrations and token refreshes.
+// @Tags auth-policy
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param policy body user_management.AuthPolicy true "Auth policy"
+// @Success 200 {object} user_management.AuthPolicy
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/auth-policy [put]
+func (h *AuthPolicyHandler) UpdateAuthPolicy(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+	tenant := currentTenant(c)
+
+	policy := services.DefaultAuthPolicy()
+	if err := c.ShouldBindJSON(policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.policyService.UpdatePolicy(c.Request.Context(), actor, tenant.ID, policy, clientInfo(c)); err != nil {
+		if errors.Is(err, services.ErrInvalidAuthPolicy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update auth policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// currentTenant returns the tenant resolved by TenantMiddleware.
+func currentTenant(c *gin.Context) *models.Tenant {
+	return c.MustGet("tenant").(*models.Tenant)
+}
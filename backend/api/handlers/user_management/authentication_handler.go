@@ -1,7 +1,10 @@
 package user_management
 
 import (
+	"errors"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -10,26 +13,34 @@ import (
 	"github.com/josy-coder/adminsuite/internal/services/user_management"
 )
 
+// deviceCookieName is the cookie browsers use to carry the trusted device
+// token; API clients may send it as device_token in the login body instead.
+const deviceCookieName = "adminsuite_device"
+
 type AuthenticationHandler struct {
-	authService *user_management.AuthenticationService
-	mfaService  *user_management.MFAService
+	authService         *user_management.AuthenticationService
+	mfaService          *user_management.MFAService
+	verificationService *user_management.EmailVerificationService
+	deviceService       *user_management.DeviceService
 }
 
-func NewAuthenticationHandler(authService *user_management.AuthenticationService, mfaService *user_management.MFAService) *AuthenticationHandler {
+func NewAuthenticationHandler(authService *user_management.AuthenticationService, mfaService *user_management.MFAService, verificationService *user_management.EmailVerificationService, deviceService *user_management.DeviceService) *AuthenticationHandler {
 	return &AuthenticationHandler{
-		authService: authService,
-		mfaService:  mfaService,
+		authService:         authService,
+		mfaService:          mfaService,
+		verificationService: verificationService,
+		deviceService:       deviceService,
 	}
 }
 
 // Register godoc
 // @Summary Register a new user
-// @Description Register a new user with the provided details
+// @Description Register a new user with the provided details in the tenant resolved from the request host or X-Tenant header. The email domain and password must satisfy the tenant's auth policy.
 // @Tags authentication
 // @Accept json
 // @Produce json
 // @Param user body RegisterRequest true "User Registration Details"
-// @Success 201 {object} models.User
+// @Success 201 {object} RegisterResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /auth/register [post]
@@ -48,17 +59,29 @@ func (h *AuthenticationHandler) Register(c *gin.Context) {
 		LastName:  req.LastName,
 	}
 
-	if err := h.authService.RegisterUser(user); err != nil {
+	if err := h.authService.RegisterUser(c.Request.Context(), user, clientInfo(c)); err != nil {
+		if errors.Is(err, user_management.ErrPasswordPolicy) || err == user_management.ErrEmailDomainNotAllowed {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, user)
+	c.JSON(http.StatusCreated, RegisterResponse{
+		ID:            user.ID,
+		Email:         user.Email,
+		Username:      user.Username,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		EmailVerified: user.EmailVerified,
+		CreatedAt:     user.CreatedAt,
+	})
 }
 
 // Login godoc
 // @Summary Authenticate a user
-// @Description Authenticate a user with email and password
+// @Description Authenticate a user with email and password. MFA is skipped when a valid trusted device token is supplied in the body or the device cookie. When the tenant requires MFA and the user has not enrolled, mfa_enrollment_required is set and only the /mfa endpoints accept the issued token.
 // @Tags authentication
 // @Accept json
 // @Produce json
@@ -66,6 +89,8 @@ func (h *AuthenticationHandler) Register(c *gin.Context) {
 // @Success 200 {object} LoginResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /auth/login [post]
 func (h *AuthenticationHandler) Login(c *gin.Context) {
@@ -75,10 +100,15 @@ func (h *AuthenticationHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, accessToken, refreshToken, err := h.authService.AuthenticateUser(req.Email, req.Password)
+	deviceToken := req.DeviceToken
+	if deviceToken == "" {
+		deviceToken, _ = c.Cookie(deviceCookieName)
+	}
+
+	user, accessToken, refreshToken, err := h.authService.AuthenticateUser(c.Request.Context(), req.Email, req.Password, deviceToken, clientInfo(c))
 	if err != nil {
 		if err == user_management.ErrMFARequired {
-			tempToken, err := h.authService.GenerateTempToken(user.ID)
+			tempToken, err := h.authService.GenerateTempToken(user.ID, nil)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate temporary token"})
 				return
@@ -90,24 +120,43 @@ func (h *AuthenticationHandler) Login(c *gin.Context) {
 			})
 			return
 		}
+		if err == user_management.ErrEmailNotVerified {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Email address not verified"})
+			return
+		}
+		if err == user_management.ErrAccountDisabled {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+			return
+		}
+		if err == user_management.ErrAccountLocked || err == user_management.ErrTooManyAttempts {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, try again later"})
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
+	enrollmentRequired, err := h.authService.RequiresMFAEnrollment(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load auth policy"})
+		return
+	}
+
 	c.JSON(http.StatusOK, LoginResponse{
 		User: UserResponse{
 			ID:       user.ID,
 			Email:    user.Email,
 			Username: user.Username,
 		},
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		MFAEnrollmentRequired: enrollmentRequired,
 	})
 }
 
 // RefreshToken godoc
 // @Summary Refresh access token
-// @Description Refresh the access token using a valid refresh token
+// @Description Exchange a refresh token for a new access and refresh token. Each refresh token can be used once; presenting one again signs out the session it belongs to.
 // @Tags authentication
 // @Accept json
 // @Produce json
@@ -123,7 +172,7 @@ func (h *AuthenticationHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	newAccessToken, newRefreshToken, err := h.authService.RefreshToken(refreshToken)
+	newAccessToken, newRefreshToken, err := h.authService.RefreshToken(c.Request.Context(), refreshToken, clientInfo(c))
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
 		return
@@ -135,9 +184,65 @@ func (h *AuthenticationHandler) RefreshToken(c *gin.Context) {
 	})
 }
 
+// Logout godoc
+// @Summary Log out
+// @Description End the session the refresh token belongs to. Its refresh tokens and the access tokens issued in it stop working immediately. An access token sent as bearer token is revoked as well.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param refresh_token header string true "Refresh Token"
+// @Param Authorization header string false "Bearer access token"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/logout [post]
+func (h *AuthenticationHandler) Logout(c *gin.Context) {
+	refreshToken := c.GetHeader("Refresh-Token")
+	if refreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Refresh token is required"})
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), refreshToken, bearerToken(c), clientInfo(c)); err != nil {
+		if err == user_management.ErrInvalidRefreshToken {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Logged out successfully"})
+}
+
+// GetTokenKeys godoc
+// @Summary Get the token verification keys
+// @Description Get the public keys that verify AdminSuite tokens. Tokens are v2.public PASETOs whose footer names the verifying key as kid, so other services can verify them offline. Keys are PASERK k2.public strings and key IDs PASERK k2.pid strings. After a key rotation the set holds both the new key and the rotated one until its grace period ends.
+// @Tags authentication
+// @Produce json
+// @Success 200 {object} TokenKeySetResponse
+// @Router /auth/keys [get]
+func (h *AuthenticationHandler) GetTokenKeys(c *gin.Context) {
+	keys := h.authService.PublicKeys()
+
+	response := TokenKeySetResponse{Keys: make([]TokenKeyResponse, 0, len(keys))}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, TokenKeyResponse{
+			KeyID:     key.ID,
+			Version:   "v2",
+			Purpose:   "public",
+			PublicKey: key.PASERK(),
+		})
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, response)
+}
+
 // VerifyMFA godoc
 // @Summary Verify MFA token
-// @Description Verify the MFA token provided by the user
+// @Description Verify the MFA token provided by the user. With remember_device set, the response carries a device token that skips MFA on later logins from this device. If the tenant's policy no longer allows the user's MFA method, mfa_enrollment_required is set and only the MFA endpoints can be used until an allowed method is enrolled.
 // @Tags authentication
 // @Accept json
 // @Produce json
@@ -145,6 +250,7 @@ func (h *AuthenticationHandler) RefreshToken(c *gin.Context) {
 // @Success 200 {object} LoginResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /auth/verify-mfa [post]
 func (h *AuthenticationHandler) VerifyMFA(c *gin.Context) {
@@ -154,47 +260,195 @@ func (h *AuthenticationHandler) VerifyMFA(c *gin.Context) {
 		return
 	}
 
-	user, err := h.authService.GetUserByTempToken(req.TempToken)
+	user, roleSync, err := h.authService.GetUserByTempToken(c.Request.Context(), req.TempToken)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid temporary token"})
 		return
 	}
 
-	var valid bool
-	switch user.MFAMethod {
-	case models.MFAMethodTOTP:
-		valid, err = h.mfaService.VerifyTOTP(user, req.MFAToken)
-	case models.MFAMethodSMS:
-		valid, err = h.mfaService.VerifySMSCode(user, req.MFAToken)
-	case models.MFAMethodEmail:
-		valid, err = h.mfaService.VerifyEmailCode(user, req.MFAToken)
-	default:
+	valid, err := h.authService.VerifyMFALogin(c.Request.Context(), user, req.MFAToken, roleSync, clientInfo(c))
+	if err == user_management.ErrUnsupportedMFAMethod {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid MFA method"})
 		return
 	}
-
+	if err == user_management.ErrAccountLocked || err == user_management.ErrTooManyAttempts {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, try again later"})
+		return
+	}
 	if err != nil || !valid {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid MFA token"})
 		return
 	}
 
-	accessToken, refreshToken, err := h.authService.GenerateTokens(user)
+	var deviceToken string
+	var device *models.Device
+	if req.RememberDevice {
+		deviceToken, device, err = h.deviceService.TrustDevice(c.Request.Context(), user, req.DeviceName, req.DeviceType, clientInfo(c))
+		if err != nil && err != user_management.ErrTrustedDevicesDisabled {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to trust device"})
+			return
+		}
+	}
+
+	var deviceID *uuid.UUID
+	if device != nil {
+		deviceID = &device.ID
+		maxAge := int(time.Until(device.TrustedUntil).Seconds())
+		c.SetSameSite(http.SameSiteStrictMode)
+		c.SetCookie(deviceCookieName, deviceToken, maxAge, "/api/v1/auth", "", true, true)
+	}
+
+	accessToken, refreshToken, err := h.authService.GenerateTokensForDevice(c.Request.Context(), user, deviceID, user_management.AuthMethodMFA, clientInfo(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
 		return
 	}
 
+	enrollmentRequired, err := h.authService.RequiresMFAEnrollment(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load auth policy"})
+		return
+	}
+
 	c.JSON(http.StatusOK, LoginResponse{
 		User: UserResponse{
 			ID:       user.ID,
 			Email:    user.Email,
 			Username: user.Username,
 		},
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		DeviceToken:           deviceToken,
+		MFAEnrollmentRequired: enrollmentRequired,
 	})
 }
 
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Send a password reset link to the given email address if an account exists for it. The response is the same whether or not an account exists or the email could be sent. The link carries the token and the user's tenant ID as the token and tenant query parameters; the tenant is to be sent as X-Tenant header when resetting the password.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Account email"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/password/forgot [post]
+func (h *AuthenticationHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.authService.RequestPasswordReset(c.Request.Context(), req.Email)
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "If an account exists for this email, a password reset link has been sent"})
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password using a password reset token and sign out all sessions
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/password/reset [post]
+func (h *AuthenticationHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.ResetPassword(c.Request.Context(), req.Token, req.Password, clientInfo(c)); err != nil {
+		if err == user_management.ErrInvalidResetToken {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+			return
+		}
+		if errors.Is(err, user_management.ErrPasswordPolicy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Password reset successfully"})
+}
+
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Description Mark the user's email address as verified using the token from the verification email. The link in the email carries the user's tenant ID as the tenant query parameter, to be sent as X-Tenant header.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body VerifyEmailRequest true "Verification token"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/email/verify [post]
+func (h *AuthenticationHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.verificationService.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		if err == user_management.ErrInvalidVerificationToken {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email address"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Email address verified successfully"})
+}
+
+// ResendVerificationEmail godoc
+// @Summary Resend verification email
+// @Description Send a new email verification link if an unverified account exists for the given email. The response is the same whether or not an account exists or the email could be sent.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body ResendVerificationRequest true "Account email"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/email/resend [post]
+func (h *AuthenticationHandler) ResendVerificationEmail(c *gin.Context) {
+	var req ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.verificationService.ResendVerificationEmail(c.Request.Context(), req.Email)
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "If an unverified account exists for this email, a verification link has been sent"})
+}
+
+func clientInfo(c *gin.Context) user_management.ClientInfo {
+	return user_management.ClientInfo{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		RequestID: c.GetString("request_id"),
+	}
+}
+
+// bearerToken returns the token of a bearer Authorization header, or an
+// empty string if the request has none.
+func bearerToken(c *gin.Context) string {
+	scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "bearer") {
+		return ""
+	}
+	return token
+}
+
 type RegisterRequest struct {
 	Email     string `json:"email" binding:"required,email"`
 	Username  string `json:"username" binding:"required"`
@@ -204,19 +458,42 @@ type RegisterRequest struct {
 }
 
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email       string `json:"email" binding:"required,email"`
+	Password    string `json:"password" binding:"required"`
+	DeviceToken string `json:"device_token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
 }
 
 type MFAVerificationRequest struct {
-	TempToken string `json:"temp_token" binding:"required"`
-	MFAToken  string `json:"mfa_token" binding:"required"`
+	TempToken      string `json:"temp_token" binding:"required"`
+	MFAToken       string `json:"mfa_token" binding:"required"`
+	RememberDevice bool   `json:"remember_device"`
+	DeviceName     string `json:"device_name" binding:"max=50"`
+	DeviceType     string `json:"device_type" binding:"max=20"`
 }
 
 type LoginResponse struct {
-	User         UserResponse `json:"user"`
-	AccessToken  string       `json:"access_token"`
-	RefreshToken string       `json:"refresh_token"`
+	User                  UserResponse `json:"user"`
+	AccessToken           string       `json:"access_token"`
+	RefreshToken          string       `json:"refresh_token"`
+	DeviceToken           string       `json:"device_token,omitempty"`
+	MFAEnrollmentRequired bool         `json:"mfa_enrollment_required,omitempty"`
 }
 
 type UserResponse struct {
@@ -225,6 +502,16 @@ type UserResponse struct {
 	Username string    `json:"username"`
 }
 
+type RegisterResponse struct {
+	ID            uuid.UUID `json:"id"`
+	Email         string    `json:"email"`
+	Username      string    `json:"username"`
+	FirstName     string    `json:"first_name"`
+	LastName      string    `json:"last_name"`
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
 type TokenResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
@@ -233,3 +520,14 @@ type TokenResponse struct {
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
+
+type TokenKeySetResponse struct {
+	Keys []TokenKeyResponse `json:"keys"`
+}
+
+type TokenKeyResponse struct {
+	KeyID     string `json:"kid"`
+	Version   string `json:"version"`
+	Purpose   string `json:"purpose"`
+	PublicKey string `json:"public_key"`
+}
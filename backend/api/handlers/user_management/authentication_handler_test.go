@@ -0,0 +1,67 @@
+package user_management
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/josy-coder/adminsuite/internal/config"
+	"github.com/josy-coder/adminsuite/internal/database/databasetest"
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+	"github.com/josy-coder/adminsuite/internal/tenancy"
+)
+
+func TestResendVerificationEmailDoesNotRevealAccounts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := databasetest.Open(t, &models.Tenant{}, &models.User{}, &models.AuditLog{})
+	tenant := &models.Tenant{Name: "Acme", Domain: "acme.test"}
+	if err := db.Create(tenant).Error; err != nil {
+		t.Fatalf("failed to create tenant: %v", err)
+	}
+	ctx := tenancy.WithTenant(context.Background(), tenant.ID)
+
+	userRepo := user_management.NewUserRepository(db)
+	if err := userRepo.Create(ctx, &models.User{Email: "amy@acme.test", Username: "amy", Password: "hash", IsActive: true}); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	signingKey, err := services.GenerateSigningKey()
+	if err != nil {
+		t.Fatalf("failed to generate signing key: %v", err)
+	}
+	keyRing, err := services.NewKeyRing(ctx, services.NewStaticKeyStore(signingKey), nil)
+	if err != nil {
+		t.Fatalf("failed to create key ring: %v", err)
+	}
+	// The SMTP server refuses connections, so sending the email fails.
+	emailService := services.NewEmailService(&config.Config{SMTPHost: "127.0.0.1", SMTPPort: 1, SMTPFrom: "noreply@acme.test"})
+	brandingService := services.NewBrandingService(user_management.NewTenantRepository(db), services.NewAuditService(user_management.NewAuditLogRepository(db)))
+	verificationService := services.NewEmailVerificationService(userRepo, emailService, brandingService, services.NewTokenSigner(keyRing), "https://app.acme.test")
+	handler := NewAuthenticationHandler(nil, nil, verificationService, nil)
+
+	resend := func(email string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/auth/email/resend", strings.NewReader(`{"email":"`+email+`"}`))
+		request = request.WithContext(ctx)
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = request
+		handler.ResendVerificationEmail(c)
+		return recorder
+	}
+
+	registered, unknown := resend("amy@acme.test"), resend("nobody@acme.test")
+	if registered.Code != http.StatusOK || unknown.Code != http.StatusOK {
+		t.Fatalf("status = %d for a registered and %d for an unknown address, want 200 for both", registered.Code, unknown.Code)
+	}
+	if registered.Body.String() != unknown.Body.String() {
+		t.Fatalf("responses differ: %s and %s", registered.Body, unknown.Body)
+	}
+}
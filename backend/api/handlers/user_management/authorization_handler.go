@@ -0,0 +1,592 @@
+package user_management
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type AuthorizationHandler struct {
+	authorizationService *services.AuthorizationService
+}
+
+func NewAuthorizationHandler(authorizationService *services.AuthorizationService) *AuthorizationHandler {
+	return &AuthorizationHandler{
+		authorizationService: authorizationService,
+	}
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description List the roles of the current tenant with their permissions
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} RoleResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/roles [get]
+func (h *AuthorizationHandler) ListRoles(c *gin.Context) {
+	roles, err := h.authorizationService.ListRoles(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list roles"})
+		return
+	}
+
+	response := make([]RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		response = append(response, newRoleResponse(role))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRole godoc
+// @Summary Get a role
+// @Description Get a role of the current tenant by ID
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Success 200 {object} RoleResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/roles/{id} [get]
+func (h *AuthorizationHandler) GetRole(c *gin.Context) {
+	role, err := h.authorizationService.GetRole(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondAuthorizationError(c, err, "Failed to load role")
+		return
+	}
+
+	c.JSON(http.StatusOK, newRoleResponse(role))
+}
+
+// CreateRole godoc
+// @Summary Create a role
+// @Description Create a role in the current tenant. A parent role must not grant permissions the caller lacks, and the name Admin is reserved for the built-in role.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateRoleRequest true "Role details"
+// @Success 201 {object} RoleResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/roles [post]
+func (h *AuthorizationHandler) CreateRole(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := services.RoleInput{
+		Name:        &req.Name,
+		Description: &req.Description,
+	}
+	if req.ParentID != "" {
+		input.ParentID = &req.ParentID
+	}
+
+	role, err := h.authorizationService.CreateRole(c.Request.Context(), actor, input, clientInfo(c))
+	if err != nil {
+		respondAuthorizationError(c, err, "Failed to create role")
+		return
+	}
+
+	c.JSON(http.StatusCreated, newRoleResponse(role))
+}
+
+// UpdateRole godoc
+// @Summary Update a role
+// @Description Update the name, description or parent of a role. Omitted fields are left unchanged; an empty parent_id removes the parent. The caller must hold every permission the role grants once updated, including inherited ones. The Admin role cannot be renamed, and no other role can take its name.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Param request body UpdateRoleRequest true "Role fields to change"
+// @Success 200 {object} RoleResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/roles/{id} [patch]
+func (h *AuthorizationHandler) UpdateRole(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.authorizationService.UpdateRole(c.Request.Context(), actor, c.Param("id"), services.RoleInput{
+		Name:        req.Name,
+		Description: req.Description,
+		ParentID:    req.ParentID,
+	}, clientInfo(c))
+	if err != nil {
+		respondAuthorizationError(c, err, "Failed to update role")
+		return
+	}
+
+	c.JSON(http.StatusOK, newRoleResponse(role))
+}
+
+// DeleteRole godoc
+// @Summary Delete a role
+// @Description Delete a role and remove it from every user. The Admin role cannot be deleted.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/roles/{id} [delete]
+func (h *AuthorizationHandler) DeleteRole(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	if err := h.authorizationService.DeleteRole(c.Request.Context(), actor, c.Param("id"), clientInfo(c)); err != nil {
+		respondAuthorizationError(c, err, "Failed to delete role")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Role deleted successfully"})
+}
+
+// AssignPermissionToRole godoc
+// @Summary Grant a permission to a role
+// @Description Add a permission to a role. Callers can only grant permissions they hold themselves. Granting a permission the role already has is a no-op.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Param permissionId path string true "Permission ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/roles/{id}/permissions/{permissionId} [post]
+func (h *AuthorizationHandler) AssignPermissionToRole(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	if err := h.authorizationService.AssignPermissionToRole(c.Request.Context(), actor, c.Param("id"), c.Param("permissionId"), clientInfo(c)); err != nil {
+		respondAuthorizationError(c, err, "Failed to assign permission")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Permission assigned successfully"})
+}
+
+// UnassignPermissionFromRole godoc
+// @Summary Revoke a permission from a role
+// @Description Remove a permission from a role
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID"
+// @Param permissionId path string true "Permission ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/roles/{id}/permissions/{permissionId} [delete]
+func (h *AuthorizationHandler) UnassignPermissionFromRole(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	if err := h.authorizationService.UnassignPermissionFromRole(c.Request.Context(), actor, c.Param("id"), c.Param("permissionId"), clientInfo(c)); err != nil {
+		respondAuthorizationError(c, err, "Failed to unassign permission")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Permission unassigned successfully"})
+}
+
+// ListPermissions godoc
+// @Summary List permissions
+// @Description List the permissions of the current tenant
+// @Tags permissions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} PermissionResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/permissions [get]
+func (h *AuthorizationHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.authorizationService.ListPermissions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list permissions"})
+		return
+	}
+
+	response := make([]PermissionResponse, 0, len(permissions))
+	for _, permission := range permissions {
+		response = append(response, newPermissionResponse(permission))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPermission godoc
+// @Summary Get a permission
+// @Description Get a permission of the current tenant by ID
+// @Tags permissions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Permission ID"
+// @Success 200 {object} PermissionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/permissions/{id} [get]
+func (h *AuthorizationHandler) GetPermission(c *gin.Context) {
+	permission, err := h.authorizationService.GetPermission(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondAuthorizationError(c, err, "Failed to load permission")
+		return
+	}
+
+	c.JSON(http.StatusOK, newPermissionResponse(permission))
+}
+
+// CreatePermission godoc
+// @Summary Create a permission
+// @Description Create a permission in the current tenant. Names have the form resource:action and cannot contain wildcards.
+// @Tags permissions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreatePermissionRequest true "Permission details"
+// @Success 201 {object} PermissionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/permissions [post]
+func (h *AuthorizationHandler) CreatePermission(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	var req CreatePermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	permission, err := h.authorizationService.CreatePermission(c.Request.Context(), actor, services.PermissionInput{
+		Name:        &req.Name,
+		Description: &req.Description,
+	}, clientInfo(c))
+	if err != nil {
+		respondAuthorizationError(c, err, "Failed to create permission")
+		return
+	}
+
+	c.JSON(http.StatusCreated, newPermissionResponse(permission))
+}
+
+// UpdatePermission godoc
+// @Summary Update a permission
+// @Description Update the name or description of a permission. Omitted fields are left unchanged. Renaming a permission requires holding both the old and the new name, and names cannot contain wildcards.
+// @Tags permissions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Permission ID"
+// @Param request body UpdatePermissionRequest true "Permission fields to change"
+// @Success 200 {object} PermissionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/permissions/{id} [patch]
+func (h *AuthorizationHandler) UpdatePermission(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	var req UpdatePermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	permission, err := h.authorizationService.UpdatePermission(c.Request.Context(), actor, c.Param("id"), services.PermissionInput{
+		Name:        req.Name,
+		Description: req.Description,
+	}, clientInfo(c))
+	if err != nil {
+		respondAuthorizationError(c, err, "Failed to update permission")
+		return
+	}
+
+	c.JSON(http.StatusOK, newPermissionResponse(permission))
+}
+
+// DeletePermission godoc
+// @Summary Delete a permission
+// @Description Delete a permission and remove it from every role
+// @Tags permissions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Permission ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/permissions/{id} [delete]
+func (h *AuthorizationHandler) DeletePermission(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	if err := h.authorizationService.DeletePermission(c.Request.Context(), actor, c.Param("id"), clientInfo(c)); err != nil {
+		respondAuthorizationError(c, err, "Failed to delete permission")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Permission deleted successfully"})
+}
+
+// AssignRoleToUser godoc
+// @Summary Assign a role to a user
+// @Description Add a role to a user of the current tenant. Requires roles:assign or roles:write, and callers can only assign roles whose permissions, including inherited ones, they hold themselves. Assigning a role the user already has is a no-op.
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param roleId path string true "Role ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/users/{id}/roles/{roleId} [post]
+func (h *AuthorizationHandler) AssignRoleToUser(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	if err := h.authorizationService.AssignRoleToUser(c.Request.Context(), actor, c.Param("id"), c.Param("roleId"), clientInfo(c)); err != nil {
+		respondAuthorizationError(c, err, "Failed to assign role")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Role assigned successfully"})
+}
+
+// UnassignRoleFromUser godoc
+// @Summary Remove a role from a user
+// @Description Remove a role from a user of the current tenant
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param roleId path string true "Role ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/users/{id}/roles/{roleId} [delete]
+func (h *AuthorizationHandler) UnassignRoleFromUser(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	if err := h.authorizationService.UnassignRoleFromUser(c.Request.Context(), actor, c.Param("id"), c.Param("roleId"), clientInfo(c)); err != nil {
+		respondAuthorizationError(c, err, "Failed to unassign role")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Role unassigned successfully"})
+}
+
+// GetEffectivePermissions godoc
+// @Summary Get a user's effective permissions
+// @Description List every permission a user holds, including those inherited from parent roles, together with the roles that grant it
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} EffectivePermissionsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/users/{id}/effective-permissions [get]
+func (h *AuthorizationHandler) GetEffectivePermissions(c *gin.Context) {
+	user, grants, err := h.authorizationService.EffectivePermissions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondAuthorizationError(c, err, "Failed to resolve permissions")
+		return
+	}
+
+	c.JSON(http.StatusOK, newEffectivePermissionsResponse(user, grants))
+}
+
+// respondAuthorizationError maps AuthorizationService errors to responses;
+// anything unexpected is reported as a 500 with fallback as the message.
+func respondAuthorizationError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case services.ErrInvalidID, services.ErrProtectedRole, services.ErrInvalidParentRole, services.ErrRoleCycle, services.ErrInvalidPermission, services.ErrWildcardPermission:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case services.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case services.ErrRoleNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+	case services.ErrPermissionNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Permission not found"})
+	case services.ErrRoleNameTaken, services.ErrPermissionNameTaken, services.ErrReservedRoleName:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case services.ErrPermissionNotHeld:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func newRoleResponse(role *models.Role) RoleResponse {
+	permissions := make([]PermissionResponse, 0, len(role.Permissions))
+	for i := range role.Permissions {
+		permissions = append(permissions, newPermissionResponse(&role.Permissions[i]))
+	}
+
+	return RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		IsAdmin:     role.IsAdmin,
+		ParentID:    role.ParentID,
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+// newEffectivePermissionsResponse groups grants by permission name so each
+// permission is listed once with every role that grants it.
+func newEffectivePermissionsResponse(user *models.User, grants []services.PermissionGrant) EffectivePermissionsResponse {
+	sources := make(map[string][]PermissionSource)
+	for _, grant := range grants {
+		source := PermissionSource{SuperAdmin: grant.Role == nil}
+		if grant.Role != nil {
+			source.RoleID = &grant.Role.ID
+			source.RoleName = grant.Role.Name
+			source.AssignedRoleID = &grant.AssignedRole.ID
+			source.AssignedRoleName = grant.AssignedRole.Name
+			source.Inherited = grant.Role.ID != grant.AssignedRole.ID
+		}
+		sources[grant.Permission] = append(sources[grant.Permission], source)
+	}
+
+	permissions := make([]EffectivePermission, 0, len(sources))
+	for name, grantedBy := range sources {
+		permissions = append(permissions, EffectivePermission{Permission: name, GrantedBy: grantedBy})
+	}
+	sort.Slice(permissions, func(i, j int) bool {
+		return permissions[i].Permission < permissions[j].Permission
+	})
+
+	return EffectivePermissionsResponse{
+		UserID:      user.ID,
+		SuperAdmin:  user.IsSuperAdmin,
+		Permissions: permissions,
+	}
+}
+
+func newPermissionResponse(permission *models.Permission) PermissionResponse {
+	return PermissionResponse{
+		ID:          permission.ID,
+		Name:        permission.Name,
+		Description: permission.Description,
+		CreatedAt:   permission.CreatedAt,
+		UpdatedAt:   permission.UpdatedAt,
+	}
+}
+
+type CreateRoleRequest struct {
+	Name        string `json:"name" binding:"required,max=50"`
+	Description string `json:"description" binding:"max=255"`
+	ParentID    string `json:"parent_id"`
+}
+
+type UpdateRoleRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=50"`
+	Description *string `json:"description" binding:"omitempty,max=255"`
+	ParentID    *string `json:"parent_id"`
+}
+
+type CreatePermissionRequest struct {
+	Name        string `json:"name" binding:"required,max=50"`
+	Description string `json:"description" binding:"max=255"`
+}
+
+type UpdatePermissionRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=50"`
+	Description *string `json:"description" binding:"omitempty,max=255"`
+}
+
+type RoleResponse struct {
+	ID          uuid.UUID            `json:"id"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	IsAdmin     bool                 `json:"is_admin"`
+	ParentID    *uuid.UUID           `json:"parent_id"`
+	Permissions []PermissionResponse `json:"permissions"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+type PermissionResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type EffectivePermissionsResponse struct {
+	UserID      uuid.UUID             `json:"user_id"`
+	SuperAdmin  bool                  `json:"super_admin"`
+	Permissions []EffectivePermission `json:"permissions"`
+}
+
+type EffectivePermission struct {
+	Permission string             `json:"permission"`
+	GrantedBy  []PermissionSource `json:"granted_by"`
+}
+
+// PermissionSource describes one way a user holds a permission: through
+// Role, reached from the role they were assigned, or as a super admin.
+type PermissionSource struct {
+	RoleID           *uuid.UUID `json:"role_id,omitempty"`
+	RoleName         string     `json:"role_name,omitempty"`
+	AssignedRoleID   *uuid.UUID `json:"assigned_role_id,omitempty"`
+	AssignedRoleName string     `json:"assigned_role_name,omitempty"`
+	Inherited        bool       `json:"inherited"`
+	SuperAdmin       bool       `json:"super_admin"`
+}
@@ -0,0 +1,111 @@
+package user_management
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type BrandingHandler struct {
+	brandingService *services.BrandingService
+}
+
+func NewBrandingHandler(brandingService *services.BrandingService) *BrandingHandler {
+	return &BrandingHandler{
+		brandingService: brandingService,
+	}
+}
+
+// GetPublicBranding godoc
+// @Summary Get branding for a domain
+// @Description Get the branding of the active tenant served on the given domain. Does not require authentication, so the frontend can render the login page.
+// @Tags branding
+// @Accept json
+// @Produce json
+// @Param domain query string true "Tenant domain"
+// @Success 200 {object} user_management.Branding
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /branding [get]
+func (h *BrandingHandler) GetPublicBranding(c *gin.Context) {
+	domain := c.Query("domain")
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "domain is required"})
+		return
+	}
+
+	branding, err := h.brandingService.GetBrandingByDomain(c.Request.Context(), domain)
+	if err != nil {
+		if err == services.ErrTenantNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load branding"})
+		return
+	}
+
+	c.JSON(http.StatusOK, branding)
+}
+
+// GetBranding godoc
+// @Summary Get the tenant's branding
+// @Description Get the branding of the current tenant, with defaults filled in for settings that were never configured
+// @Tags branding
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} user_management.Branding
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/branding [get]
+func (h *BrandingHandler) GetBranding(c *gin.Context) {
+	tenant := currentTenant(c)
+
+	branding, err := h.brandingService.GetBranding(c.Request.Context(), tenant.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load branding"})
+		return
+	}
+
+	c.JSON(http.StatusOK, branding)
+}
+
+// UpdateBranding godoc
+// @Summary Replace the tenant's branding
+// @Description Replace the branding of the current tenant. Omitted settings are reset to their defaults.
+// @Tags branding
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param branding body user_management.Branding true "Branding"
+// @Success 200 {object} user_management.Branding
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/branding [put]
+func (h *BrandingHandler) UpdateBranding(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+	tenant := currentTenant(c)
+
+	branding := services.DefaultBranding()
+	if err := c.ShouldBindJSON(branding); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.brandingService.UpdateBranding(c.Request.Context(), actor, tenant.ID, branding, clientInfo(c)); err != nil {
+		if errors.Is(err, services.ErrInvalidBranding) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update branding"})
+		return
+	}
+
+	c.JSON(http.StatusOK, branding)
+}
@@ -0,0 +1,140 @@
+package user_management
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type DeviceHandler struct {
+	deviceService *services.DeviceService
+}
+
+func NewDeviceHandler(deviceService *services.DeviceService) *DeviceHandler {
+	return &DeviceHandler{
+		deviceService: deviceService,
+	}
+}
+
+// ListDevices godoc
+// @Summary List trusted devices
+// @Description List the devices the caller has marked as trusted, including expired ones
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} DeviceResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /me/devices [get]
+func (h *DeviceHandler) ListDevices(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	devices, err := h.deviceService.ListDevices(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list devices"})
+		return
+	}
+
+	response := make([]DeviceResponse, 0, len(devices))
+	for _, device := range devices {
+		response = append(response, newDeviceResponse(device))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RenameDevice godoc
+// @Summary Rename a trusted device
+// @Description Change the display name of one of the caller's trusted devices
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Device ID"
+// @Param request body RenameDeviceRequest true "New device name"
+// @Success 200 {object} DeviceResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /me/devices/{id} [patch]
+func (h *DeviceHandler) RenameDevice(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	var req RenameDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	device, err := h.deviceService.RenameDevice(c.Request.Context(), user, c.Param("id"), req.Name, clientInfo(c))
+	if err != nil {
+		if err == services.ErrDeviceNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Device not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rename device"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newDeviceResponse(device))
+}
+
+// RevokeDevice godoc
+// @Summary Revoke a trusted device
+// @Description Stop trusting a device and sign out the sessions started from it. The next login from the device requires MFA again.
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Device ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /me/devices/{id} [delete]
+func (h *DeviceHandler) RevokeDevice(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	if err := h.deviceService.RevokeDevice(c.Request.Context(), user, c.Param("id"), clientInfo(c)); err != nil {
+		if err == services.ErrDeviceNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Device not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke device"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Device revoked successfully"})
+}
+
+func newDeviceResponse(device *models.Device) DeviceResponse {
+	return DeviceResponse{
+		ID:           device.ID,
+		Name:         device.Name,
+		Type:         device.Type,
+		IP:           device.IP,
+		UserAgent:    device.UserAgent,
+		TrustedUntil: device.TrustedUntil,
+		LastUsedAt:   device.LastUsedAt,
+		CreatedAt:    device.CreatedAt,
+	}
+}
+
+type RenameDeviceRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+type DeviceResponse struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	IP           string    `json:"ip"`
+	UserAgent    string    `json:"user_agent"`
+	TrustedUntil time.Time `json:"trusted_until"`
+	LastUsedAt   time.Time `json:"last_used_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
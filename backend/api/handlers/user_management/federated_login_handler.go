@@ -0,0 +1,277 @@
+package user_management
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type FederatedLoginHandler struct {
+	federationService *services.FederationService
+	providerService   *services.IdentityProviderService
+	authService       *services.AuthenticationService
+}
+
+func NewFederatedLoginHandler(federationService *services.FederationService, providerService *services.IdentityProviderService, authService *services.AuthenticationService) *FederatedLoginHandler {
+	return &FederatedLoginHandler{
+		federationService: federationService,
+		providerService:   providerService,
+		authService:       authService,
+	}
+}
+
+// ListProviders godoc
+// @Summary List sign-in identity providers
+// @Description List the external OpenID Connect providers members of the tenant can sign in with, for the login page.
+// @Tags authentication
+// @Produce json
+// @Success 200 {array} FederatedProviderResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/federated/providers [get]
+func (h *FederatedLoginHandler) ListProviders(c *gin.Context) {
+	providers, err := h.providerService.ListEnabledProviders(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list identity providers"})
+		return
+	}
+
+	response := make([]FederatedProviderResponse, 0, len(providers))
+	for _, provider := range providers {
+		response = append(response, FederatedProviderResponse{ID: provider.ID, Name: provider.Name})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// StartLogin godoc
+// @Summary Start signing in with an identity provider
+// @Description Start the authorization code flow with an external OpenID Connect provider. The user agent is to be sent to the returned URL; the provider sends it back to the frontend's /auth/federated/callback page, which completes the sign-in. The sign-in expires after ten minutes.
+// @Tags authentication
+// @Produce json
+// @Param id path string true "Identity provider ID"
+// @Success 200 {object} FederatedAuthorizationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/federated/{id}/authorize [get]
+func (h *FederatedLoginHandler) StartLogin(c *gin.Context) {
+	authorizationURL, err := h.federationService.StartLogin(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondFederatedLoginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, FederatedAuthorizationResponse{AuthorizationURL: authorizationURL})
+}
+
+// CompleteLogin godoc
+// @Summary Complete signing in with an identity provider
+// @Description Redeem the code and state the identity provider returned. Provider accounts not yet linked create a user if the provider verified their email address. If a user already has the address, the sign-in is refused with 409 and a pending link is recorded, which that user can confirm at /me/federated-identities. Roles mapped from the provider's groups are granted or revoked to match. MFA-enabled users get a temporary token to complete the sign-in at /auth/verify-mfa, as with password logins; their roles change only once the second factor is verified.
+// @Tags authentication
+// @Accept json
+// @Produce json
+// @Param request body FederatedCallbackRequest true "Code and state from the identity provider"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/federated/callback [post]
+func (h *FederatedLoginHandler) CompleteLogin(c *gin.Context) {
+	var req FederatedCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, accessToken, refreshToken, err := h.federationService.CompleteLogin(c.Request.Context(), req.Code, req.State, clientInfo(c))
+	if err != nil && err != services.ErrMFARequired {
+		respondFederatedLoginError(c, err)
+		return
+	}
+
+	respondFederatedLogin(c, h.authService, user, accessToken, refreshToken, err)
+}
+
+// respondFederatedLogin writes the outcome of a sign-in through an
+// identity provider, which err, if not nil, is ErrMFARequired for: the
+// token pair, or the temporary token MFA-enabled users complete the
+// sign-in with, which the sign-in returns in place of the access token.
+func respondFederatedLogin(c *gin.Context, authService *services.AuthenticationService, user *models.User, accessToken, refreshToken string, err error) {
+	if err == services.ErrMFARequired {
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required": true,
+			"mfa_method":   user.MFAMethod,
+			"temp_token":   accessToken,
+		})
+		return
+	}
+
+	enrollmentRequired, err := authService.RequiresMFAEnrollment(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load auth policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, LoginResponse{
+		User: UserResponse{
+			ID:       user.ID,
+			Email:    user.Email,
+			Username: user.Username,
+		},
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		MFAEnrollmentRequired: enrollmentRequired,
+	})
+}
+
+// ListLinks godoc
+// @Summary List linked identity provider accounts
+// @Description List the caller's links to accounts at identity providers, including pending links requested by signing in with a provider account that has the caller's email address
+// @Tags federated-identities
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} FederatedIdentityResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /me/federated-identities [get]
+func (h *FederatedLoginHandler) ListLinks(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	identities, err := h.federationService.ListLinks(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list linked accounts"})
+		return
+	}
+
+	response := make([]FederatedIdentityResponse, 0, len(identities))
+	for _, identity := range identities {
+		response = append(response, newFederatedIdentityResponse(identity))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ConfirmLink godoc
+// @Summary Confirm a link to an identity provider account
+// @Description Confirm a pending link, after which the identity provider account signs in as the caller. The caller's email address must be verified.
+// @Tags federated-identities
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Federated identity ID"
+// @Success 200 {object} FederatedIdentityResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /me/federated-identities/{id}/confirm [post]
+func (h *FederatedLoginHandler) ConfirmLink(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	identity, err := h.federationService.ConfirmLink(c.Request.Context(), user, c.Param("id"), clientInfo(c))
+	if err != nil {
+		respondFederatedLinkError(c, err, "Failed to confirm link")
+		return
+	}
+
+	c.JSON(http.StatusOK, newFederatedIdentityResponse(identity))
+}
+
+// RemoveLink godoc
+// @Summary Unlink an identity provider account
+// @Description Remove a link to an identity provider account, or decline a pending one. The account can no longer sign in as the caller.
+// @Tags federated-identities
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Federated identity ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /me/federated-identities/{id} [delete]
+func (h *FederatedLoginHandler) RemoveLink(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	if err := h.federationService.RemoveLink(c.Request.Context(), user, c.Param("id"), clientInfo(c)); err != nil {
+		respondFederatedLinkError(c, err, "Failed to remove link")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Account unlinked successfully"})
+}
+
+func newFederatedIdentityResponse(identity *models.FederatedIdentity) FederatedIdentityResponse {
+	return FederatedIdentityResponse{
+		ID:                 identity.ID,
+		IdentityProviderID: identity.IdentityProviderID,
+		Subject:            identity.Subject,
+		Email:              identity.Email,
+		Pending:            identity.Pending,
+		LastLoginAt:        identity.LastLoginAt,
+		CreatedAt:          identity.CreatedAt,
+	}
+}
+
+func respondFederatedLinkError(c *gin.Context, err error, message string) {
+	switch err {
+	case services.ErrFederatedIdentityNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Linked account not found"})
+	case services.ErrEmailNotVerified:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func respondFederatedLoginError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrInvalidID:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case services.ErrIdentityProviderNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Identity provider not found"})
+	case services.ErrInvalidFederatedState, services.ErrInvalidIDToken:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case services.ErrFederatedEmailNotVerified, services.ErrEmailDomainNotAllowed:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case services.ErrAccountDisabled:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+	case services.ErrFederatedLinkPending:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case services.ErrIdentityProviderUnavailable:
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in with identity provider"})
+	}
+}
+
+type FederatedCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+type FederatedProviderResponse struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+type FederatedAuthorizationResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+type FederatedIdentityResponse struct {
+	ID                 uuid.UUID  `json:"id"`
+	IdentityProviderID uuid.UUID  `json:"identity_provider_id"`
+	Subject            string     `json:"subject"`
+	Email              string     `json:"email"`
+	Pending            bool       `json:"pending"`
+	LastLoginAt        *time.Time `json:"last_login_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
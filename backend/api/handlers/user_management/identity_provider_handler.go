@@ -0,0 +1,272 @@
+package user_management
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type IdentityProviderHandler struct {
+	providerService   *services.IdentityProviderService
+	federationService *services.FederationService
+}
+
+func NewIdentityProviderHandler(providerService *services.IdentityProviderService, federationService *services.FederationService) *IdentityProviderHandler {
+	return &IdentityProviderHandler{
+		providerService:   providerService,
+		federationService: federationService,
+	}
+}
+
+// ListIdentityProviders godoc
+// @Summary List identity providers
+// @Description List the external OpenID Connect providers configured for the current tenant. Client secrets are never returned.
+// @Tags identity-providers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} IdentityProviderResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/identity-providers [get]
+func (h *IdentityProviderHandler) ListIdentityProviders(c *gin.Context) {
+	providers, err := h.providerService.ListProviders(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list identity providers"})
+		return
+	}
+
+	response := make([]IdentityProviderResponse, 0, len(providers))
+	for _, provider := range providers {
+		response = append(response, h.newIdentityProviderResponse(provider))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetIdentityProvider godoc
+// @Summary Get an identity provider
+// @Description Get an identity provider of the current tenant by ID
+// @Tags identity-providers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Identity provider ID"
+// @Success 200 {object} IdentityProviderResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/identity-providers/{id} [get]
+func (h *IdentityProviderHandler) GetIdentityProvider(c *gin.Context) {
+	provider, err := h.providerService.GetProvider(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondIdentityProviderError(c, err, "Failed to load identity provider")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.newIdentityProviderResponse(provider))
+}
+
+// CreateIdentityProvider godoc
+// @Summary Add an identity provider
+// @Description Let members of the current tenant sign in through an external OpenID Connect provider. The client must be registered at the provider with the redirect URI returned in the response. Users whose groups, as reported in groups_claim, match a role mapping are granted the mapped role when they sign in and lose it when they leave the group.
+// @Tags identity-providers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateIdentityProviderRequest true "Identity provider details"
+// @Success 201 {object} IdentityProviderResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/identity-providers [post]
+func (h *IdentityProviderHandler) CreateIdentityProvider(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	var req CreateIdentityProviderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	enabled := req.Enabled == nil || *req.Enabled
+	provider, err := h.providerService.CreateProvider(c.Request.Context(), actor, services.IdentityProviderInput{
+		Name:         &req.Name,
+		Issuer:       &req.Issuer,
+		ClientID:     &req.ClientID,
+		ClientSecret: &req.ClientSecret,
+		Scopes:       req.Scopes,
+		GroupsClaim:  &req.GroupsClaim,
+		RoleMappings: roleMappingInput(req.RoleMappings),
+		Enabled:      &enabled,
+	}, clientInfo(c))
+	if err != nil {
+		respondIdentityProviderError(c, err, "Failed to create identity provider")
+		return
+	}
+
+	c.JSON(http.StatusCreated, h.newIdentityProviderResponse(provider))
+}
+
+// UpdateIdentityProvider godoc
+// @Summary Update an identity provider
+// @Description Update an identity provider of the current tenant. Omitted fields are left unchanged, as is the client secret when it is empty.
+// @Tags identity-providers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Identity provider ID"
+// @Param request body UpdateIdentityProviderRequest true "Identity provider fields to change"
+// @Success 200 {object} IdentityProviderResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/identity-providers/{id} [patch]
+func (h *IdentityProviderHandler) UpdateIdentityProvider(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	var req UpdateIdentityProviderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	provider, err := h.providerService.UpdateProvider(c.Request.Context(), actor, c.Param("id"), services.IdentityProviderInput{
+		Name:         req.Name,
+		Issuer:       req.Issuer,
+		ClientID:     req.ClientID,
+		ClientSecret: req.ClientSecret,
+		Scopes:       req.Scopes,
+		GroupsClaim:  req.GroupsClaim,
+		RoleMappings: roleMappingInput(req.RoleMappings),
+		Enabled:      req.Enabled,
+	}, clientInfo(c))
+	if err != nil {
+		respondIdentityProviderError(c, err, "Failed to update identity provider")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.newIdentityProviderResponse(provider))
+}
+
+// DeleteIdentityProvider godoc
+// @Summary Delete an identity provider
+// @Description Delete an identity provider together with the links of users to their accounts at it. The users are kept and can still sign in by other means.
+// @Tags identity-providers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Identity provider ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/identity-providers/{id} [delete]
+func (h *IdentityProviderHandler) DeleteIdentityProvider(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	if err := h.providerService.DeleteProvider(c.Request.Context(), actor, c.Param("id"), clientInfo(c)); err != nil {
+		respondIdentityProviderError(c, err, "Failed to delete identity provider")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Identity provider deleted successfully"})
+}
+
+func respondIdentityProviderError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case services.ErrInvalidID, services.ErrInvalidIssuer, services.ErrInvalidRoleMapping:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case services.ErrIdentityProviderNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Identity provider not found"})
+	case services.ErrPermissionNotHeld:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func (h *IdentityProviderHandler) newIdentityProviderResponse(provider *models.IdentityProvider) IdentityProviderResponse {
+	response := IdentityProviderResponse{
+		ID:           provider.ID,
+		Name:         provider.Name,
+		Issuer:       provider.Issuer,
+		ClientID:     provider.ClientID,
+		Scopes:       strings.Fields(provider.Scopes),
+		GroupsClaim:  provider.GroupsClaim,
+		RoleMappings: []RoleMappingRequest{},
+		Enabled:      provider.Enabled,
+		RedirectURI:  h.federationService.RedirectURI(),
+		CreatedAt:    provider.CreatedAt,
+		UpdatedAt:    provider.UpdatedAt,
+	}
+	var mappings []services.GroupRoleMapping
+	_ = json.Unmarshal([]byte(provider.RoleMappings), &mappings)
+	for _, mapping := range mappings {
+		response.RoleMappings = append(response.RoleMappings, RoleMappingRequest{Group: mapping.Group, RoleID: mapping.RoleID})
+	}
+	return response
+}
+
+func roleMappingInput(mappings []RoleMappingRequest) []services.GroupRoleMapping {
+	if mappings == nil {
+		return nil
+	}
+	input := make([]services.GroupRoleMapping, 0, len(mappings))
+	for _, mapping := range mappings {
+		input = append(input, services.GroupRoleMapping{Group: mapping.Group, RoleID: mapping.RoleID})
+	}
+	return input
+}
+
+type RoleMappingRequest struct {
+	Group  string    `json:"group" binding:"required"`
+	RoleID uuid.UUID `json:"role_id" binding:"required"`
+}
+
+type CreateIdentityProviderRequest struct {
+	Name         string               `json:"name" binding:"required,max=100"`
+	Issuer       string               `json:"issuer" binding:"required,max=255"`
+	ClientID     string               `json:"client_id" binding:"required,max=255"`
+	ClientSecret string               `json:"client_secret" binding:"required,max=255"`
+	Scopes       []string             `json:"scopes"`
+	GroupsClaim  string               `json:"groups_claim" binding:"max=100"`
+	RoleMappings []RoleMappingRequest `json:"role_mappings" binding:"omitempty,dive"`
+	Enabled      *bool                `json:"enabled"`
+}
+
+type UpdateIdentityProviderRequest struct {
+	Name         *string              `json:"name" binding:"omitempty,min=1,max=100"`
+	Issuer       *string              `json:"issuer" binding:"omitempty,max=255"`
+	ClientID     *string              `json:"client_id" binding:"omitempty,min=1,max=255"`
+	ClientSecret *string              `json:"client_secret" binding:"omitempty,max=255"`
+	Scopes       []string             `json:"scopes"`
+	GroupsClaim  *string              `json:"groups_claim" binding:"omitempty,max=100"`
+	RoleMappings []RoleMappingRequest `json:"role_mappings" binding:"omitempty,dive"`
+	Enabled      *bool                `json:"enabled"`
+}
+
+// IdentityProviderResponse describes an identity provider. RedirectURI is
+// the redirect URI to register for the client at the provider.
+type IdentityProviderResponse struct {
+	ID           uuid.UUID            `json:"id"`
+	Name         string               `json:"name"`
+	Issuer       string               `json:"issuer"`
+	ClientID     string               `json:"client_id"`
+	Scopes       []string             `json:"scopes"`
+	GroupsClaim  string               `json:"groups_claim"`
+	RoleMappings []RoleMappingRequest `json:"role_mappings"`
+	Enabled      bool                 `json:"enabled"`
+	RedirectURI  string               `json:"redirect_uri"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+}
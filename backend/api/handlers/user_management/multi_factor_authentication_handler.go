@@ -6,21 +6,20 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/josy-coder/adminsuite/internal/models"
-	repositories "github.com/josy-coder/adminsuite/internal/repositories/user_management"
 	services "github.com/josy-coder/adminsuite/internal/services/user_management"
 )
 
 type MFAHandler struct {
-	authService *services.AuthenticationService
-	mfaService  *services.MFAService
-	userRepo    repositories.UserRepository
+	authService  *services.AuthenticationService
+	mfaService   *services.MFAService
+	auditService *services.AuditService
 }
 
-func NewMFAHandler(authService *services.AuthenticationService, mfaService *services.MFAService, userRepo repositories.UserRepository) *MFAHandler {
+func NewMFAHandler(authService *services.AuthenticationService, mfaService *services.MFAService, auditService *services.AuditService) *MFAHandler {
 	return &MFAHandler{
-		authService: authService,
-		mfaService:  mfaService,
-		userRepo:    userRepo,
+		authService:  authService,
+		mfaService:   mfaService,
+		auditService: auditService,
 	}
 }
 
@@ -33,23 +32,30 @@ func NewMFAHandler(authService *services.AuthenticationService, mfaService *serv
 // @Security BearerAuth
 // @Success 200 {object} TOTPSetupResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /mfa/setup/totp [post]
 func (h *MFAHandler) SetupTOTP(c *gin.Context) {
 	user := c.MustGet("user").(*models.User)
 
-	secret, err := h.mfaService.GenerateTOTPSecret(user)
+	secret, err := h.mfaService.GenerateTOTPSecret(c.Request.Context(), user)
+	if err == services.ErrMFAMethodNotAllowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate MFA secret"})
 		return
 	}
 
-	qrCode, err := h.mfaService.GenerateTOTPQRCode(user)
+	qrCode, err := h.mfaService.GenerateTOTPQRCode(c.Request.Context(), user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate QR code"})
 		return
 	}
 
+	h.auditService.RecordUserAction(c.Request.Context(), user, services.AuditActionMFASetup, map[string]interface{}{"mfa_method": models.MFAMethodTOTP}, clientInfo(c))
+
 	c.JSON(http.StatusOK, TOTPSetupResponse{
 		Secret: secret,
 		QRCode: qrCode,
@@ -78,7 +84,7 @@ func (h *MFAHandler) VerifyTOTP(c *gin.Context) {
 		return
 	}
 
-	valid, err := h.mfaService.VerifyTOTP(user, req.Token)
+	valid, err := h.mfaService.VerifyTOTP(c.Request.Context(), user, req.Token)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify MFA token"})
 		return
@@ -88,6 +94,8 @@ func (h *MFAHandler) VerifyTOTP(c *gin.Context) {
 		return
 	}
 
+	h.auditService.RecordUserAction(c.Request.Context(), user, services.AuditActionMFAEnable, map[string]interface{}{"mfa_method": models.MFAMethodTOTP}, clientInfo(c))
+
 	c.JSON(http.StatusOK, SuccessResponse{Message: "MFA verified successfully"})
 }
 
@@ -100,17 +108,24 @@ func (h *MFAHandler) VerifyTOTP(c *gin.Context) {
 // @Security BearerAuth
 // @Success 200 {object} SuccessResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /mfa/setup/sms [post]
 func (h *MFAHandler) SetupSMS(c *gin.Context) {
 	user := c.MustGet("user").(*models.User)
 
-	_, err := h.mfaService.GenerateSMSCode(user)
+	_, err := h.mfaService.GenerateSMSCode(c.Request.Context(), user)
+	if err == services.ErrMFAMethodNotAllowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate SMS code"})
 		return
 	}
 
+	h.auditService.RecordUserAction(c.Request.Context(), user, services.AuditActionMFASetup, map[string]interface{}{"mfa_method": models.MFAMethodSMS}, clientInfo(c))
+
 	c.JSON(http.StatusOK, SuccessResponse{Message: "SMS code sent successfully"})
 }
 
@@ -136,7 +151,7 @@ func (h *MFAHandler) VerifySMS(c *gin.Context) {
 		return
 	}
 
-	valid, err := h.mfaService.VerifySMSCode(user, req.Code)
+	valid, err := h.mfaService.VerifySMSCode(c.Request.Context(), user, req.Code)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify SMS code"})
 		return
@@ -146,6 +161,8 @@ func (h *MFAHandler) VerifySMS(c *gin.Context) {
 		return
 	}
 
+	h.auditService.RecordUserAction(c.Request.Context(), user, services.AuditActionMFAEnable, map[string]interface{}{"mfa_method": models.MFAMethodSMS}, clientInfo(c))
+
 	c.JSON(http.StatusOK, SuccessResponse{Message: "SMS code verified successfully"})
 }
 
@@ -162,12 +179,14 @@ func (h *MFAHandler) VerifySMS(c *gin.Context) {
 func (h *MFAHandler) GenerateBackupCodes(c *gin.Context) {
 	user := c.MustGet("user").(*models.User)
 
-	codes, err := h.mfaService.GenerateBackupCodes(user)
+	codes, err := h.mfaService.GenerateBackupCodes(c.Request.Context(), user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate backup codes"})
 		return
 	}
 
+	h.auditService.RecordUserAction(c.Request.Context(), user, services.AuditActionMFABackupCodes, map[string]interface{}{"count": len(codes)}, clientInfo(c))
+
 	c.JSON(http.StatusOK, BackupCodesResponse{BackupCodes: codes})
 }
 
@@ -193,7 +212,7 @@ func (h *MFAHandler) VerifyBackupCode(c *gin.Context) {
 		return
 	}
 
-	valid, err := h.mfaService.VerifyBackupCode(user, req.Code)
+	valid, err := h.mfaService.VerifyBackupCode(c.Request.Context(), user, req.Code)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify backup code"})
 		return
@@ -203,32 +222,37 @@ func (h *MFAHandler) VerifyBackupCode(c *gin.Context) {
 		return
 	}
 
+	h.auditService.RecordUserAction(c.Request.Context(), user, services.AuditActionMFABackupUsed, map[string]interface{}{"remaining": len(user.MFABackupCodes)}, clientInfo(c))
+
 	c.JSON(http.StatusOK, SuccessResponse{Message: "Backup code verified successfully"})
 }
 
 // DisableMFA godoc
 // @Summary Disable MFA
-// @Description Disable MFA for the user
+// @Description Disable MFA for the user. Refused when the tenant's auth policy requires MFA.
 // @Tags MFA
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} SuccessResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /mfa/disable [post]
 func (h *MFAHandler) DisableMFA(c *gin.Context) {
 	user := c.MustGet("user").(*models.User)
 
-	user.MFAEnabled = false
-	user.MFASecret = ""
-	user.MFAMethod = ""
-	user.MFABackupCodes = nil
-
-	if err := h.userRepo.Update(user); err != nil {
+	err := h.mfaService.DisableMFA(c.Request.Context(), user)
+	if err == services.ErrMFARequiredByPolicy {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable MFA"})
 		return
 	}
 
+	h.auditService.RecordUserAction(c.Request.Context(), user, services.AuditActionMFADisable, nil, clientInfo(c))
+
 	c.JSON(http.StatusOK, SuccessResponse{Message: "MFA disabled successfully"})
 }
 
@@ -0,0 +1,437 @@
+package user_management
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type OAuthClientHandler struct {
+	oauthClientService *services.OAuthClientService
+}
+
+func NewOAuthClientHandler(oauthClientService *services.OAuthClientService) *OAuthClientHandler {
+	return &OAuthClientHandler{
+		oauthClientService: oauthClientService,
+	}
+}
+
+// ListOAuthClients godoc
+// @Summary List OAuth clients
+// @Description List the OAuth clients registered with the current tenant
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} OAuthClientResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/oauth/clients [get]
+func (h *OAuthClientHandler) ListOAuthClients(c *gin.Context) {
+	clients, err := h.oauthClientService.ListClients(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list OAuth clients"})
+		return
+	}
+
+	response := make([]OAuthClientResponse, 0, len(clients))
+	for _, oauthClient := range clients {
+		response = append(response, newOAuthClientResponse(oauthClient))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetOAuthClient godoc
+// @Summary Get an OAuth client
+// @Description Get an OAuth client of the current tenant by ID
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "OAuth client ID"
+// @Success 200 {object} OAuthClientResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/oauth/clients/{id} [get]
+func (h *OAuthClientHandler) GetOAuthClient(c *gin.Context) {
+	oauthClient, err := h.oauthClientService.GetClient(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondOAuthClientError(c, err, "Failed to load OAuth client")
+		return
+	}
+
+	c.JSON(http.StatusOK, newOAuthClientResponse(oauthClient))
+}
+
+// CreateOAuthClient godoc
+// @Summary Register an OAuth client
+// @Description Register an application that signs users in through the OAuth authorization server. Confidential clients are issued a client secret, which is only returned once; public clients such as single-page and native apps get none and rely on PKCE. Redirect URIs must match exactly and may only use http for loopback addresses.
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateOAuthClientRequest true "Client details"
+// @Success 201 {object} OAuthClientCreatedResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/oauth/clients [post]
+func (h *OAuthClientHandler) CreateOAuthClient(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	var req CreateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	secret, oauthClient, err := h.oauthClientService.CreateClient(c.Request.Context(), actor, services.OAuthClientInput{
+		Name:                   &req.Name,
+		RedirectURIs:           req.RedirectURIs,
+		PostLogoutRedirectURIs: req.PostLogoutRedirectURIs,
+		Scopes:                 req.Scopes,
+		Public:                 &req.Public,
+		Trusted:                &req.Trusted,
+	}, clientInfo(c))
+	if err != nil {
+		respondOAuthClientError(c, err, "Failed to create OAuth client")
+		return
+	}
+
+	c.JSON(http.StatusCreated, OAuthClientCreatedResponse{
+		OAuthClientResponse: newOAuthClientResponse(oauthClient),
+		ClientSecret:        secret,
+	})
+}
+
+// UpdateOAuthClient godoc
+// @Summary Update an OAuth client
+// @Description Update the name, redirect URIs, allowed scopes or trust of an OAuth client. Omitted fields are left unchanged.
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "OAuth client ID"
+// @Param request body UpdateOAuthClientRequest true "Client fields to change"
+// @Success 200 {object} OAuthClientResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/oauth/clients/{id} [patch]
+func (h *OAuthClientHandler) UpdateOAuthClient(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	var req UpdateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	oauthClient, err := h.oauthClientService.UpdateClient(c.Request.Context(), actor, c.Param("id"), services.OAuthClientInput{
+		Name:                   req.Name,
+		RedirectURIs:           req.RedirectURIs,
+		PostLogoutRedirectURIs: req.PostLogoutRedirectURIs,
+		Scopes:                 req.Scopes,
+		Trusted:                req.Trusted,
+	}, clientInfo(c))
+	if err != nil {
+		respondOAuthClientError(c, err, "Failed to update OAuth client")
+		return
+	}
+
+	c.JSON(http.StatusOK, newOAuthClientResponse(oauthClient))
+}
+
+// DeleteOAuthClient godoc
+// @Summary Delete an OAuth client
+// @Description Delete an OAuth client. Every session it was granted ends and the consents given to it are forgotten.
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "OAuth client ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/oauth/clients/{id} [delete]
+func (h *OAuthClientHandler) DeleteOAuthClient(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	if err := h.oauthClientService.DeleteClient(c.Request.Context(), actor, c.Param("id"), clientInfo(c)); err != nil {
+		respondOAuthClientError(c, err, "Failed to delete OAuth client")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "OAuth client deleted successfully"})
+}
+
+// RotateOAuthClientSecret godoc
+// @Summary Rotate an OAuth client secret
+// @Description Issue a confidential OAuth client a new secret, which is only returned once. The previous secret stops working immediately.
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "OAuth client ID"
+// @Success 200 {object} OAuthClientCreatedResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/oauth/clients/{id}/rotate-secret [post]
+func (h *OAuthClientHandler) RotateOAuthClientSecret(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	secret, oauthClient, err := h.oauthClientService.RotateClientSecret(c.Request.Context(), actor, c.Param("id"), clientInfo(c))
+	if err != nil {
+		respondOAuthClientError(c, err, "Failed to rotate OAuth client secret")
+		return
+	}
+
+	c.JSON(http.StatusOK, OAuthClientCreatedResponse{
+		OAuthClientResponse: newOAuthClientResponse(oauthClient),
+		ClientSecret:        secret,
+	})
+}
+
+// ListOAuthScopes godoc
+// @Summary List OAuth scopes
+// @Description List the scopes OAuth clients of the current tenant may request, with the permissions each grants. The reserved offline_access scope is not listed.
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} OAuthScopeResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/oauth/scopes [get]
+func (h *OAuthClientHandler) ListOAuthScopes(c *gin.Context) {
+	scopes, err := h.oauthClientService.ListScopes(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list OAuth scopes"})
+		return
+	}
+
+	response := make([]OAuthScopeResponse, 0, len(scopes))
+	for _, scope := range scopes {
+		response = append(response, newOAuthScopeResponse(scope))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateOAuthScope godoc
+// @Summary Create an OAuth scope
+// @Description Create a scope OAuth clients may request. Tokens granted the scope carry its permissions, as far as the user holds them.
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateOAuthScopeRequest true "Scope details"
+// @Success 201 {object} OAuthScopeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/oauth/scopes [post]
+func (h *OAuthClientHandler) CreateOAuthScope(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	var req CreateOAuthScopeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scope, err := h.oauthClientService.CreateScope(c.Request.Context(), actor, services.OAuthScopeInput{
+		Name:        &req.Name,
+		Description: &req.Description,
+		Permissions: req.Permissions,
+	}, clientInfo(c))
+	if err != nil {
+		respondOAuthClientError(c, err, "Failed to create OAuth scope")
+		return
+	}
+
+	c.JSON(http.StatusCreated, newOAuthScopeResponse(scope))
+}
+
+// UpdateOAuthScope godoc
+// @Summary Update an OAuth scope
+// @Description Update the name, description or permissions of an OAuth scope. Omitted fields are left unchanged. Tokens already issued keep the permissions they carry until they expire.
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "OAuth scope ID"
+// @Param request body UpdateOAuthScopeRequest true "Scope fields to change"
+// @Success 200 {object} OAuthScopeResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/oauth/scopes/{id} [patch]
+func (h *OAuthClientHandler) UpdateOAuthScope(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	var req UpdateOAuthScopeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scope, err := h.oauthClientService.UpdateScope(c.Request.Context(), actor, c.Param("id"), services.OAuthScopeInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	}, clientInfo(c))
+	if err != nil {
+		respondOAuthClientError(c, err, "Failed to update OAuth scope")
+		return
+	}
+
+	c.JSON(http.StatusOK, newOAuthScopeResponse(scope))
+}
+
+// DeleteOAuthScope godoc
+// @Summary Delete an OAuth scope
+// @Description Delete an OAuth scope. Tokens already granted it keep their permissions until they expire; refreshed tokens no longer carry them.
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "OAuth scope ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/oauth/scopes/{id} [delete]
+func (h *OAuthClientHandler) DeleteOAuthScope(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	if err := h.oauthClientService.DeleteScope(c.Request.Context(), actor, c.Param("id"), clientInfo(c)); err != nil {
+		respondOAuthClientError(c, err, "Failed to delete OAuth scope")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "OAuth scope deleted successfully"})
+}
+
+func respondOAuthClientError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case services.ErrInvalidID, services.ErrInvalidRedirectURIs, services.ErrInvalidPermission,
+		services.ErrReservedOAuthScope, services.ErrInvalidOAuthScopeName, services.ErrPublicOAuthClientSecret:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case services.ErrOAuthClientNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "OAuth client not found"})
+	case services.ErrOAuthScopeNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "OAuth scope not found"})
+	case services.ErrOAuthScopeNameTaken:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func newOAuthClientResponse(oauthClient *models.OAuthClient) OAuthClientResponse {
+	response := OAuthClientResponse{
+		ID:                     oauthClient.ID,
+		ClientID:               oauthClient.ClientID,
+		Name:                   oauthClient.Name,
+		RedirectURIs:           []string{},
+		PostLogoutRedirectURIs: []string{},
+		Scopes:                 []string{},
+		Public:                 oauthClient.Public,
+		Trusted:                oauthClient.Trusted,
+		CreatedAt:              oauthClient.CreatedAt,
+		UpdatedAt:              oauthClient.UpdatedAt,
+	}
+	_ = json.Unmarshal([]byte(oauthClient.RedirectURIs), &response.RedirectURIs)
+	_ = json.Unmarshal([]byte(oauthClient.PostLogoutRedirectURIs), &response.PostLogoutRedirectURIs)
+	_ = json.Unmarshal([]byte(oauthClient.Scopes), &response.Scopes)
+	return response
+}
+
+func newOAuthScopeResponse(scope *models.OAuthScope) OAuthScopeResponse {
+	response := OAuthScopeResponse{
+		ID:          scope.ID,
+		Name:        scope.Name,
+		Description: scope.Description,
+		Permissions: []string{},
+		CreatedAt:   scope.CreatedAt,
+		UpdatedAt:   scope.UpdatedAt,
+	}
+	_ = json.Unmarshal([]byte(scope.Permissions), &response.Permissions)
+	return response
+}
+
+type CreateOAuthClientRequest struct {
+	Name                   string   `json:"name" binding:"required,max=100"`
+	RedirectURIs           []string `json:"redirect_uris" binding:"required,min=1"`
+	PostLogoutRedirectURIs []string `json:"post_logout_redirect_uris"`
+	Scopes                 []string `json:"scopes" binding:"required,min=1"`
+	Public                 bool     `json:"public"`
+	Trusted                bool     `json:"trusted"`
+}
+
+type UpdateOAuthClientRequest struct {
+	Name                   *string  `json:"name" binding:"omitempty,min=1,max=100"`
+	RedirectURIs           []string `json:"redirect_uris" binding:"omitempty,min=1"`
+	PostLogoutRedirectURIs []string `json:"post_logout_redirect_uris"`
+	Scopes                 []string `json:"scopes" binding:"omitempty,min=1"`
+	Trusted                *bool    `json:"trusted"`
+}
+
+type CreateOAuthScopeRequest struct {
+	Name        string   `json:"name" binding:"required,max=100"`
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+type UpdateOAuthScopeRequest struct {
+	Name        *string  `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string  `json:"description" binding:"omitempty,max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+type OAuthClientResponse struct {
+	ID                     uuid.UUID `json:"id"`
+	ClientID               string    `json:"client_id"`
+	Name                   string    `json:"name"`
+	RedirectURIs           []string  `json:"redirect_uris"`
+	PostLogoutRedirectURIs []string  `json:"post_logout_redirect_uris"`
+	Scopes                 []string  `json:"scopes"`
+	Public                 bool      `json:"public"`
+	Trusted                bool      `json:"trusted"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
+
+// OAuthClientCreatedResponse carries the client secret, which is returned
+// only when it is issued. It is empty for public clients.
+type OAuthClientCreatedResponse struct {
+	OAuthClientResponse
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+type OAuthScopeResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
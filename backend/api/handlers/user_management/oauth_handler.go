@@ -0,0 +1,252 @@
+package user_management
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type OAuthHandler struct {
+	oauthService *services.OAuthService
+}
+
+func NewOAuthHandler(oauthService *services.OAuthService) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: oauthService,
+	}
+}
+
+// GetAuthorization godoc
+// @Summary Start an OAuth authorization
+// @Description Validate an OAuth 2.1 authorization request for the signed-in user and describe what the client asks for, so the frontend can show a consent screen. Only the code response type with an S256 PKCE challenge is supported. An unknown client or unregistered redirect URI is a 400 without redirect_to; other errors carry the redirect_to URI the user agent must be sent back to.
+// @Tags oauth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param response_type query string true "Must be code"
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string true "Registered redirect URI"
+// @Param scope query string true "Space-separated scopes"
+// @Param state query string false "Opaque value returned to the client"
+// @Param code_challenge query string true "PKCE code challenge"
+// @Param code_challenge_method query string true "Must be S256"
+// @Param nonce query string false "OpenID Connect nonce echoed in the ID token"
+// @Success 200 {object} AuthorizationPromptResponse
+// @Failure 400 {object} OAuthErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /oauth/authorize [get]
+func (h *OAuthHandler) GetAuthorization(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	var req AuthorizationQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authReq := req.toService()
+	prompt, err := h.oauthService.PrepareAuthorization(c.Request.Context(), user, authReq)
+	if err != nil {
+		respondAuthorizationRequestError(c, authReq, err)
+		return
+	}
+
+	scopes := make([]ScopeDescriptionResponse, 0, len(prompt.Scopes))
+	for _, scope := range prompt.Scopes {
+		scopes = append(scopes, ScopeDescriptionResponse{Name: scope.Name, Description: scope.Description})
+	}
+
+	c.JSON(http.StatusOK, AuthorizationPromptResponse{
+		ClientID:        prompt.Client.ClientID,
+		ClientName:      prompt.Client.Name,
+		Scopes:          scopes,
+		ConsentRequired: prompt.ConsentRequired,
+	})
+}
+
+// Authorize godoc
+// @Summary Complete an OAuth authorization
+// @Description Approve or deny an OAuth 2.1 authorization request on behalf of the signed-in user. The response names the URI to send the user agent back to, carrying an authorization code and the state if the request was approved, or an access_denied error if not. Authorization codes expire after a minute and can be exchanged once.
+// @Tags oauth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AuthorizeRequest true "Authorization request and decision"
+// @Success 200 {object} AuthorizationRedirectResponse
+// @Failure 400 {object} OAuthErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /oauth/authorize [post]
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+	claims := c.MustGet("access_claims").(*services.AccessClaims)
+
+	var req AuthorizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	authReq := req.toService()
+	redirectTo, err := h.oauthService.Authorize(c.Request.Context(), user, claims, authReq, req.Approve, clientInfo(c))
+	if err != nil {
+		respondAuthorizationRequestError(c, authReq, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthorizationRedirectResponse{RedirectTo: redirectTo})
+}
+
+// Token godoc
+// @Summary Issue OAuth tokens
+// @Description OAuth 2.1 token endpoint. Exchanges an authorization code together with its PKCE code verifier, or a refresh token, for an access token limited to the granted scopes. Refresh tokens are only issued for the offline_access scope and rotate on every use. Grants of the openid scope also return an OpenID Connect ID token. Confidential clients authenticate with HTTP Basic or client_secret in the body; public clients send client_id only.
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code or refresh_token"
+// @Param code formData string false "Authorization code"
+// @Param redirect_uri formData string false "Redirect URI of the authorization request"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Param refresh_token formData string false "Refresh token"
+// @Param scope formData string false "Scope of the refresh; must equal the scope granted"
+// @Param client_id formData string false "Client ID"
+// @Param client_secret formData string false "Client secret"
+// @Success 200 {object} OAuthTokenResponse
+// @Failure 400 {object} OAuthErrorResponse
+// @Failure 401 {object} OAuthErrorResponse
+// @Failure 500 {object} OAuthErrorResponse
+// @Router /oauth/token [post]
+func (h *OAuthHandler) Token(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	req := services.TokenRequest{
+		GrantType:    c.PostForm("grant_type"),
+		ClientID:     c.PostForm("client_id"),
+		ClientSecret: c.PostForm("client_secret"),
+		Code:         c.PostForm("code"),
+		RedirectURI:  c.PostForm("redirect_uri"),
+		CodeVerifier: c.PostForm("code_verifier"),
+		RefreshToken: c.PostForm("refresh_token"),
+		Scope:        c.PostForm("scope"),
+	}
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		// Client credentials are form-encoded before they are put in the
+		// Basic authorization header.
+		req.ClientID, _ = url.QueryUnescape(id)
+		req.ClientSecret, _ = url.QueryUnescape(secret)
+	}
+
+	tokens, err := h.oauthService.Exchange(c.Request.Context(), req, clientInfo(c))
+	if err != nil {
+		var oauthErr *services.OAuthError
+		if !errors.As(err, &oauthErr) {
+			c.JSON(http.StatusInternalServerError, OAuthErrorResponse{Error: "server_error"})
+			return
+		}
+
+		status := http.StatusBadRequest
+		if oauthErr.Code == services.OAuthErrorInvalidClient {
+			status = http.StatusUnauthorized
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		c.JSON(status, OAuthErrorResponse{Error: oauthErr.Code, ErrorDescription: oauthErr.Description})
+		return
+	}
+
+	c.JSON(http.StatusOK, OAuthTokenResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(tokens.ExpiresIn.Seconds()),
+		RefreshToken: tokens.RefreshToken,
+		IDToken:      tokens.IDToken,
+		Scope:        strings.Join(tokens.Scopes, " "),
+	})
+}
+
+// respondAuthorizationRequestError reports a failed authorization request.
+// Errors about the client and redirect URI are shown to the user only, as
+// the redirect URI cannot be trusted; the rest are returned to the client.
+func respondAuthorizationRequestError(c *gin.Context, req services.AuthorizationRequest, err error) {
+	var oauthErr *services.OAuthError
+	switch {
+	case errors.Is(err, services.ErrOAuthClientNotFound), errors.Is(err, services.ErrInvalidRedirectURI):
+		c.JSON(http.StatusBadRequest, OAuthErrorResponse{Error: services.OAuthErrorInvalidRequest, ErrorDescription: err.Error()})
+	case errors.As(err, &oauthErr):
+		c.JSON(http.StatusBadRequest, OAuthErrorResponse{
+			Error:            oauthErr.Code,
+			ErrorDescription: oauthErr.Description,
+			RedirectTo:       req.ErrorRedirect(oauthErr),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process the authorization request"})
+	}
+}
+
+type AuthorizationQuery struct {
+	ResponseType        string `form:"response_type" json:"response_type" binding:"required"`
+	ClientID            string `form:"client_id" json:"client_id" binding:"required"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri" binding:"required"`
+	Scope               string `form:"scope" json:"scope"`
+	State               string `form:"state" json:"state"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+	Nonce               string `form:"nonce" json:"nonce"`
+}
+
+func (q AuthorizationQuery) toService() services.AuthorizationRequest {
+	return services.AuthorizationRequest{
+		ResponseType:        q.ResponseType,
+		ClientID:            q.ClientID,
+		RedirectURI:         q.RedirectURI,
+		Scope:               q.Scope,
+		State:               q.State,
+		CodeChallenge:       q.CodeChallenge,
+		CodeChallengeMethod: q.CodeChallengeMethod,
+		Nonce:               q.Nonce,
+	}
+}
+
+type AuthorizeRequest struct {
+	AuthorizationQuery
+	Approve bool `json:"approve"`
+}
+
+type ScopeDescriptionResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type AuthorizationPromptResponse struct {
+	ClientID        string                     `json:"client_id"`
+	ClientName      string                     `json:"client_name"`
+	Scopes          []ScopeDescriptionResponse `json:"scopes"`
+	ConsentRequired bool                       `json:"consent_required"`
+}
+
+type AuthorizationRedirectResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope"`
+}
+
+// OAuthErrorResponse is an RFC 6749 error. RedirectTo is set on errors of
+// the authorization endpoint that are returned to the client.
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+	RedirectTo       string `json:"redirect_to,omitempty"`
+}
@@ -0,0 +1,199 @@
+package user_management
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type OIDCHandler struct {
+	oidcService *services.OIDCService
+}
+
+func NewOIDCHandler(oidcService *services.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+	}
+}
+
+// GetConfiguration godoc
+// @Summary Get the OpenID Provider configuration
+// @Description OpenID Connect discovery document of the tenant the request is addressed to, served at /.well-known/openid-configuration relative to the issuer. Each tenant is a provider of its own with its own issuer.
+// @Tags oidc
+// @Produce json
+// @Success 200 {object} OpenIDConfigurationResponse
+// @Router /.well-known/openid-configuration [get]
+func (h *OIDCHandler) GetConfiguration(c *gin.Context) {
+	tenant := c.MustGet("tenant").(*models.Tenant)
+	metadata := h.oidcService.Metadata(tenant)
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, OpenIDConfigurationResponse{
+		Issuer:                            metadata.Issuer,
+		AuthorizationEndpoint:             metadata.AuthorizationEndpoint,
+		TokenEndpoint:                     metadata.TokenEndpoint,
+		UserinfoEndpoint:                  metadata.UserinfoEndpoint,
+		JWKSURI:                           metadata.JWKSURI,
+		EndSessionEndpoint:                metadata.EndSessionEndpoint,
+		ScopesSupported:                   metadata.ScopesSupported,
+		ResponseTypesSupported:            []string{services.ResponseTypeCode},
+		GrantTypesSupported:               []string{services.GrantTypeAuthorizationCode, services.GrantTypeRefreshToken},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{"EdDSA"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{services.CodeChallengeMethodS256},
+		ClaimsSupported:                   metadata.ClaimsSupported,
+	})
+}
+
+// GetKeySet godoc
+// @Summary Get the ID token verification keys
+// @Description Get the keys that verify ID tokens as a JSON Web Key Set. ID tokens are EdDSA JWTs signed with the token signing keys and name the verifying key as kid.
+// @Tags oidc
+// @Produce json
+// @Success 200 {object} JSONWebKeySetResponse
+// @Router /oidc/jwks [get]
+func (h *OIDCHandler) GetKeySet(c *gin.Context) {
+	keys := h.oidcService.KeySet()
+
+	response := JSONWebKeySetResponse{Keys: make([]JSONWebKeyResponse, 0, len(keys))}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, JSONWebKeyResponse{
+			KeyType:   key.KeyType,
+			Curve:     key.Curve,
+			X:         key.X,
+			KeyID:     key.KeyID,
+			Use:       key.Use,
+			Algorithm: key.Algorithm,
+		})
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUserInfo godoc
+// @Summary Get claims about the signed-in user
+// @Description OpenID Connect userinfo endpoint. Returns the claims about the user that the access token's scopes grant: name and username for profile, email and email_verified for email, phone_number for phone. Requires an access token issued to an OAuth client with the openid scope.
+// @Tags oidc
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} OAuthErrorResponse
+// @Router /oidc/userinfo [get]
+// @Router /oidc/userinfo [post]
+func (h *OIDCHandler) GetUserInfo(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	claims, ok := c.Get("access_claims")
+	if !ok {
+		respondInsufficientScope(c)
+		return
+	}
+
+	info, err := h.oidcService.UserInfo(user, claims.(*services.AccessClaims))
+	if err != nil {
+		respondInsufficientScope(c)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, info)
+}
+
+// EndSession godoc
+// @Summary Sign out of an OpenID Connect client
+// @Description RP-initiated logout. Ends the session the id_token_hint was issued in together with the session the user approved the client from. If post_logout_redirect_uri is registered for the client, the user agent is redirected to it with the state; otherwise a success message is returned.
+// @Tags oidc
+// @Produce json
+// @Param id_token_hint query string false "ID token issued to the client"
+// @Param client_id query string false "Client ID, required without id_token_hint to redirect"
+// @Param post_logout_redirect_uri query string false "Registered post-logout redirect URI"
+// @Param state query string false "Opaque value returned to the client"
+// @Success 200 {object} SuccessResponse
+// @Success 302
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /oidc/logout [get]
+func (h *OIDCHandler) EndSession(c *gin.Context) {
+	tenant := c.MustGet("tenant").(*models.Tenant)
+
+	var req EndSessionQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	redirectTo, err := h.oidcService.EndSession(c.Request.Context(), tenant, services.EndSessionRequest{
+		IDTokenHint:           req.IDTokenHint,
+		ClientID:              req.ClientID,
+		PostLogoutRedirectURI: req.PostLogoutRedirectURI,
+		State:                 req.State,
+	}, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidIDTokenHint), errors.Is(err, services.ErrOAuthClientNotFound),
+			errors.Is(err, services.ErrInvalidPostLogoutRedirectURI):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign out"})
+		}
+		return
+	}
+
+	if redirectTo != "" {
+		c.Redirect(http.StatusFound, redirectTo)
+		return
+	}
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Signed out successfully"})
+}
+
+func respondInsufficientScope(c *gin.Context) {
+	c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+	c.JSON(http.StatusForbidden, OAuthErrorResponse{
+		Error:            "insufficient_scope",
+		ErrorDescription: services.ErrOpenIDScopeRequired.Error(),
+	})
+}
+
+type EndSessionQuery struct {
+	IDTokenHint           string `form:"id_token_hint"`
+	ClientID              string `form:"client_id"`
+	PostLogoutRedirectURI string `form:"post_logout_redirect_uri"`
+	State                 string `form:"state"`
+}
+
+type OpenIDConfigurationResponse struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	EndSessionEndpoint                string   `json:"end_session_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+type JSONWebKeySetResponse struct {
+	Keys []JSONWebKeyResponse `json:"keys"`
+}
+
+type JSONWebKeyResponse struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+}
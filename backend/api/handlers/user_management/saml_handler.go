@@ -0,0 +1,143 @@
+package user_management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type SAMLHandler struct {
+	samlService     *services.SAMLService
+	providerService *services.SAMLProviderService
+	authService     *services.AuthenticationService
+}
+
+func NewSAMLHandler(samlService *services.SAMLService, providerService *services.SAMLProviderService, authService *services.AuthenticationService) *SAMLHandler {
+	return &SAMLHandler{
+		samlService:     samlService,
+		providerService: providerService,
+		authService:     authService,
+	}
+}
+
+// ListProviders godoc
+// @Summary List sign-in SAML providers
+// @Description List the SAML identity providers members of the tenant can sign in with, for the login page.
+// @Tags authentication
+// @Produce json
+// @Success 200 {array} FederatedProviderResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/saml/providers [get]
+func (h *SAMLHandler) ListProviders(c *gin.Context) {
+	providers, err := h.providerService.ListEnabledProviders(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list SAML providers"})
+		return
+	}
+
+	response := make([]FederatedProviderResponse, 0, len(providers))
+	for _, provider := range providers {
+		response = append(response, FederatedProviderResponse{ID: provider.ID, Name: provider.Name})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetMetadata godoc
+// @Summary Get SAML service provider metadata
+// @Description Get the metadata describing the tenant as service provider of a SAML identity provider, for registering it there. Assertions are to be posted to the listed assertion consumer service and must be signed.
+// @Tags authentication
+// @Produce xml
+// @Param id path string true "SAML provider ID"
+// @Success 200 {string} string "SAML metadata"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/saml/{id}/metadata [get]
+func (h *SAMLHandler) GetMetadata(c *gin.Context) {
+	metadata, err := h.samlService.Metadata(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondSAMLLoginError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/samlmetadata+xml", metadata)
+}
+
+// StartLogin godoc
+// @Summary Start signing in with a SAML provider
+// @Description Create an authentication request for a SAML identity provider. The user agent is to be sent to the returned URL; the provider posts its response to the assertion consumer service, which completes the sign-in. The request expires after ten minutes.
+// @Tags authentication
+// @Produce json
+// @Param id path string true "SAML provider ID"
+// @Success 200 {object} FederatedAuthorizationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/saml/{id}/login [get]
+func (h *SAMLHandler) StartLogin(c *gin.Context) {
+	authorizationURL, err := h.samlService.StartLogin(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondSAMLLoginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, FederatedAuthorizationResponse{AuthorizationURL: authorizationURL})
+}
+
+// ConsumeAssertion godoc
+// @Summary SAML assertion consumer service
+// @Description Complete signing in with the SAML response an identity provider posts. The assertion must be signed with the provider's certificate, be addressed to this service provider, and answer a request started at /auth/saml/{id}/login; each response is accepted once. Users are found by their linked account, provisioned if the provider is trusted with their email address, and given the roles mapped from their groups as with OpenID Connect providers; a user who already has the email address must confirm the link at /me/federated-identities first. MFA-enabled users get a temporary token to complete the sign-in at /auth/verify-mfa.
+// @Tags authentication
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param id path string true "SAML provider ID"
+// @Param SAMLResponse formData string true "Base64 encoded SAML response"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /auth/saml/{id}/acs [post]
+func (h *SAMLHandler) ConsumeAssertion(c *gin.Context) {
+	var req SAMLResponseRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, accessToken, refreshToken, err := h.samlService.CompleteLogin(c.Request.Context(), c.Param("id"), req.SAMLResponse, clientInfo(c))
+	if err != nil && err != services.ErrMFARequired {
+		respondSAMLLoginError(c, err)
+		return
+	}
+
+	respondFederatedLogin(c, h.authService, user, accessToken, refreshToken, err)
+}
+
+func respondSAMLLoginError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrInvalidID:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case services.ErrSAMLProviderNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "SAML provider not found"})
+	case services.ErrInvalidSAMLResponse, services.ErrInvalidSAMLSignature, services.ErrSAMLAuthnFailed:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case services.ErrFederatedEmailNotVerified, services.ErrEmailDomainNotAllowed:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case services.ErrAccountDisabled:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+	case services.ErrFederatedLinkPending:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in with SAML provider"})
+	}
+}
+
+type SAMLResponseRequest struct {
+	SAMLResponse string `form:"SAMLResponse" binding:"required"`
+}
@@ -0,0 +1,224 @@
+package user_management
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type SessionHandler struct {
+	sessionService *services.SessionService
+}
+
+func NewSessionHandler(sessionService *services.SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// ListMySessions godoc
+// @Summary List sessions
+// @Description List the caller's active sessions, most recently used first. The session of the calling access token is marked as current.
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} SessionResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /me/sessions [get]
+func (h *SessionHandler) ListMySessions(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+	h.listSessions(c, user.ID.String())
+}
+
+// RevokeMySession godoc
+// @Summary Revoke a session
+// @Description Sign out one of the caller's sessions, which may be the current one
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /me/sessions/{id} [delete]
+func (h *SessionHandler) RevokeMySession(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+	h.revokeSession(c, user.ID.String(), c.Param("id"))
+}
+
+// RevokeMyOtherSessions godoc
+// @Summary Revoke all other sessions
+// @Description Sign the caller out everywhere except the session of the calling access token
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} RevokeSessionsResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /me/sessions [delete]
+func (h *SessionHandler) RevokeMyOtherSessions(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+	h.revokeSessions(c, user.ID.String(), currentSessionID(c))
+}
+
+// ListUserSessions godoc
+// @Summary List a user's sessions
+// @Description List the active sessions of a user in the current tenant, most recently used first
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {array} SessionResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/users/{id}/sessions [get]
+func (h *SessionHandler) ListUserSessions(c *gin.Context) {
+	h.listSessions(c, c.Param("id"))
+}
+
+// RevokeUserSession godoc
+// @Summary Revoke a user's session
+// @Description Sign a user of the current tenant out of one session
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param sessionId path string true "Session ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/users/{id}/sessions/{sessionId} [delete]
+func (h *SessionHandler) RevokeUserSession(c *gin.Context) {
+	h.revokeSession(c, c.Param("id"), c.Param("sessionId"))
+}
+
+// RevokeUserSessions godoc
+// @Summary Revoke all of a user's sessions
+// @Description Sign a user of the current tenant out everywhere
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} RevokeSessionsResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/users/{id}/sessions [delete]
+func (h *SessionHandler) RevokeUserSessions(c *gin.Context) {
+	h.revokeSessions(c, c.Param("id"), nil)
+}
+
+func (h *SessionHandler) listSessions(c *gin.Context, userID string) {
+	sessions, err := h.sessionService.ListSessions(c.Request.Context(), userID)
+	if err != nil {
+		respondSessionError(c, err, "Failed to list sessions")
+		return
+	}
+
+	current := currentSessionID(c)
+	response := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, newSessionResponse(session, current))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *SessionHandler) revokeSession(c *gin.Context, userID, sessionID string) {
+	actor := c.MustGet("user").(*models.User)
+
+	if err := h.sessionService.RevokeSession(c.Request.Context(), actor, userID, sessionID, clientInfo(c)); err != nil {
+		respondSessionError(c, err, "Failed to revoke session")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Session revoked successfully"})
+}
+
+func (h *SessionHandler) revokeSessions(c *gin.Context, userID string, keep *uuid.UUID) {
+	actor := c.MustGet("user").(*models.User)
+
+	revoked, err := h.sessionService.RevokeSessions(c.Request.Context(), actor, userID, keep, clientInfo(c))
+	if err != nil {
+		respondSessionError(c, err, "Failed to revoke sessions")
+		return
+	}
+
+	c.JSON(http.StatusOK, RevokeSessionsResponse{Revoked: revoked})
+}
+
+// currentSessionID returns the session of the calling access token, or nil
+// when the caller authenticated otherwise.
+func currentSessionID(c *gin.Context) *uuid.UUID {
+	value, ok := c.Get("access_claims")
+	if !ok {
+		return nil
+	}
+	claims := value.(*services.AccessClaims)
+	if claims.SessionID == uuid.Nil {
+		return nil
+	}
+	return &claims.SessionID
+}
+
+func respondSessionError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case services.ErrSessionNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+	case services.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func newSessionResponse(session *models.Session, current *uuid.UUID) SessionResponse {
+	response := SessionResponse{
+		ID:         session.ID,
+		DeviceID:   session.DeviceID,
+		ClientID:   session.OAuthClientID,
+		Scopes:     session.Scopes,
+		AuthMethod: session.AuthMethod,
+		IP:         session.IP,
+		UserAgent:  session.UserAgent,
+		Current:    current != nil && *current == session.ID,
+		CreatedAt:  session.CreatedAt,
+		LastUsedAt: session.LastUsedAt,
+		ExpiresAt:  session.ExpiresAt,
+	}
+	if session.Device != nil {
+		response.DeviceName = session.Device.Name
+	}
+	return response
+}
+
+type SessionResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	DeviceID   *uuid.UUID `json:"device_id,omitempty"`
+	DeviceName string     `json:"device_name,omitempty"`
+	ClientID   *uuid.UUID `json:"oauth_client_id,omitempty"`
+	Scopes     string     `json:"scopes,omitempty"`
+	AuthMethod string     `json:"auth_method"`
+	IP         string     `json:"ip"`
+	UserAgent  string     `json:"user_agent"`
+	Current    bool       `json:"current"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+}
+
+type RevokeSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
+}
@@ -0,0 +1,129 @@
+package user_management
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type SigningKeyHandler struct {
+	signingKeyService *services.SigningKeyService
+}
+
+func NewSigningKeyHandler(signingKeyService *services.SigningKeyService) *SigningKeyHandler {
+	return &SigningKeyHandler{
+		signingKeyService: signingKeyService,
+	}
+}
+
+// ListSigningKeys godoc
+// @Summary List signing keys
+// @Description List the keys of the token signing key ring, oldest first. Active keys sign and verify tokens, verify-only keys verify tokens until retire_at and retired keys verify nothing. Requires super admin access.
+// @Tags signing-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} SigningKeyResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/signing-keys [get]
+func (h *SigningKeyHandler) ListSigningKeys(c *gin.Context) {
+	keys, err := h.signingKeyService.ListKeys(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list signing keys"})
+		return
+	}
+
+	response := make([]SigningKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, newSigningKeyResponse(key))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RotateSigningKey godoc
+// @Summary Rotate the signing key
+// @Description Generate a new active signing key. The previous key becomes verify-only, so tokens it signed keep working for the configured grace period. Requires super admin access.
+// @Tags signing-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} SigningKeyResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/signing-keys/rotate [post]
+func (h *SigningKeyHandler) RotateSigningKey(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	key, err := h.signingKeyService.RotateKey(c.Request.Context(), actor, clientInfo(c))
+	if err != nil {
+		if err == services.ErrKeyStoreReadOnly {
+			c.JSON(http.StatusConflict, gin.H{"error": "Signing keys cannot be rotated without a signing key store"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate signing key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newSigningKeyResponse(key))
+}
+
+// RetireSigningKey godoc
+// @Summary Retire a signing key
+// @Description Stop a rotated signing key from verifying tokens before its grace period ends, e.g. because it was compromised. Tokens it signed stop working at once. The active key must be rotated before it can be retired. Requires super admin access.
+// @Tags signing-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param kid path string true "Key ID (PASERK k2.pid)"
+// @Success 200 {object} SigningKeyResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/signing-keys/{kid}/retire [post]
+func (h *SigningKeyHandler) RetireSigningKey(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	key, err := h.signingKeyService.RetireKey(c.Request.Context(), actor, c.Param("kid"), clientInfo(c))
+	if err != nil {
+		switch err {
+		case services.ErrSigningKeyNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Signing key not found"})
+		case services.ErrRetireActiveSigningKey:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case services.ErrKeyStoreReadOnly:
+			c.JSON(http.StatusConflict, gin.H{"error": "Signing keys cannot be retired without a signing key store"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retire signing key"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newSigningKeyResponse(key))
+}
+
+func newSigningKeyResponse(key *services.SigningKey) SigningKeyResponse {
+	return SigningKeyResponse{
+		KeyID:     key.ID,
+		PublicKey: key.PublicKey().PASERK(),
+		State:     string(key.State),
+		CreatedAt: key.CreatedAt,
+		RetireAt:  key.RetireAt,
+	}
+}
+
+type SigningKeyResponse struct {
+	KeyID     string     `json:"kid"`
+	PublicKey string     `json:"public_key"`
+	State     string     `json:"state"`
+	CreatedAt time.Time  `json:"created_at"`
+	RetireAt  *time.Time `json:"retire_at,omitempty"`
+}
@@ -0,0 +1,219 @@
+package user_management
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type TenantHandler struct {
+	tenantService *services.TenantService
+}
+
+func NewTenantHandler(tenantService *services.TenantService) *TenantHandler {
+	return &TenantHandler{
+		tenantService: tenantService,
+	}
+}
+
+// CreateTenant godoc
+// @Summary Create a tenant
+// @Description Create a new tenant served on the given domain. Requires super admin access.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateTenantRequest true "Tenant details"
+// @Success 201 {object} TenantResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/tenants [post]
+func (h *TenantHandler) CreateTenant(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	var req CreateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenant, err := h.tenantService.CreateTenant(c.Request.Context(), actor, services.TenantInput{
+		Name:     &req.Name,
+		Domain:   &req.Domain,
+		IsActive: req.IsActive,
+	}, clientInfo(c))
+	if err != nil {
+		if err == services.ErrTenantDomainTaken {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tenant"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newTenantResponse(tenant))
+}
+
+// ListTenants godoc
+// @Summary List tenants
+// @Description List all tenants. Requires super admin access.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} TenantResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/tenants [get]
+func (h *TenantHandler) ListTenants(c *gin.Context) {
+	tenants, err := h.tenantService.ListTenants(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list tenants"})
+		return
+	}
+
+	response := make([]TenantResponse, 0, len(tenants))
+	for _, tenant := range tenants {
+		response = append(response, newTenantResponse(tenant))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTenant godoc
+// @Summary Get a tenant
+// @Description Get a tenant by ID. Requires super admin access.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tenant ID"
+// @Success 200 {object} TenantResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/tenants/{id} [get]
+func (h *TenantHandler) GetTenant(c *gin.Context) {
+	tenant, err := h.tenantService.GetTenant(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newTenantResponse(tenant))
+}
+
+// UpdateTenant godoc
+// @Summary Update a tenant
+// @Description Update the name, domain or active flag of a tenant. Omitted fields are left unchanged. Requires super admin access.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tenant ID"
+// @Param request body UpdateTenantRequest true "Tenant fields to change"
+// @Success 200 {object} TenantResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/tenants/{id} [patch]
+func (h *TenantHandler) UpdateTenant(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	var req UpdateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tenant, err := h.tenantService.UpdateTenant(c.Request.Context(), actor, c.Param("id"), services.TenantInput{
+		Name:     req.Name,
+		Domain:   req.Domain,
+		IsActive: req.IsActive,
+	}, clientInfo(c))
+	if err != nil {
+		switch err {
+		case services.ErrTenantNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
+		case services.ErrTenantDomainTaken:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tenant"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newTenantResponse(tenant))
+}
+
+// DeleteTenant godoc
+// @Summary Delete a tenant
+// @Description Delete a tenant. Requests addressed to it are rejected afterwards. Requires super admin access.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tenant ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/tenants/{id} [delete]
+func (h *TenantHandler) DeleteTenant(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	if err := h.tenantService.DeleteTenant(c.Request.Context(), actor, c.Param("id"), clientInfo(c)); err != nil {
+		switch err {
+		case services.ErrTenantNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
+		case services.ErrCannotDeleteTenant:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tenant"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "Tenant deleted successfully"})
+}
+
+func newTenantResponse(tenant *models.Tenant) TenantResponse {
+	return TenantResponse{
+		ID:        tenant.ID,
+		Name:      tenant.Name,
+		Domain:    tenant.Domain,
+		IsActive:  tenant.IsActive,
+		CreatedAt: tenant.CreatedAt,
+		UpdatedAt: tenant.UpdatedAt,
+	}
+}
+
+type CreateTenantRequest struct {
+	Name     string `json:"name" binding:"required,max=255"`
+	Domain   string `json:"domain" binding:"required,hostname,max=255"`
+	IsActive *bool  `json:"is_active"`
+}
+
+type UpdateTenantRequest struct {
+	Name     *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Domain   *string `json:"domain" binding:"omitempty,hostname,max=255"`
+	IsActive *bool   `json:"is_active"`
+}
+
+type TenantResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Domain    string    `json:"domain"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,92 @@
+package user_management
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+type UserAdminHandler struct {
+	authService *services.AuthenticationService
+}
+
+func NewUserAdminHandler(authService *services.AuthenticationService) *UserAdminHandler {
+	return &UserAdminHandler{
+		authService: authService,
+	}
+}
+
+// UnlockUser godoc
+// @Summary Unlock a user account
+// @Description Clear the failed login counter and temporary lock of a user account
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /admin/users/{id}/unlock [post]
+func (h *UserAdminHandler) UnlockUser(c *gin.Context) {
+	actor := c.MustGet("user").(*models.User)
+
+	if err := h.authService.UnlockUser(c.Request.Context(), actor, c.Param("id"), clientInfo(c)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: "User unlocked successfully"})
+}
+
+// DeactivateUser godoc
+// @Summary Deactivate a user account
+// @Description Prevent a user from logging in, and sign them out of every session. Access tokens already issued to them are revoked.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/users/{id}/deactivate [post]
+func (h *UserAdminHandler) DeactivateUser(c *gin.Context) {
+	h.setUserActive(c, false, "User deactivated successfully")
+}
+
+// ActivateUser godoc
+// @Summary Activate a user account
+// @Description Allow a deactivated user to log in again
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /admin/users/{id}/activate [post]
+func (h *UserAdminHandler) ActivateUser(c *gin.Context) {
+	h.setUserActive(c, true, "User activated successfully")
+}
+
+func (h *UserAdminHandler) setUserActive(c *gin.Context, active bool, message string) {
+	actor := c.MustGet("user").(*models.User)
+
+	if err := h.authService.SetUserActive(c.Request.Context(), actor, c.Param("id"), active, clientInfo(c)); err != nil {
+		if err == services.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{Message: message})
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+// SuperAdminMiddleware restricts platform-wide operations, such as managing
+// tenants, to super admins.
+func SuperAdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := c.MustGet("user").(*models.User)
+
+		if !user.IsSuperAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Super admin access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -6,11 +6,34 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/josy-coder/adminsuite/internal/models"
 	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+	"github.com/josy-coder/adminsuite/internal/tenancy"
 )
 
-func AuthMiddleware(authService *services.AuthenticationService) gin.HandlerFunc {
+const APIKeyHeader = "X-API-Key"
+
+// userFromClaimsKey marks requests whose "user" was rebuilt from access token
+// claims rather than loaded; see LoadUserMiddleware.
+const userFromClaimsKey = "user_from_claims"
+
+func AuthMiddleware(authService *services.AuthenticationService, apiKeyService *services.APIKeyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if rawKey := c.GetHeader(APIKeyHeader); rawKey != "" {
+			user, apiKey, permissions, err := apiKeyService.AuthenticateAPIKey(c.Request.Context(), rawKey)
+			if err != nil || !belongsToTenant(c, user) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired API key"})
+				c.Abort()
+				return
+			}
+
+			c.Set("user", user)
+			c.Set("api_key", apiKey)
+			c.Set("api_key_permissions", permissions)
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is missing"})
@@ -26,21 +49,158 @@ func AuthMiddleware(authService *services.AuthenticationService) gin.HandlerFunc
 		}
 
 		token := bearerToken[1]
-		claims, err := authService.ValidateToken(token)
+		claims, err := authService.ValidateToken(c.Request.Context(), token)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
 
-		user, err := authService.GetUserByID(claims.Subject)
-		if err != nil {
+		// Super admins may act in any tenant, so the owner of the token is
+		// looked up across tenants and checked against the request's tenant.
+		user, fromClaims, err := authService.Principal(tenancy.WithAllTenants(c.Request.Context()), claims)
+		if err != nil || !belongsToTenant(c, user) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 			c.Abort()
 			return
 		}
 
 		c.Set("user", user)
+		c.Set("access_claims", claims)
+		if fromClaims {
+			// The claims are current, so the permissions they carry are
+			// used for authorization instead of resolving the user's roles.
+			c.Set(userFromClaimsKey, true)
+			c.Set(permissionsKey, services.NewPermissionSet(claims.Permissions))
+		}
+		c.Next()
+	}
+}
+
+// LoadUserMiddleware replaces a user rebuilt from access token claims with
+// the stored user. It guards routes that read or save user fields the claims
+// do not carry, such as MFA settings or the attributes access policies match.
+func LoadUserMiddleware(authService *services.AuthenticationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool(userFromClaimsKey) {
+			c.Next()
+			return
+		}
+
+		claimed := c.MustGet("user").(*models.User)
+		user, err := authService.GetUserByID(tenancy.WithAllTenants(c.Request.Context()), claimed.ID.String())
+		if err != nil || !belongsToTenant(c, user) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			c.Abort()
+			return
+		}
+
+		c.Set("user", user)
+		c.Set(userFromClaimsKey, false)
+		c.Next()
+	}
+}
+
+// belongsToTenant reports whether user may act in the tenant resolved for the
+// request. Super admins may act in any tenant.
+func belongsToTenant(c *gin.Context, user *models.User) bool {
+	value, ok := c.Get("tenant")
+	if !ok || user.IsSuperAdmin {
+		return true
+	}
+	return user.TenantID == value.(*models.Tenant).ID
+}
+
+// RequireSessionMiddleware rejects requests authenticated with an API key
+// or with a token issued to an OAuth client. It guards account management
+// routes such as MFA and API key management, which must not be reachable
+// with a leaked non-interactive credential or by third-party applications.
+func RequireSessionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("api_key"); ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This endpoint cannot be used with an API key"})
+			c.Abort()
+			return
+		}
+		if _, ok := oauthClientClaims(c); ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This endpoint cannot be used with a token issued to an OAuth client"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// oauthClientClaims returns the claims of the access token of the request if
+// it was issued to an OAuth client.
+func oauthClientClaims(c *gin.Context) (*services.AccessClaims, bool) {
+	value, ok := c.Get("access_claims")
+	if !ok {
+		return nil, false
+	}
+	claims := value.(*services.AccessClaims)
+	return claims, claims.ClientID != ""
+}
+
+// APIKeyScopeMiddleware requires requests authenticated with an API key to
+// carry the given permission in the key's scopes, and tokens issued to an
+// OAuth client to carry it in their permissions. Session requests pass
+// through unchanged.
+func APIKeyScopeMiddleware(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, ok := oauthClientClaims(c); ok {
+			if !services.NewPermissionSet(claims.Permissions).Has(permission) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "OAuth client token is missing the required permission: " + permission})
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		value, ok := c.Get("api_key_permissions")
+		if !ok {
+			c.Next()
+			return
+		}
+
+		// Key scopes may be wildcards such as users:*, so they are matched
+		// the same way as the permissions of roles.
+		if !services.NewPermissionSet(value.([]string)).Has(permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key is missing the required permission: " + permission})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// MFAEnrollmentMiddleware rejects sessions of users who must enable MFA
+// under their tenant's auth policy but have not done so yet, or whose MFA
+// method the policy no longer allows. It is applied
+// to every authenticated group except /mfa, which those users need to
+// enroll. API key requests pass through; they are non-interactive and
+// limited by the key's scopes instead.
+func MFAEnrollmentMiddleware(authService *services.AuthenticationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("api_key"); ok {
+			c.Next()
+			return
+		}
+
+		user := c.MustGet("user").(*models.User)
+		required, err := authService.RequiresMFAEnrollment(c.Request.Context(), user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load auth policy"})
+			c.Abort()
+			return
+		}
+		if required {
+			c.JSON(http.StatusForbidden, gin.H{"error": "MFA enrollment required"})
+			c.Abort()
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+// permissionsKey is the Gin context key under which the caller's permission
+// set is memoized for the rest of the request.
+const permissionsKey = "permissions"
+
+// RequirePermission allows the request only if the caller holds permission.
+func RequirePermission(authorizationService *services.AuthorizationService, permission string) gin.HandlerFunc {
+	return RequireAllPermissions(authorizationService, permission)
+}
+
+// RequireAnyPermission allows the request if the caller holds at least one of
+// permissions.
+func RequireAnyPermission(authorizationService *services.AuthorizationService, permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted, ok := callerPermissions(c, authorizationService)
+		if !ok {
+			return
+		}
+		if !granted.HasAny(permissions...) {
+			denyPermission(c, "any", permissions)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAllPermissions allows the request only if the caller holds every one
+// of permissions.
+func RequireAllPermissions(authorizationService *services.AuthorizationService, permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted, ok := callerPermissions(c, authorizationService)
+		if !ok {
+			return
+		}
+		if !granted.HasAll(permissions...) {
+			denyPermission(c, "all", permissions)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAccess allows the request only if the caller may perform action
+// under both role permissions and the tenant's access policies. The :id path
+// parameter, when present, identifies the resource the policies see.
+func RequireAccess(authorizationService *services.AuthorizationService, accessPolicyService *services.AccessPolicyService, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted, ok := callerPermissions(c, authorizationService)
+		if !ok {
+			return
+		}
+
+		_, viaAPIKey := c.Get("api_key")
+		req := services.AccessRequest{
+			Action:   action,
+			Resource: map[string]interface{}{},
+			Context: services.AccessContext{
+				IP:        c.ClientIP(),
+				Time:      time.Now(),
+				ViaAPIKey: viaAPIKey,
+			},
+		}
+		if id := c.Param("id"); id != "" {
+			req.Resource["id"] = id
+		}
+		if value, ok := c.Get("access_claims"); ok {
+			if verifiedAt := value.(*services.AccessClaims).MFAVerifiedAt; !verifiedAt.IsZero() {
+				req.Context.MFAVerifiedAt = &verifiedAt
+			}
+		}
+
+		decision, err := accessPolicyService.Evaluate(c.Request.Context(), c.MustGet("user").(*models.User), granted, req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to evaluate access policies"})
+			c.Abort()
+			return
+		}
+		if !decision.Allowed {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":  "Access denied",
+				"action": action,
+				"reason": decision.Reason,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// callerPermissions returns the permission set of the authenticated caller,
+// computing it on first use. Requests made with an API key are limited to
+// the key's scopes, which AuthMiddleware has already narrowed to what the
+// owner holds, and tokens issued to an OAuth client to the permissions of
+// their scopes. If the permissions cannot be resolved the request is aborted
+// and ok is false.
+func callerPermissions(c *gin.Context, authorizationService *services.AuthorizationService) (permissions services.PermissionSet, ok bool) {
+	if value, ok := c.Get(permissionsKey); ok {
+		return value.(services.PermissionSet), true
+	}
+
+	if scopes, ok := c.Get("api_key_permissions"); ok {
+		permissions = services.NewPermissionSet(scopes.([]string))
+	} else {
+		var err error
+		permissions, err = authorizationService.UserPermissions(c.Request.Context(), c.MustGet("user").(*models.User))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve permissions"})
+			c.Abort()
+			return permissions, false
+		}
+		if claims, ok := oauthClientClaims(c); ok {
+			permissions = permissions.Intersect(services.NewPermissionSet(claims.Permissions))
+		}
+	}
+
+	c.Set(permissionsKey, permissions)
+	return permissions, true
+}
+
+func denyPermission(c *gin.Context, mode string, permissions []string) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":                "Insufficient permissions",
+		"required_permissions": permissions,
+		"match":                mode,
+	})
+	c.Abort()
+}
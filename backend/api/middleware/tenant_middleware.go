@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+	"github.com/josy-coder/adminsuite/internal/tenancy"
+)
+
+// TenantHeader lets clients that cannot control the Host header, such as
+// server-to-server integrations, name the tenant explicitly by ID or domain.
+const TenantHeader = "X-Tenant"
+
+// TenantMiddleware resolves the tenant a request is addressed to and stores
+// it in the context under "tenant". The tenant ID is also attached to the
+// request context, which scopes every repository query made while handling
+// the request. The X-Tenant header takes precedence over the request host.
+// Unknown and inactive tenants are rejected.
+func TenantMiddleware(tenantService *services.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var tenant *models.Tenant
+		var err error
+		if identifier := c.GetHeader(TenantHeader); identifier != "" {
+			tenant, err = tenantService.ResolveByIdentifier(c.Request.Context(), identifier)
+		} else {
+			tenant, err = tenantService.ResolveByHost(c.Request.Context(), c.Request.Host)
+		}
+		if err != nil {
+			switch err {
+			case services.ErrTenantNotFound:
+				c.JSON(http.StatusNotFound, gin.H{"error": "Tenant not found"})
+			case services.ErrTenantInactive:
+				c.JSON(http.StatusForbidden, gin.H{"error": "Tenant is inactive"})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve tenant"})
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set("tenant", tenant)
+		c.Request = c.Request.WithContext(tenancy.WithTenant(c.Request.Context(), tenant.ID))
+		c.Next()
+	}
+}
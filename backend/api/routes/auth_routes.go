@@ -5,24 +5,95 @@ import (
 
 	handlers "github.com/josy-coder/adminsuite/api/handlers/user_management"
 	"github.com/josy-coder/adminsuite/api/middleware"
-	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
 	services "github.com/josy-coder/adminsuite/internal/services/user_management"
 )
 
-func SetupRoutes(r *gin.Engine, authService *services.AuthenticationService, mfaService *services.MFAService, userRepo user_management.UserRepository) {
-	authHandler := handlers.NewAuthenticationHandler(authService, mfaService)
-	mfaHandler := handlers.NewMFAHandler(authService, mfaService, userRepo)
+func SetupRoutes(r *gin.Engine, authService *services.AuthenticationService, mfaService *services.MFAService, verificationService *services.EmailVerificationService, auditService *services.AuditService, apiKeyService *services.APIKeyService, deviceService *services.DeviceService, tenantService *services.TenantService, policyService *services.AuthPolicyService, brandingService *services.BrandingService, authorizationService *services.AuthorizationService, accessPolicyService *services.AccessPolicyService, sessionService *services.SessionService, signingKeyService *services.SigningKeyService, oauthService *services.OAuthService, oauthClientService *services.OAuthClientService, oidcService *services.OIDCService, identityProviderService *services.IdentityProviderService, federationService *services.FederationService, samlProviderService *services.SAMLProviderService, samlService *services.SAMLService) {
+	authHandler := handlers.NewAuthenticationHandler(authService, mfaService, verificationService, deviceService)
+	mfaHandler := handlers.NewMFAHandler(authService, mfaService, auditService)
+	userAdminHandler := handlers.NewUserAdminHandler(authService)
+	auditLogHandler := handlers.NewAuditLogHandler(auditService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	deviceHandler := handlers.NewDeviceHandler(deviceService)
+	tenantHandler := handlers.NewTenantHandler(tenantService)
+	authPolicyHandler := handlers.NewAuthPolicyHandler(policyService)
+	brandingHandler := handlers.NewBrandingHandler(brandingService)
+	authorizationHandler := handlers.NewAuthorizationHandler(authorizationService)
+	accessPolicyHandler := handlers.NewAccessPolicyHandler(accessPolicyService)
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	signingKeyHandler := handlers.NewSigningKeyHandler(signingKeyService)
+	oauthHandler := handlers.NewOAuthHandler(oauthService)
+	oauthClientHandler := handlers.NewOAuthClientHandler(oauthClientService)
+	oidcHandler := handlers.NewOIDCHandler(oidcService)
+	federatedLoginHandler := handlers.NewFederatedLoginHandler(federationService, identityProviderService, authService)
+	identityProviderHandler := handlers.NewIdentityProviderHandler(identityProviderService, federationService)
+	samlHandler := handlers.NewSAMLHandler(samlService, samlProviderService, authService)
+	samlProviderHandler := handlers.NewSAMLProviderHandler(samlProviderService, samlService)
 
-	auth := r.Group("/api/v1/auth")
+	authMiddleware := middleware.AuthMiddleware(authService, apiKeyService)
+	mfaEnrollment := middleware.MFAEnrollmentMiddleware(authService)
+	loadUser := middleware.LoadUserMiddleware(authService)
+
+	r.Use(middleware.RequestIDMiddleware())
+
+	// Public branding is keyed by the domain query parameter rather than the
+	// request host, so it is registered outside the tenant-resolving group.
+	r.GET("/api/v1/branding", brandingHandler.GetPublicBranding)
+
+	// Services verifying AdminSuite tokens fetch the key set without
+	// belonging to a tenant.
+	r.GET("/api/v1/auth/keys", authHandler.GetTokenKeys)
+	r.GET("/api/v1/oidc/jwks", oidcHandler.GetKeySet)
+
+	// OpenID Connect discovery lives at the root of each tenant's issuer.
+	r.GET("/.well-known/openid-configuration", middleware.TenantMiddleware(tenantService), oidcHandler.GetConfiguration)
+
+	v1 := r.Group("/api/v1")
+	v1.Use(middleware.TenantMiddleware(tenantService))
+
+	auth := v1.Group("/auth")
 	{
 		auth.POST("/register", authHandler.Register)
 		auth.POST("/login", authHandler.Login)
 		auth.POST("/refresh", authHandler.RefreshToken)
+		auth.POST("/logout", authHandler.Logout)
 		auth.POST("/verify-mfa", authHandler.VerifyMFA)
+		auth.POST("/password/forgot", authHandler.ForgotPassword)
+		auth.POST("/password/reset", authHandler.ResetPassword)
+		auth.POST("/email/verify", authHandler.VerifyEmail)
+		auth.POST("/email/resend", authHandler.ResendVerificationEmail)
+		auth.GET("/federated/providers", federatedLoginHandler.ListProviders)
+		auth.GET("/federated/:id/authorize", federatedLoginHandler.StartLogin)
+		auth.POST("/federated/callback", federatedLoginHandler.CompleteLogin)
+		auth.GET("/saml/providers", samlHandler.ListProviders)
+		auth.GET("/saml/:id/metadata", samlHandler.GetMetadata)
+		auth.GET("/saml/:id/login", samlHandler.StartLogin)
+		auth.POST("/saml/:id/acs", samlHandler.ConsumeAssertion)
+	}
+
+	// OAuth clients authenticate themselves at the token endpoint, while the
+	// authorization endpoint acts for the signed-in user.
+	v1.POST("/oauth/token", oauthHandler.Token)
+
+	oauth := v1.Group("/oauth")
+	oauth.Use(authMiddleware, middleware.RequireSessionMiddleware(), mfaEnrollment)
+	{
+		oauth.GET("/authorize", oauthHandler.GetAuthorization)
+		oauth.POST("/authorize", oauthHandler.Authorize)
+	}
+
+	// Relying parties send users to the logout endpoint without a token.
+	v1.GET("/oidc/logout", oidcHandler.EndSession)
+
+	oidc := v1.Group("/oidc")
+	oidc.Use(authMiddleware, loadUser)
+	{
+		oidc.GET("/userinfo", oidcHandler.GetUserInfo)
+		oidc.POST("/userinfo", oidcHandler.GetUserInfo)
 	}
 
-	mfa := r.Group("/api/v1/mfa")
-	mfa.Use(middleware.AuthMiddleware(authService))
+	mfa := v1.Group("/mfa")
+	mfa.Use(authMiddleware, middleware.RequireSessionMiddleware(), loadUser)
 	{
 		mfa.POST("/setup/totp", mfaHandler.SetupTOTP)
 		mfa.POST("/verify/totp", mfaHandler.VerifyTOTP)
@@ -32,4 +103,117 @@ func SetupRoutes(r *gin.Engine, authService *services.AuthenticationService, mfa
 		mfa.POST("/verify/backup", mfaHandler.VerifyBackupCode)
 		mfa.POST("/disable", mfaHandler.DisableMFA)
 	}
+
+	apiKeys := v1.Group("/api-keys")
+	apiKeys.Use(authMiddleware, middleware.RequireSessionMiddleware(), mfaEnrollment, loadUser)
+	{
+		apiKeys.POST("", apiKeyHandler.CreateAPIKey)
+		apiKeys.GET("", apiKeyHandler.ListAPIKeys)
+		apiKeys.POST("/:id/rotate", apiKeyHandler.RotateAPIKey)
+		apiKeys.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
+	}
+
+	devices := v1.Group("/me/devices")
+	devices.Use(authMiddleware, middleware.RequireSessionMiddleware(), mfaEnrollment, loadUser)
+	{
+		devices.GET("", deviceHandler.ListDevices)
+		devices.PATCH("/:id", deviceHandler.RenameDevice)
+		devices.DELETE("/:id", deviceHandler.RevokeDevice)
+	}
+
+	federatedIdentities := v1.Group("/me/federated-identities")
+	federatedIdentities.Use(authMiddleware, middleware.RequireSessionMiddleware(), mfaEnrollment, loadUser)
+	{
+		federatedIdentities.GET("", federatedLoginHandler.ListLinks)
+		federatedIdentities.POST("/:id/confirm", federatedLoginHandler.ConfirmLink)
+		federatedIdentities.DELETE("/:id", federatedLoginHandler.RemoveLink)
+	}
+
+	sessions := v1.Group("/me/sessions")
+	sessions.Use(authMiddleware, middleware.RequireSessionMiddleware(), mfaEnrollment)
+	{
+		sessions.GET("", sessionHandler.ListMySessions)
+		sessions.DELETE("", sessionHandler.RevokeMyOtherSessions)
+		sessions.DELETE("/:id", sessionHandler.RevokeMySession)
+	}
+
+	admin := v1.Group("/admin")
+	admin.Use(authMiddleware, mfaEnrollment)
+	{
+		// Routes addressing a single user are also subject to the tenant's
+		// access policies, which can match on the target user's attributes.
+		admin.POST("/users/:id/unlock", loadUser, middleware.RequireAccess(authorizationService, accessPolicyService, "users:write"), userAdminHandler.UnlockUser)
+		admin.POST("/users/:id/deactivate", loadUser, middleware.RequireAccess(authorizationService, accessPolicyService, "users:write"), userAdminHandler.DeactivateUser)
+		admin.POST("/users/:id/activate", loadUser, middleware.RequireAccess(authorizationService, accessPolicyService, "users:write"), userAdminHandler.ActivateUser)
+		admin.GET("/users/:id/effective-permissions", loadUser, middleware.RequireAccess(authorizationService, accessPolicyService, "users:read"), authorizationHandler.GetEffectivePermissions)
+		admin.PUT("/users/:id/attributes", loadUser, middleware.RequireAccess(authorizationService, accessPolicyService, "users:write"), accessPolicyHandler.SetUserAttributes)
+		admin.GET("/users/:id/sessions", loadUser, middleware.RequireAccess(authorizationService, accessPolicyService, "users:read"), sessionHandler.ListUserSessions)
+		admin.DELETE("/users/:id/sessions", loadUser, middleware.RequireAccess(authorizationService, accessPolicyService, "users:write"), sessionHandler.RevokeUserSessions)
+		admin.DELETE("/users/:id/sessions/:sessionId", loadUser, middleware.RequireAccess(authorizationService, accessPolicyService, "users:write"), sessionHandler.RevokeUserSession)
+		admin.GET("/roles", middleware.RequirePermission(authorizationService, "roles:read"), authorizationHandler.ListRoles)
+		admin.POST("/roles", middleware.RequirePermission(authorizationService, "roles:write"), authorizationHandler.CreateRole)
+		admin.GET("/roles/:id", middleware.RequirePermission(authorizationService, "roles:read"), authorizationHandler.GetRole)
+		admin.PATCH("/roles/:id", middleware.RequirePermission(authorizationService, "roles:write"), authorizationHandler.UpdateRole)
+		admin.DELETE("/roles/:id", middleware.RequirePermission(authorizationService, "roles:write"), authorizationHandler.DeleteRole)
+		// Role assignments need a role permission rather than users:write, so
+		// user administrators cannot hand out roles on their own.
+		admin.POST("/users/:id/roles/:roleId", loadUser, middleware.RequireAnyPermission(authorizationService, "roles:assign", "roles:write"), authorizationHandler.AssignRoleToUser)
+		admin.DELETE("/users/:id/roles/:roleId", loadUser, middleware.RequireAnyPermission(authorizationService, "roles:assign", "roles:write"), authorizationHandler.UnassignRoleFromUser)
+		admin.POST("/roles/:id/permissions/:permissionId", middleware.RequirePermission(authorizationService, "roles:write"), authorizationHandler.AssignPermissionToRole)
+		admin.DELETE("/roles/:id/permissions/:permissionId", middleware.RequirePermission(authorizationService, "roles:write"), authorizationHandler.UnassignPermissionFromRole)
+		admin.GET("/permissions", middleware.RequirePermission(authorizationService, "permissions:read"), authorizationHandler.ListPermissions)
+		admin.POST("/permissions", middleware.RequirePermission(authorizationService, "permissions:write"), authorizationHandler.CreatePermission)
+		admin.GET("/permissions/:id", middleware.RequirePermission(authorizationService, "permissions:read"), authorizationHandler.GetPermission)
+		admin.PATCH("/permissions/:id", middleware.RequirePermission(authorizationService, "permissions:write"), authorizationHandler.UpdatePermission)
+		admin.DELETE("/permissions/:id", middleware.RequirePermission(authorizationService, "permissions:write"), authorizationHandler.DeletePermission)
+		admin.GET("/access-policies", middleware.RequirePermission(authorizationService, "access_policies:read"), accessPolicyHandler.ListAccessPolicies)
+		admin.POST("/access-policies", middleware.RequirePermission(authorizationService, "access_policies:write"), accessPolicyHandler.CreateAccessPolicy)
+		admin.POST("/access-policies/explain", middleware.RequirePermission(authorizationService, "access_policies:read"), accessPolicyHandler.ExplainAccessDecision)
+		admin.GET("/access-policies/:id", middleware.RequirePermission(authorizationService, "access_policies:read"), accessPolicyHandler.GetAccessPolicy)
+		admin.PUT("/access-policies/:id", middleware.RequirePermission(authorizationService, "access_policies:write"), accessPolicyHandler.UpdateAccessPolicy)
+		admin.DELETE("/access-policies/:id", middleware.RequirePermission(authorizationService, "access_policies:write"), accessPolicyHandler.DeleteAccessPolicy)
+		admin.GET("/audit-logs", middleware.RequirePermission(authorizationService, "audit:read"), auditLogHandler.ListAuditLogs)
+		admin.GET("/auth-policy", middleware.RequirePermission(authorizationService, "policy:read"), authPolicyHandler.GetAuthPolicy)
+		admin.PUT("/auth-policy", middleware.RequirePermission(authorizationService, "policy:write"), authPolicyHandler.UpdateAuthPolicy)
+		admin.GET("/branding", middleware.RequirePermission(authorizationService, "branding:read"), brandingHandler.GetBranding)
+		admin.PUT("/branding", middleware.RequirePermission(authorizationService, "branding:write"), brandingHandler.UpdateBranding)
+		admin.GET("/oauth/clients", middleware.RequirePermission(authorizationService, "oauth:read"), oauthClientHandler.ListOAuthClients)
+		admin.POST("/oauth/clients", middleware.RequirePermission(authorizationService, "oauth:write"), oauthClientHandler.CreateOAuthClient)
+		admin.GET("/oauth/clients/:id", middleware.RequirePermission(authorizationService, "oauth:read"), oauthClientHandler.GetOAuthClient)
+		admin.PATCH("/oauth/clients/:id", middleware.RequirePermission(authorizationService, "oauth:write"), oauthClientHandler.UpdateOAuthClient)
+		admin.DELETE("/oauth/clients/:id", middleware.RequirePermission(authorizationService, "oauth:write"), oauthClientHandler.DeleteOAuthClient)
+		admin.POST("/oauth/clients/:id/rotate-secret", middleware.RequirePermission(authorizationService, "oauth:write"), oauthClientHandler.RotateOAuthClientSecret)
+		admin.GET("/oauth/scopes", middleware.RequirePermission(authorizationService, "oauth:read"), oauthClientHandler.ListOAuthScopes)
+		admin.POST("/oauth/scopes", middleware.RequirePermission(authorizationService, "oauth:write"), oauthClientHandler.CreateOAuthScope)
+		admin.PATCH("/oauth/scopes/:id", middleware.RequirePermission(authorizationService, "oauth:write"), oauthClientHandler.UpdateOAuthScope)
+		admin.DELETE("/oauth/scopes/:id", middleware.RequirePermission(authorizationService, "oauth:write"), oauthClientHandler.DeleteOAuthScope)
+		admin.GET("/identity-providers", middleware.RequirePermission(authorizationService, "identity_providers:read"), identityProviderHandler.ListIdentityProviders)
+		admin.POST("/identity-providers", middleware.RequirePermission(authorizationService, "identity_providers:write"), identityProviderHandler.CreateIdentityProvider)
+		admin.GET("/identity-providers/:id", middleware.RequirePermission(authorizationService, "identity_providers:read"), identityProviderHandler.GetIdentityProvider)
+		admin.PATCH("/identity-providers/:id", middleware.RequirePermission(authorizationService, "identity_providers:write"), identityProviderHandler.UpdateIdentityProvider)
+		admin.DELETE("/identity-providers/:id", middleware.RequirePermission(authorizationService, "identity_providers:write"), identityProviderHandler.DeleteIdentityProvider)
+		admin.GET("/saml-providers", middleware.RequirePermission(authorizationService, "identity_providers:read"), samlProviderHandler.ListSAMLProviders)
+		admin.POST("/saml-providers", middleware.RequirePermission(authorizationService, "identity_providers:write"), samlProviderHandler.CreateSAMLProvider)
+		admin.GET("/saml-providers/:id", middleware.RequirePermission(authorizationService, "identity_providers:read"), samlProviderHandler.GetSAMLProvider)
+		admin.PATCH("/saml-providers/:id", middleware.RequirePermission(authorizationService, "identity_providers:write"), samlProviderHandler.UpdateSAMLProvider)
+		admin.DELETE("/saml-providers/:id", middleware.RequirePermission(authorizationService, "identity_providers:write"), samlProviderHandler.DeleteSAMLProvider)
+	}
+
+	tenants := v1.Group("/admin/tenants")
+	tenants.Use(authMiddleware, mfaEnrollment, middleware.SuperAdminMiddleware())
+	{
+		tenants.POST("", middleware.APIKeyScopeMiddleware("tenants:write"), tenantHandler.CreateTenant)
+		tenants.GET("", middleware.APIKeyScopeMiddleware("tenants:read"), tenantHandler.ListTenants)
+		tenants.GET("/:id", middleware.APIKeyScopeMiddleware("tenants:read"), tenantHandler.GetTenant)
+		tenants.PATCH("/:id", middleware.APIKeyScopeMiddleware("tenants:write"), tenantHandler.UpdateTenant)
+		tenants.DELETE("/:id", middleware.APIKeyScopeMiddleware("tenants:write"), tenantHandler.DeleteTenant)
+	}
+
+	signingKeys := v1.Group("/admin/signing-keys")
+	signingKeys.Use(authMiddleware, mfaEnrollment, middleware.SuperAdminMiddleware())
+	{
+		signingKeys.GET("", middleware.APIKeyScopeMiddleware("signing_keys:read"), signingKeyHandler.ListSigningKeys)
+		signingKeys.POST("/rotate", middleware.APIKeyScopeMiddleware("signing_keys:write"), signingKeyHandler.RotateSigningKey)
+		signingKeys.POST("/:kid/retire", middleware.APIKeyScopeMiddleware("signing_keys:write"), signingKeyHandler.RetireSigningKey)
+	}
 }
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net/http"
+	"time"
 	// Import the docs package
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
@@ -32,6 +36,10 @@ import (
 // @in header
 // @name Authorization
 
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
+
 func main() {
 	// Load configuration
 	cfg, err := config.LoadConfig()
@@ -53,16 +61,99 @@ func main() {
 	// Initialize repositories
 	userRepo := user_management.NewUserRepository(db)
 	tokenRepo := user_management.NewTokenRepository(db)
+	passwordResetRepo := user_management.NewPasswordResetRepository(db)
+	tenantRepo := user_management.NewTenantRepository(db)
+	loginAttemptRepo := user_management.NewLoginAttemptRepository(db)
+	auditLogRepo := user_management.NewAuditLogRepository(db)
+	apiKeyRepo := user_management.NewAPIKeyRepository(db)
+	deviceRepo := user_management.NewDeviceRepository(db)
+	sessionRepo := user_management.NewSessionRepository(db)
+	roleRepo := user_management.NewRoleRepository(db)
+	permissionRepo := user_management.NewPermissionRepository(db)
+	accessPolicyRepo := user_management.NewAccessPolicyRepository(db)
+	signingKeyRepo := user_management.NewSigningKeyRepository(db)
+	oauthClientRepo := user_management.NewOAuthClientRepository(db)
+	oauthScopeRepo := user_management.NewOAuthScopeRepository(db)
+	oauthConsentRepo := user_management.NewOAuthConsentRepository(db)
+	oauthCodeRepo := user_management.NewOAuthAuthorizationCodeRepository(db)
+	identityProviderRepo := user_management.NewIdentityProviderRepository(db)
+	federatedIdentityRepo := user_management.NewFederatedIdentityRepository(db)
+	federatedLoginStateRepo := user_management.NewFederatedLoginStateRepository(db)
+	samlProviderRepo := user_management.NewSAMLProviderRepository(db)
+	samlRequestRepo := user_management.NewSAMLRequestRepository(db)
+
+	// Access token revocations are shared through Redis when configured
+	var revocationStore services.RevocationStore = services.NewMemoryRevocationStore()
+	if cfg.RedisURL != "" {
+		redisOptions, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			log.Fatalf("Failed to parse Redis URL: %v", err)
+		}
+		revocationStore = services.NewRedisRevocationStore(redis.NewClient(redisOptions))
+	}
+
+	// Load the token signing key ring, seeded with the configured key
+	if cfg.SigningKeyGracePeriod < services.MinSigningKeyGracePeriod {
+		log.Fatalf("SIGNING_KEY_GRACE_PERIOD must be at least %s", services.MinSigningKeyGracePeriod)
+	}
+	var bootstrapKey *services.SigningKey
+	if cfg.PasetoSigningKey != nil {
+		bootstrapKey = services.NewSigningKey(cfg.PasetoSigningKey)
+	}
+	var signingKeyStore services.SigningKeyStore
+	switch cfg.SigningKeyStore {
+	case config.SigningKeyStoreDatabase:
+		signingKeyStore, err = services.NewDatabaseKeyStore(signingKeyRepo, cfg.SigningKeySecret)
+		if err != nil {
+			log.Fatalf("Failed to open signing key store: %v", err)
+		}
+	case config.SigningKeyStoreFile:
+		signingKeyStore = services.NewFileKeyStore(cfg.SigningKeyFile)
+	default:
+		signingKeyStore = services.NewStaticKeyStore(bootstrapKey)
+	}
+	keyRing, err := services.NewKeyRing(context.Background(), signingKeyStore, bootstrapKey)
+	if err != nil {
+		log.Fatalf("Failed to load signing keys: %v", err)
+	}
 
 	// Initialize services
-	mfaService := services.NewMFAService(userRepo, cfg)
-	authService := services.NewAuthenticationService(userRepo, tokenRepo, cfg.PasetoKey, mfaService)
+	tokenRevocations := services.NewTokenRevocationService(revocationStore)
+	tokenSigner := services.NewTokenSigner(keyRing)
+	emailService := services.NewEmailService(cfg)
+	auditService := services.NewAuditService(auditLogRepo)
+	signingKeyService := services.NewSigningKeyService(keyRing, auditService, cfg.SigningKeyRotationInterval, cfg.SigningKeyGracePeriod)
+	policyService := services.NewAuthPolicyService(tenantRepo, auditService)
+	brandingService := services.NewBrandingService(tenantRepo, auditService)
+	loginProtection := services.NewLoginProtectionService(userRepo, loginAttemptRepo)
+	securityStamps := services.NewSecurityStampService(userRepo, tokenRevocations)
+	verificationService := services.NewEmailVerificationService(userRepo, emailService, brandingService, tokenSigner, cfg.FrontendURL)
+	tenantService := services.NewTenantService(tenantRepo, auditService, cfg.DefaultTenantDomain)
+	tokenHasher := services.NewTokenHasher(cfg.TokenHashKey)
+	sessionService := services.NewSessionService(sessionRepo, tokenRepo, userRepo, tokenHasher, tokenRevocations, auditService)
+	deviceService := services.NewDeviceService(deviceRepo, sessionService, policyService, auditService)
+	mfaService := services.NewMFAService(userRepo, cfg, emailService, policyService, brandingService, securityStamps)
+	authorizationService := services.NewAuthorizationService(userRepo, roleRepo, permissionRepo, oauthScopeRepo, securityStamps, auditService)
+	authService := services.NewAuthenticationService(userRepo, tokenRepo, passwordResetRepo, tokenSigner, tokenHasher, mfaService, emailService, brandingService, verificationService, policyService, loginProtection, auditService, deviceService, authorizationService, securityStamps, sessionService, tokenRevocations, cfg.FrontendURL)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, userRepo, authorizationService, auditService)
+	accessPolicyService := services.NewAccessPolicyService(accessPolicyRepo, userRepo, authorizationService, auditService)
+	oidcService := services.NewOIDCService(tenantRepo, userRepo, oauthClientRepo, sessionService, tokenSigner, auditService, cfg.IssuerURL, cfg.FrontendURL, cfg.DefaultTenantDomain)
+	oauthService := services.NewOAuthService(oauthClientRepo, oauthScopeRepo, oauthConsentRepo, oauthCodeRepo, userRepo, authService, sessionService, oidcService, tokenHasher, auditService)
+	oauthClientService := services.NewOAuthClientService(oauthClientRepo, oauthScopeRepo, oauthConsentRepo, sessionService, auditService)
+	identityProviderClient := services.NewIdentityProviderClient(&http.Client{Timeout: 10 * time.Second})
+	identityProviderService := services.NewIdentityProviderService(identityProviderRepo, federatedIdentityRepo, roleRepo, authorizationService, auditService)
+	federationService := services.NewFederationService(identityProviderRepo, federatedIdentityRepo, federatedLoginStateRepo, userRepo, authService, authorizationService, policyService, identityProviderClient, tokenHasher, auditService, cfg.FrontendURL)
+	samlProviderService := services.NewSAMLProviderService(samlProviderRepo, federatedIdentityRepo, roleRepo, authorizationService, auditService)
+	samlService := services.NewSAMLService(samlProviderRepo, samlRequestRepo, tenantRepo, federationService, oidcService)
+
+	// Keep the key ring current and rotate it on schedule
+	go signingKeyService.RunScheduler(context.Background())
 
 	// Initialize Gin router
 	r := gin.Default()
 
 	// Setup routes
-	routes.SetupRoutes(r, authService, mfaService, userRepo)
+	routes.SetupRoutes(r, authService, mfaService, verificationService, auditService, apiKeyService, deviceService, tenantService, policyService, brandingService, authorizationService, accessPolicyService, sessionService, signingKeyService, oauthService, oauthClientService, oidcService, identityProviderService, federationService, samlProviderService, samlService)
 
 	// Swagger route
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -1,9 +1,25 @@
 package config
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/spf13/viper"
 )
 
+// minTokenHashSecretLength is the shortest TOKEN_HASH_SECRET accepted.
+const minTokenHashSecretLength = 32
+
+// Signing key stores selectable with SIGNING_KEY_STORE.
+const (
+	SigningKeyStoreDatabase = "database"
+	SigningKeyStoreFile     = "file"
+)
+
 type Config struct {
 	DBHost     string `mapstructure:"DB_HOST"`
 	DBPort     string `mapstructure:"DB_PORT"`
@@ -12,9 +28,50 @@ type Config struct {
 	DBName     string `mapstructure:"DB_NAME"`
 	ServerPort string `mapstructure:"SERVER_PORT"`
 
+	FrontendURL string `mapstructure:"FRONTEND_URL"`
+
+	// IssuerURL is the public base URL of the API and the OpenID Connect
+	// issuer of the default tenant. Other tenants issue from their domain.
+	IssuerURL string `mapstructure:"ISSUER_URL"`
+
+	// DefaultTenantDomain is used when the request host does not match any
+	// tenant domain, e.g. when running locally.
+	DefaultTenantDomain string `mapstructure:"DEFAULT_TENANT_DOMAIN"`
+
+	// PasetoPrivateKey is the hex-encoded Ed25519 key, either the 32-byte
+	// seed or the 64-byte private key, that signs tokens. PasetoPublicKey
+	// is optional and, if set, must be its hex-encoded public key. With a
+	// signing key store it only seeds an empty key ring and may be omitted,
+	// in which case the first key is generated.
 	PasetoPublicKey  string `mapstructure:"PASETO_PUBLIC_KEY"`
 	PasetoPrivateKey string `mapstructure:"PASETO_PRIVATE_KEY"`
-	PasetoKey        []byte
+	PasetoSigningKey ed25519.PrivateKey
+
+	// SigningKeyStore selects where the rotating key ring that signs tokens
+	// is kept: "database", sealed under the hex-encoded 32-byte
+	// SigningKeySecret, or "file", in SigningKeyFile. If empty, tokens are
+	// signed with the PASETO key alone and keys cannot be rotated.
+	SigningKeyStore     string `mapstructure:"SIGNING_KEY_STORE"`
+	SigningKeyFile      string `mapstructure:"SIGNING_KEY_FILE"`
+	SigningKeySecretHex string `mapstructure:"SIGNING_KEY_SECRET"`
+	SigningKeySecret    []byte
+
+	// SigningKeyRotationInterval, if positive, is the age at which the
+	// active signing key is rotated automatically. Rotated keys keep
+	// verifying tokens for SigningKeyGracePeriod.
+	SigningKeyRotationInterval time.Duration `mapstructure:"SIGNING_KEY_ROTATION_INTERVAL"`
+	SigningKeyGracePeriod      time.Duration `mapstructure:"SIGNING_KEY_GRACE_PERIOD"`
+
+	// TokenHashSecret keys the hashes refresh and password reset tokens are
+	// stored under. It is required and deliberately separate from the PASETO
+	// key, so rotating one does not affect the other.
+	TokenHashSecret string `mapstructure:"TOKEN_HASH_SECRET"`
+	TokenHashKey    []byte
+
+	// RedisURL, if set, points at a Redis-compatible server that holds
+	// access token revocations, so every instance sees them. Otherwise they
+	// are kept in memory.
+	RedisURL string `mapstructure:"REDIS_URL"`
 
 	SMTPHost     string `mapstructure:"SMTP_HOST"`
 	SMTPPort     int    `mapstructure:"SMTP_PORT"`
@@ -38,6 +95,14 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("DB_PASSWORD", "")
 	viper.SetDefault("DB_NAME", "adminsuitedb")
 	viper.SetDefault("SERVER_PORT", "8080")
+	viper.SetDefault("FRONTEND_URL", "http://localhost:3000")
+	viper.SetDefault("ISSUER_URL", "http://localhost:8080")
+	viper.SetDefault("DEFAULT_TENANT_DOMAIN", "default.adminsuite.com")
+	viper.SetDefault("SIGNING_KEY_STORE", "")
+	viper.SetDefault("SIGNING_KEY_FILE", "")
+	viper.SetDefault("SIGNING_KEY_SECRET", "")
+	viper.SetDefault("SIGNING_KEY_ROTATION_INTERVAL", "0s")
+	viper.SetDefault("SIGNING_KEY_GRACE_PERIOD", "48h")
 
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
@@ -51,7 +116,60 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
-	config.PasetoKey = []byte(config.PasetoPrivateKey)
+	switch config.SigningKeyStore {
+	case "":
+		config.PasetoSigningKey, err = parseSigningKey(config.PasetoPrivateKey, config.PasetoPublicKey)
+	case SigningKeyStoreDatabase, SigningKeyStoreFile:
+		if config.PasetoPrivateKey != "" {
+			config.PasetoSigningKey, err = parseSigningKey(config.PasetoPrivateKey, config.PasetoPublicKey)
+		}
+	default:
+		err = errors.New("SIGNING_KEY_STORE must be empty, database or file")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if config.SigningKeyStore == SigningKeyStoreFile && config.SigningKeyFile == "" {
+		return nil, errors.New("SIGNING_KEY_FILE must be set when signing keys are stored in a file")
+	}
+	if config.SigningKeyStore == SigningKeyStoreDatabase {
+		config.SigningKeySecret, err = hex.DecodeString(config.SigningKeySecretHex)
+		if err != nil || len(config.SigningKeySecret) != 32 {
+			return nil, errors.New("SIGNING_KEY_SECRET must be 32 hex-encoded bytes when signing keys are stored in the database")
+		}
+	}
+
+	if len(config.TokenHashSecret) < minTokenHashSecretLength {
+		return nil, fmt.Errorf("TOKEN_HASH_SECRET must be set to at least %d characters", minTokenHashSecretLength)
+	}
+	config.TokenHashKey = []byte(config.TokenHashSecret)
 
 	return &config, nil
 }
+
+func parseSigningKey(privateKeyHex, publicKeyHex string) (ed25519.PrivateKey, error) {
+	raw, err := hex.DecodeString(privateKeyHex)
+	if err != nil {
+		return nil, errors.New("PASETO_PRIVATE_KEY must be hex encoded")
+	}
+
+	var privateKey ed25519.PrivateKey
+	switch len(raw) {
+	case ed25519.SeedSize:
+		privateKey = ed25519.NewKeyFromSeed(raw)
+	case ed25519.PrivateKeySize:
+		privateKey = ed25519.PrivateKey(raw)
+	default:
+		return nil, errors.New("PASETO_PRIVATE_KEY must be a 32-byte Ed25519 seed or a 64-byte Ed25519 private key")
+	}
+
+	if publicKeyHex != "" {
+		publicKey, err := hex.DecodeString(publicKeyHex)
+		if err != nil || !bytes.Equal(publicKey, privateKey.Public().(ed25519.PublicKey)) {
+			return nil, errors.New("PASETO_PUBLIC_KEY does not match PASETO_PRIVATE_KEY")
+		}
+	}
+
+	return privateKey, nil
+}
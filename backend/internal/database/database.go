@@ -1,6 +1,7 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"log"
 
@@ -9,6 +10,7 @@ import (
 
 	"github.com/josy-coder/adminsuite/internal/config"
 	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/tenancy"
 )
 
 func SetupDatabase(cfg *config.Config) (*gorm.DB, error) {
@@ -29,6 +31,10 @@ func SetupDatabase(cfg *config.Config) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to run migrations: %v", err)
 	}
 
+	if err := db.Use(tenancy.Plugin{}); err != nil {
+		return nil, fmt.Errorf("failed to register tenancy plugin: %v", err)
+	}
+
 	return db, nil
 }
 
@@ -39,16 +45,45 @@ func runMigrations(db *gorm.DB) error {
 		&models.Role{},
 		&models.Permission{},
 		&models.Token{},
+		&models.Session{},
 		&models.PasswordReset{},
 		&models.LoginAttempt{},
 		&models.AuditLog{},
 		&models.APIKey{},
 		&models.Device{},
+		&models.AccessPolicy{},
+		&models.SigningKey{},
+		&models.OAuthClient{},
+		&models.OAuthScope{},
+		&models.OAuthConsent{},
+		&models.OAuthAuthorizationCode{},
+		&models.IdentityProvider{},
+		&models.FederatedIdentity{},
+		&models.FederatedLoginState{},
+		&models.SAMLProvider{},
+		&models.SAMLRequest{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %v", err)
 	}
 
+	// Email and username used to be unique across all tenants. They are now
+	// unique per tenant, so the old global indexes have to go.
+	for _, index := range []string{"idx_users_email", "idx_users_username"} {
+		if db.Migrator().HasIndex(&models.User{}, index) {
+			if err := db.Migrator().DropIndex(&models.User{}, index); err != nil {
+				return fmt.Errorf("failed to drop index %s: %v", index, err)
+			}
+		}
+	}
+
+	// Refresh and password reset tokens used to be stored in plaintext in
+	// tokens.token. The migrations command converts them to keyed hashes
+	// and drops the column; until it has run the server refuses to start.
+	if db.Migrator().HasColumn(&models.Token{}, "token") {
+		return errors.New("tokens are still stored in plaintext, run the migrations command first")
+	}
+
 	log.Println("Migrations completed successfully")
 	return nil
 }
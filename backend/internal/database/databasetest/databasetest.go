@@ -0,0 +1,38 @@
+// Package databasetest provides throwaway databases for tests.
+package databasetest
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/josy-coder/adminsuite/internal/tenancy"
+)
+
+// Open returns an in-memory SQLite database private to the test, with
+// models migrated and queries scoped to tenants as in production.
+func Open(t testing.TB, models ...interface{}) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+uuid.NewString()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	if err := db.Use(tenancy.Plugin{}); err != nil {
+		t.Fatalf("failed to register tenancy plugin: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
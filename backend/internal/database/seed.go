@@ -1,12 +1,19 @@
 package database
 
 import (
+	"context"
+
 	"gorm.io/gorm"
 
 	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/tenancy"
 )
 
 func SeedDatabase(db *gorm.DB) error {
+	// Seeding creates rows for a tenant that does not exist yet, so it runs
+	// outside tenant scoping.
+	db = db.WithContext(tenancy.WithAllTenants(context.Background()))
+
 	// Create a default tenant
 	tenant := models.Tenant{
 		Name:             "Default Tenant",
@@ -24,6 +31,7 @@ func SeedDatabase(db *gorm.DB) error {
 		TenantID:    tenant.ID,
 		Name:        "Admin",
 		Description: "Administrator role",
+		IsAdmin:     true,
 	}
 	userRole := models.Role{
 		TenantID:    tenant.ID,
@@ -46,6 +54,7 @@ func SeedDatabase(db *gorm.DB) error {
 		FirstName:         "Admin",
 		LastName:          "User",
 		IsActive:          true,
+		IsSuperAdmin:      true,
 		MFABackupCodes:    []string{},
 		PreferencesConfig: "{}",
 	}
@@ -14,8 +14,13 @@ type BaseModel struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string" format:"date-time"`
 }
 
+// BeforeCreate assigns new rows an ID. Rows that already have one keep it:
+// gorm upserts associated rows when an association is appended to, and a
+// new ID would insert a copy of the associated row instead.
 func (base *BaseModel) BeforeCreate(tx *gorm.DB) error {
-	base.ID = uuid.New()
+	if base.ID == uuid.Nil {
+		base.ID = uuid.New()
+	}
 	return nil
 }
 
@@ -40,36 +45,50 @@ const (
 
 type User struct {
 	BaseModel
-	TenantID           uuid.UUID `gorm:"type:uuid;index"`
-	Email              string    `gorm:"size:255;uniqueIndex"`
-	Username           string    `gorm:"size:50;uniqueIndex"`
-	Password           string    `gorm:"size:255"`
+	TenantID           uuid.UUID `gorm:"type:uuid;index;uniqueIndex:idx_users_tenant_email;uniqueIndex:idx_users_tenant_username"`
+	Email              string    `gorm:"size:255;uniqueIndex:idx_users_tenant_email"`
+	Username           string    `gorm:"size:50;uniqueIndex:idx_users_tenant_username"`
+	Password           string    `gorm:"size:255" json:"-"`
 	FirstName          string    `gorm:"size:50"`
 	LastName           string    `gorm:"size:50"`
 	PhoneNumber        string    `gorm:"size:20"`
 	IsActive           bool      `gorm:"default:true"`
+	IsSuperAdmin       bool      `gorm:"default:false"`
 	EmailVerified      bool      `gorm:"default:false"`
 	MFAEnabled         bool      `gorm:"default:false"`
-	MFASecret          string    `gorm:"size:64"`
+	MFASecret          string    `gorm:"size:64" json:"-"`
 	MFAMethod          MFAMethod `gorm:"size:10"`
-	MFABackupCodes     []string  `gorm:"type:json"`
-	MFASMSCode         string    `gorm:"size:6"`
-	MFASMSCodeExpiry   time.Time
-	MFAEmailCode       string `gorm:"size:6"`
-	MFAEmailCodeExpiry time.Time
-	MFAHOTPCounter     uint64
+	MFABackupCodes     []string  `gorm:"type:json" json:"-"`
+	MFASMSCode         string    `gorm:"size:6" json:"-"`
+	MFASMSCodeExpiry   time.Time `json:"-"`
+	MFAEmailCode       string    `gorm:"size:6" json:"-"`
+	MFAEmailCodeExpiry time.Time `json:"-"`
+	MFAHOTPCounter     uint64    `json:"-"`
+	FailedLoginCount   int       `gorm:"default:0"`
+	LockedUntil        *time.Time
 	LastLoginAt        *time.Time
 	PasswordChangedAt  *time.Time
-	ProfilePicture     string `gorm:"size:255"`
-	PreferencesConfig  string `gorm:"type:json"`
-	Roles              []Role `gorm:"many2many:user_roles;"`
+	// SecurityStamp changes whenever the user's roles, password or MFA status
+	// change. Access tokens carry it to tell whether their claims are current.
+	SecurityStamp     string `gorm:"size:64" json:"-"`
+	ProfilePicture    string `gorm:"size:255"`
+	PreferencesConfig string `gorm:"type:json"`
+	// Attributes holds administrator-managed key/value pairs, such as the
+	// user's department, that access policies can match on.
+	Attributes string `gorm:"type:jsonb"`
+	Roles      []Role `gorm:"many2many:user_roles;"`
 }
 
 type Role struct {
 	BaseModel
-	TenantID    uuid.UUID    `gorm:"type:uuid;index"`
-	Name        string       `gorm:"size:50"`
-	Description string       `gorm:"size:255"`
+	TenantID    uuid.UUID `gorm:"type:uuid;index"`
+	Name        string    `gorm:"size:50"`
+	Description string    `gorm:"size:255"`
+	// IsAdmin marks the built-in Admin role, which holds every permission.
+	// It is set when the role is seeded and cannot be changed through the API.
+	IsAdmin bool `gorm:"default:false"`
+	// ParentID names the role this role inherits permissions from.
+	ParentID    *uuid.UUID   `gorm:"type:uuid;index"`
 	Permissions []Permission `gorm:"many2many:role_permissions;"`
 }
 
@@ -90,10 +109,48 @@ const (
 
 type Token struct {
 	BaseModel
-	UserID    uuid.UUID `gorm:"type:uuid;index"`
-	Token     string    `gorm:"size:255;uniqueIndex"`
+	UserID    uuid.UUID  `gorm:"type:uuid;index"`
+	DeviceID  *uuid.UUID `gorm:"type:uuid;index"`
+	SessionID *uuid.UUID `gorm:"type:uuid;index"`
+	// TokenHash is a keyed hash of the token. The token itself is only
+	// ever handed to the client.
+	TokenHash string    `gorm:"size:64;uniqueIndex" json:"-"`
 	Type      TokenType `gorm:"size:20"`
 	ExpiresAt time.Time
+	// AuthMethod records how the user authenticated when a refresh token was
+	// first issued, so access tokens minted from it keep reporting it.
+	AuthMethod string `gorm:"size:20"`
+	// RotatedAt is set once a refresh token has been exchanged for a new
+	// one. Rotated tokens are kept until they expire so that presenting one
+	// again can be recognised as reuse.
+	RotatedAt *time.Time
+}
+
+// Session is one sign-in of a user on a client. The refresh tokens rotated
+// within it form one family: it outlives them, ends when its last refresh
+// token expires or it is revoked, and is revoked as a whole when a rotated
+// token is reused. IP and UserAgent are those of the most recent use.
+// Sessions granted to an OAuth client name it in OAuthClientID, and their
+// tokens are limited to the space-separated Scopes the user consented to.
+type Session struct {
+	BaseModel
+	UserID        uuid.UUID  `gorm:"type:uuid;index"`
+	DeviceID      *uuid.UUID `gorm:"type:uuid;index"`
+	Device        *Device    `gorm:"foreignKey:DeviceID"`
+	OAuthClientID *uuid.UUID `gorm:"column:oauth_client_id;type:uuid;index"`
+	// ParentSessionID names the session the user approved the OAuth grant
+	// from. Signing out of a client through OpenID Connect ends both.
+	ParentSessionID *uuid.UUID `gorm:"type:uuid;index"`
+	Scopes          string     `gorm:"size:1024"`
+	AuthMethod      string     `gorm:"size:20"`
+	// MFAVerifiedAt is when the user last passed a second factor for the
+	// session, if ever. Sessions of OAuth clients inherit it from the
+	// session the grant was approved from.
+	MFAVerifiedAt *time.Time
+	IP            string `gorm:"size:45"`
+	UserAgent     string `gorm:"size:255"`
+	LastUsedAt    time.Time
+	ExpiresAt     time.Time `gorm:"index"`
 }
 
 type PasswordReset struct {
@@ -103,37 +160,259 @@ type PasswordReset struct {
 	Token   Token     `gorm:"foreignKey:TokenID"`
 }
 
+type LoginAttemptMethod string
+
+const (
+	LoginAttemptMethodPassword LoginAttemptMethod = "password"
+	LoginAttemptMethodMFA      LoginAttemptMethod = "mfa"
+)
+
 type LoginAttempt struct {
 	BaseModel
-	UserID    uuid.UUID `gorm:"type:uuid;index"`
-	IP        string    `gorm:"size:45"`
-	UserAgent string    `gorm:"size:255"`
+	TenantID  uuid.UUID          `gorm:"type:uuid;index"`
+	UserID    uuid.UUID          `gorm:"type:uuid;index"`
+	Email     string             `gorm:"size:255"`
+	IP        string             `gorm:"size:45;index"`
+	UserAgent string             `gorm:"size:255"`
+	Method    LoginAttemptMethod `gorm:"size:20"`
 	Success   bool
 }
 
 type AuditLog struct {
 	BaseModel
-	UserID   uuid.UUID `gorm:"type:uuid;index"`
-	TenantID uuid.UUID `gorm:"type:uuid;index"`
-	Action   string    `gorm:"size:50"`
-	Resource string    `gorm:"size:50"`
-	Details  string    `gorm:"type:text"`
+	UserID     uuid.UUID `gorm:"type:uuid;index"`
+	TenantID   uuid.UUID `gorm:"type:uuid;index"`
+	Action     string    `gorm:"size:50;index"`
+	Resource   string    `gorm:"size:50;index"`
+	ResourceID string    `gorm:"size:64"`
+	Details    string    `gorm:"type:jsonb"`
+	IP         string    `gorm:"size:45"`
+	RequestID  string    `gorm:"size:64;index"`
 }
 
+// APIKey stores a hash of the key in Key; the plaintext is only returned
+// once, when the key is created or rotated. Prefix is the non-secret part
+// of the key used to look it up.
 type APIKey struct {
 	BaseModel
 	UserID      uuid.UUID `gorm:"type:uuid;index"`
 	TenantID    uuid.UUID `gorm:"type:uuid;index"`
-	Key         string    `gorm:"size:255;uniqueIndex"`
+	Prefix      string    `gorm:"size:16;uniqueIndex"`
+	Key         string    `gorm:"size:255;uniqueIndex" json:"-"`
 	Name        string    `gorm:"size:50"`
 	Permissions string    `gorm:"type:jsonb"`
 	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
 }
 
+// Device is a browser or app the user chose to trust after completing MFA.
+// TokenHash is the hash of the device token held by the client; logins that
+// present it before TrustedUntil skip the MFA challenge.
 type Device struct {
 	BaseModel
-	UserID     uuid.UUID `gorm:"type:uuid;index"`
-	Name       string    `gorm:"size:50"`
-	Type       string    `gorm:"size:20"`
-	LastUsedAt time.Time
+	UserID       uuid.UUID `gorm:"type:uuid;index"`
+	Name         string    `gorm:"size:50"`
+	Type         string    `gorm:"size:20"`
+	TokenHash    string    `gorm:"size:64;uniqueIndex" json:"-"`
+	IP           string    `gorm:"size:45"`
+	UserAgent    string    `gorm:"size:255"`
+	TrustedUntil time.Time
+	LastUsedAt   time.Time
+}
+
+type AccessPolicyEffect string
+
+const (
+	AccessPolicyEffectAllow AccessPolicyEffect = "allow"
+	AccessPolicyEffectDeny  AccessPolicyEffect = "deny"
+)
+
+// AccessPolicy is an attribute-based rule evaluated together with role
+// permissions. Actions is a JSON array of permission patterns the policy
+// applies to and Conditions a JSON array of conditions that must all hold.
+type AccessPolicy struct {
+	BaseModel
+	TenantID    uuid.UUID          `gorm:"type:uuid;index"`
+	Name        string             `gorm:"size:100"`
+	Description string             `gorm:"size:255"`
+	Effect      AccessPolicyEffect `gorm:"size:10"`
+	Actions     string             `gorm:"type:jsonb"`
+	Conditions  string             `gorm:"type:jsonb"`
+	Enabled     bool
+}
+
+// SigningKey is an Ed25519 key of the token signing key ring. KeyID is the
+// PASERK ID of its public key and SealedPrivateKey its seed, encrypted under
+// the key ring secret. Active keys sign and verify tokens, verify-only keys
+// verify tokens until RetireAt and retired keys no longer verify anything.
+type SigningKey struct {
+	BaseModel
+	KeyID            string `gorm:"size:64;uniqueIndex"`
+	SealedPrivateKey string `gorm:"size:255" json:"-"`
+	State            string `gorm:"size:20;index"`
+	RetireAt         *time.Time
+}
+
+// OAuthClient is an application registered with a tenant to sign users in
+// through the OAuth authorization server. ClientID is the public identifier
+// the application presents and SecretHash the hash of its secret, empty for
+// public clients such as single-page and native apps, which cannot keep one.
+// RedirectURIs and Scopes are JSON arrays of the exact redirect URIs and the
+// scopes the client may request. Trusted clients skip the consent screen.
+type OAuthClient struct {
+	BaseModel
+	TenantID     uuid.UUID `gorm:"type:uuid;index"`
+	ClientID     string    `gorm:"size:64;uniqueIndex"`
+	SecretHash   string    `gorm:"size:64" json:"-"`
+	Name         string    `gorm:"size:100"`
+	RedirectURIs string    `gorm:"type:jsonb"`
+	// PostLogoutRedirectURIs are the URIs the client may ask users to be
+	// sent back to after signing out through OpenID Connect.
+	PostLogoutRedirectURIs string `gorm:"type:jsonb"`
+	Scopes                 string `gorm:"type:jsonb"`
+	Public                 bool
+	Trusted                bool
+}
+
+// OAuthScope is a scope OAuth clients of a tenant may request. Permissions
+// is a JSON array of the permission patterns tokens granted the scope carry,
+// as far as the user holds them.
+type OAuthScope struct {
+	BaseModel
+	TenantID    uuid.UUID `gorm:"type:uuid;index;uniqueIndex:idx_oauth_scopes_tenant_name"`
+	Name        string    `gorm:"size:100;uniqueIndex:idx_oauth_scopes_tenant_name"`
+	Description string    `gorm:"size:255"`
+	Permissions string    `gorm:"type:jsonb"`
+}
+
+// OAuthConsent records the scopes a user allowed a client, so the consent
+// screen is only shown again when the client asks for more.
+type OAuthConsent struct {
+	BaseModel
+	TenantID      uuid.UUID `gorm:"type:uuid;index"`
+	UserID        uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_oauth_consents_user_client"`
+	OAuthClientID uuid.UUID `gorm:"column:oauth_client_id;type:uuid;uniqueIndex:idx_oauth_consents_user_client"`
+	Scopes        string    `gorm:"size:1024"`
+}
+
+// OAuthAuthorizationCode is a single-use authorization code. CodeHash is the
+// hash of the code handed to the client and CodeChallenge the PKCE S256
+// challenge its verifier must match. Once exchanged, UsedAt is set and
+// SessionID names the session it started, which is revoked if the code is
+// presented again. ParentSessionID names the session the user approved the
+// request from.
+type OAuthAuthorizationCode struct {
+	BaseModel
+	TenantID      uuid.UUID `gorm:"type:uuid;index"`
+	OAuthClientID uuid.UUID `gorm:"column:oauth_client_id;type:uuid;index"`
+	UserID        uuid.UUID `gorm:"type:uuid;index"`
+	CodeHash      string    `gorm:"size:64;uniqueIndex" json:"-"`
+	RedirectURI   string    `gorm:"size:2048"`
+	Scopes        string    `gorm:"size:1024"`
+	CodeChallenge string    `gorm:"size:128"`
+	AuthMethod    string    `gorm:"size:20"`
+	// Nonce is the OpenID Connect nonce, echoed in the ID token.
+	Nonce           string     `gorm:"size:255"`
+	ParentSessionID *uuid.UUID `gorm:"type:uuid"`
+	SessionID       *uuid.UUID `gorm:"type:uuid"`
+	ExpiresAt       time.Time
+	UsedAt          *time.Time
+}
+
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+func (OAuthScope) TableName() string {
+	return "oauth_scopes"
+}
+
+func (OAuthConsent) TableName() string {
+	return "oauth_consents"
+}
+
+func (OAuthAuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+// IdentityProvider is an external OpenID Connect provider members of a
+// tenant can sign in with. RoleMappings is a JSON array of group to role
+// mappings applied to the groups the provider reports in GroupsClaim.
+type IdentityProvider struct {
+	BaseModel
+	TenantID     uuid.UUID `gorm:"type:uuid;index"`
+	Name         string    `gorm:"size:100"`
+	Issuer       string    `gorm:"size:255"`
+	ClientID     string    `gorm:"size:255"`
+	ClientSecret string    `gorm:"size:255" json:"-"`
+	Scopes       string    `gorm:"size:1024"`
+	GroupsClaim  string    `gorm:"size:100"`
+	RoleMappings string    `gorm:"type:jsonb"`
+	Enabled      bool      `gorm:"default:true"`
+}
+
+// FederatedIdentity links a user to their account at an identity provider,
+// named by the provider's subject identifier. IdentityProviderID is the ID
+// of either an IdentityProvider or a SAMLProvider; for the latter, Subject
+// is the NameID of the assertion.
+//
+// A Pending link was requested by a sign-in whose email address matched an
+// existing user. It signs nobody in until that user confirms it while
+// signed in.
+type FederatedIdentity struct {
+	BaseModel
+	TenantID           uuid.UUID `gorm:"type:uuid;index"`
+	UserID             uuid.UUID `gorm:"type:uuid;index"`
+	IdentityProviderID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_federated_identities_provider_subject"`
+	Subject            string    `gorm:"size:255;uniqueIndex:idx_federated_identities_provider_subject"`
+	Email              string    `gorm:"size:255"`
+	Pending            bool      `gorm:"default:false"`
+	LastLoginAt        *time.Time
+}
+
+// FederatedLoginState is a pending sign-in at an identity provider. It is
+// looked up by the hash of the state parameter and consumed by the
+// callback, which checks the ID token nonce and redeems the code with the
+// PKCE CodeVerifier.
+type FederatedLoginState struct {
+	BaseModel
+	TenantID           uuid.UUID `gorm:"type:uuid;index"`
+	IdentityProviderID uuid.UUID `gorm:"type:uuid"`
+	StateHash          string    `gorm:"size:64;uniqueIndex" json:"-"`
+	Nonce              string    `gorm:"size:64"`
+	CodeVerifier       string    `gorm:"size:128" json:"-"`
+	ExpiresAt          time.Time `gorm:"index"`
+}
+
+// SAMLProvider is a SAML 2.0 identity provider members of a tenant can
+// sign in with, with the tenant as service provider. Certificate holds the
+// PEM encoded certificates assertions may be signed with. AttributeMappings
+// is a JSON object naming the assertion attributes user fields are read
+// from; RoleMappings is a JSON array of group to role mappings applied to
+// the values of GroupsAttribute. Assertions carry no claim that the email
+// address was verified, so it is only treated as verified if TrustEmail is
+// set.
+type SAMLProvider struct {
+	BaseModel
+	TenantID          uuid.UUID `gorm:"type:uuid;index"`
+	Name              string    `gorm:"size:100"`
+	EntityID          string    `gorm:"size:255"`
+	SSOURL            string    `gorm:"size:1024"`
+	Certificate       string    `gorm:"type:text"`
+	SPEntityID        string    `gorm:"size:255"`
+	AttributeMappings string    `gorm:"type:jsonb"`
+	GroupsAttribute   string    `gorm:"size:255"`
+	RoleMappings      string    `gorm:"type:jsonb"`
+	TrustEmail        bool      `gorm:"default:false"`
+	Enabled           bool      `gorm:"default:true"`
+}
+
+// SAMLRequest is an authentication request sent to a SAML provider. The
+// response to it must name RequestID in InResponseTo and is accepted once.
+type SAMLRequest struct {
+	BaseModel
+	TenantID       uuid.UUID `gorm:"type:uuid;index"`
+	SAMLProviderID uuid.UUID `gorm:"type:uuid"`
+	RequestID      string    `gorm:"size:64;uniqueIndex"`
+	ExpiresAt      time.Time `gorm:"index"`
 }
@@ -0,0 +1,67 @@
+package user_management
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type AccessPolicyRepository interface {
+	Create(ctx context.Context, policy *models.AccessPolicy) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.AccessPolicy, error)
+	FindAll(ctx context.Context) ([]*models.AccessPolicy, error)
+	FindEnabled(ctx context.Context) ([]*models.AccessPolicy, error)
+	Update(ctx context.Context, policy *models.AccessPolicy) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type accessPolicyRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessPolicyRepository(db *gorm.DB) AccessPolicyRepository {
+	return &accessPolicyRepository{db: db}
+}
+
+func (r *accessPolicyRepository) Create(ctx context.Context, policy *models.AccessPolicy) error {
+	return r.db.WithContext(ctx).Create(policy).Error
+}
+
+func (r *accessPolicyRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.AccessPolicy, error) {
+	var policy models.AccessPolicy
+	err := r.db.WithContext(ctx).First(&policy, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *accessPolicyRepository) FindAll(ctx context.Context) ([]*models.AccessPolicy, error) {
+	var policies []*models.AccessPolicy
+	err := r.db.WithContext(ctx).Order("name").Find(&policies).Error
+	return policies, err
+}
+
+func (r *accessPolicyRepository) FindEnabled(ctx context.Context) ([]*models.AccessPolicy, error) {
+	var policies []*models.AccessPolicy
+	err := r.db.WithContext(ctx).Where("enabled = ?", true).Order("name").Find(&policies).Error
+	return policies, err
+}
+
+func (r *accessPolicyRepository) Update(ctx context.Context, policy *models.AccessPolicy) error {
+	return r.db.WithContext(ctx).Save(policy).Error
+}
+
+func (r *accessPolicyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&models.AccessPolicy{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
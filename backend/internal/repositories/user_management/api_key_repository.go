@@ -0,0 +1,72 @@
+package user_management
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, apiKey *models.APIKey) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.APIKey, error)
+	FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error)
+	Update(ctx context.Context, apiKey *models.APIKey) error
+	UpdateLastUsedAt(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, apiKey *models.APIKey) error {
+	return r.db.WithContext(ctx).Create(apiKey).Error
+}
+
+func (r *apiKeyRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	err := r.db.WithContext(ctx).First(&apiKey, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+func (r *apiKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	err := r.db.WithContext(ctx).First(&apiKey, "prefix = ?", prefix).Error
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+func (r *apiKeyRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	var apiKeys []*models.APIKey
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&apiKeys).Error
+	return apiKeys, err
+}
+
+func (r *apiKeyRepository) Update(ctx context.Context, apiKey *models.APIKey) error {
+	return r.db.WithContext(ctx).Save(apiKey).Error
+}
+
+// UpdateLastUsedAt writes only last_used_at, so authenticating with a key
+// cannot overwrite a concurrent revocation or scope change with the stale
+// copy it loaded.
+func (r *apiKeyRepository) UpdateLastUsedAt(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", lastUsedAt).Error
+}
+
+func (r *apiKeyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.APIKey{}, "id = ?", id).Error
+}
@@ -0,0 +1,68 @@
+package user_management
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type AuditLogFilter struct {
+	UserID   *uuid.UUID
+	Action   string
+	Resource string
+	From     *time.Time
+	To       *time.Time
+	// BeforeCreatedAt and BeforeID form a keyset cursor: only entries older
+	// than that position are returned.
+	BeforeCreatedAt *time.Time
+	BeforeID        uuid.UUID
+	Limit           int
+}
+
+type AuditLogRepository interface {
+	Create(ctx context.Context, log *models.AuditLog) error
+	Find(ctx context.Context, filter AuditLogFilter) ([]*models.AuditLog, error)
+}
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Create(ctx context.Context, log *models.AuditLog) error {
+	return r.db.WithContext(ctx).Create(log).Error
+}
+
+func (r *auditLogRepository) Find(ctx context.Context, filter AuditLogFilter) ([]*models.AuditLog, error) {
+	query := r.db.WithContext(ctx)
+
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Resource != "" {
+		query = query.Where("resource = ?", filter.Resource)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.BeforeCreatedAt != nil {
+		query = query.Where("(created_at, id) < (?, ?)", *filter.BeforeCreatedAt, filter.BeforeID)
+	}
+
+	var logs []*models.AuditLog
+	err := query.Order("created_at DESC, id DESC").Limit(filter.Limit).Find(&logs).Error
+	return logs, err
+}
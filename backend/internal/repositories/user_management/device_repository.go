@@ -0,0 +1,69 @@
+package user_management
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type DeviceRepository interface {
+	Create(ctx context.Context, device *models.Device) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Device, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*models.Device, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Device, error)
+	Update(ctx context.Context, device *models.Device) error
+	UpdateLastUsedAt(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type deviceRepository struct {
+	db *gorm.DB
+}
+
+func NewDeviceRepository(db *gorm.DB) DeviceRepository {
+	return &deviceRepository{db: db}
+}
+
+func (r *deviceRepository) Create(ctx context.Context, device *models.Device) error {
+	return r.db.WithContext(ctx).Create(device).Error
+}
+
+func (r *deviceRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Device, error) {
+	var device models.Device
+	err := r.db.WithContext(ctx).First(&device, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *deviceRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*models.Device, error) {
+	var device models.Device
+	err := r.db.WithContext(ctx).First(&device, "token_hash = ?", tokenHash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *deviceRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Device, error) {
+	var devices []*models.Device
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("last_used_at DESC").Find(&devices).Error
+	return devices, err
+}
+
+func (r *deviceRepository) Update(ctx context.Context, device *models.Device) error {
+	return r.db.WithContext(ctx).Save(device).Error
+}
+
+func (r *deviceRepository) UpdateLastUsedAt(ctx context.Context, id uuid.UUID, lastUsedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.Device{}).Where("id = ?", id).Update("last_used_at", lastUsedAt).Error
+}
+
+func (r *deviceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Device{}, "id = ?", id).Error
+}
@@ -0,0 +1,72 @@
+package user_management
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type FederatedIdentityRepository interface {
+	Create(ctx context.Context, identity *models.FederatedIdentity) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.FederatedIdentity, error)
+	FindBySubject(ctx context.Context, providerID uuid.UUID, subject string) (*models.FederatedIdentity, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.FederatedIdentity, error)
+	Update(ctx context.Context, identity *models.FederatedIdentity) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByProviderID(ctx context.Context, providerID uuid.UUID) error
+}
+
+type federatedIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewFederatedIdentityRepository(db *gorm.DB) FederatedIdentityRepository {
+	return &federatedIdentityRepository{db: db}
+}
+
+func (r *federatedIdentityRepository) Create(ctx context.Context, identity *models.FederatedIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+func (r *federatedIdentityRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.FederatedIdentity, error) {
+	var identity models.FederatedIdentity
+	err := r.db.WithContext(ctx).First(&identity, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *federatedIdentityRepository) FindBySubject(ctx context.Context, providerID uuid.UUID, subject string) (*models.FederatedIdentity, error) {
+	var identity models.FederatedIdentity
+	err := r.db.WithContext(ctx).First(&identity, "identity_provider_id = ? AND subject = ?", providerID, subject).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *federatedIdentityRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.FederatedIdentity, error) {
+	var identities []*models.FederatedIdentity
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&identities).Error
+	return identities, err
+}
+
+func (r *federatedIdentityRepository) Update(ctx context.Context, identity *models.FederatedIdentity) error {
+	return r.db.WithContext(ctx).Save(identity).Error
+}
+
+// Delete removes a link for good, so the provider account can be linked
+// again.
+func (r *federatedIdentityRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(&models.FederatedIdentity{}, "id = ?", id).Error
+}
+
+// DeleteByProviderID removes the links to a provider for good, so users
+// can be linked again if the provider is added back.
+func (r *federatedIdentityRepository) DeleteByProviderID(ctx context.Context, providerID uuid.UUID) error {
+	return r.db.WithContext(ctx).Unscoped().Where("identity_provider_id = ?", providerID).Delete(&models.FederatedIdentity{}).Error
+}
@@ -0,0 +1,51 @@
+package user_management
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type FederatedLoginStateRepository interface {
+	Create(ctx context.Context, state *models.FederatedLoginState) error
+	Consume(ctx context.Context, stateHash string) (*models.FederatedLoginState, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+type federatedLoginStateRepository struct {
+	db *gorm.DB
+}
+
+func NewFederatedLoginStateRepository(db *gorm.DB) FederatedLoginStateRepository {
+	return &federatedLoginStateRepository{db: db}
+}
+
+func (r *federatedLoginStateRepository) Create(ctx context.Context, state *models.FederatedLoginState) error {
+	return r.db.WithContext(ctx).Create(state).Error
+}
+
+// Consume deletes the state with the given hash and returns it. Only one
+// of several concurrent callers gets the state; the others get
+// gorm.ErrRecordNotFound.
+func (r *federatedLoginStateRepository) Consume(ctx context.Context, stateHash string) (*models.FederatedLoginState, error) {
+	var state models.FederatedLoginState
+	if err := r.db.WithContext(ctx).First(&state, "state_hash = ?", stateHash).Error; err != nil {
+		return nil, err
+	}
+
+	result := r.db.WithContext(ctx).Unscoped().Delete(&models.FederatedLoginState{}, "id = ?", state.ID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected != 1 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &state, nil
+}
+
+func (r *federatedLoginStateRepository) DeleteExpired(ctx context.Context) error {
+	return r.db.WithContext(ctx).Unscoped().Where("expires_at < ?", time.Now()).Delete(&models.FederatedLoginState{}).Error
+}
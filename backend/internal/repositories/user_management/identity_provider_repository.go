@@ -0,0 +1,53 @@
+package user_management
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type IdentityProviderRepository interface {
+	Create(ctx context.Context, provider *models.IdentityProvider) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.IdentityProvider, error)
+	FindAll(ctx context.Context) ([]*models.IdentityProvider, error)
+	Update(ctx context.Context, provider *models.IdentityProvider) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type identityProviderRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityProviderRepository(db *gorm.DB) IdentityProviderRepository {
+	return &identityProviderRepository{db: db}
+}
+
+func (r *identityProviderRepository) Create(ctx context.Context, provider *models.IdentityProvider) error {
+	return r.db.WithContext(ctx).Create(provider).Error
+}
+
+func (r *identityProviderRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.IdentityProvider, error) {
+	var provider models.IdentityProvider
+	err := r.db.WithContext(ctx).First(&provider, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &provider, nil
+}
+
+func (r *identityProviderRepository) FindAll(ctx context.Context) ([]*models.IdentityProvider, error) {
+	var providers []*models.IdentityProvider
+	err := r.db.WithContext(ctx).Order("name").Find(&providers).Error
+	return providers, err
+}
+
+func (r *identityProviderRepository) Update(ctx context.Context, provider *models.IdentityProvider) error {
+	return r.db.WithContext(ctx).Save(provider).Error
+}
+
+func (r *identityProviderRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.IdentityProvider{}, "id = ?", id).Error
+}
@@ -0,0 +1,37 @@
+package user_management
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, attempt *models.LoginAttempt) error
+	CountFailuresByIP(ctx context.Context, ip string, since time.Time) (int64, error)
+}
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+func (r *loginAttemptRepository) Create(ctx context.Context, attempt *models.LoginAttempt) error {
+	return r.db.WithContext(ctx).Create(attempt).Error
+}
+
+// CountFailuresByIP counts the failed attempts from ip since the given time
+// in the tenant carried by ctx.
+func (r *loginAttemptRepository) CountFailuresByIP(ctx context.Context, ip string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.LoginAttempt{}).
+		Where("ip = ? AND success = ? AND created_at > ?", ip, false, since).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,60 @@
+package user_management
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type OAuthAuthorizationCodeRepository interface {
+	Create(ctx context.Context, code *models.OAuthAuthorizationCode) error
+	FindByCodeHash(ctx context.Context, codeHash string) (*models.OAuthAuthorizationCode, error)
+	MarkUsed(ctx context.Context, code *models.OAuthAuthorizationCode) (bool, error)
+	SetSessionID(ctx context.Context, id uuid.UUID, sessionID uuid.UUID) error
+	DeleteExpired(ctx context.Context) error
+}
+
+type oauthAuthorizationCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthAuthorizationCodeRepository(db *gorm.DB) OAuthAuthorizationCodeRepository {
+	return &oauthAuthorizationCodeRepository{db: db}
+}
+
+func (r *oauthAuthorizationCodeRepository) Create(ctx context.Context, code *models.OAuthAuthorizationCode) error {
+	return r.db.WithContext(ctx).Create(code).Error
+}
+
+func (r *oauthAuthorizationCodeRepository) FindByCodeHash(ctx context.Context, codeHash string) (*models.OAuthAuthorizationCode, error) {
+	var code models.OAuthAuthorizationCode
+	err := r.db.WithContext(ctx).First(&code, "code_hash = ?", codeHash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// MarkUsed marks code as exchanged. It reports false if the code had
+// already been used, so concurrent exchanges of one code cannot both
+// succeed.
+func (r *oauthAuthorizationCodeRepository) MarkUsed(ctx context.Context, code *models.OAuthAuthorizationCode) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.OAuthAuthorizationCode{}).
+		Where("id = ? AND used_at IS NULL", code.ID).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *oauthAuthorizationCodeRepository) SetSessionID(ctx context.Context, id uuid.UUID, sessionID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.OAuthAuthorizationCode{}).
+		Where("id = ?", id).
+		Update("session_id", sessionID).Error
+}
+
+func (r *oauthAuthorizationCodeRepository) DeleteExpired(ctx context.Context) error {
+	return r.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&models.OAuthAuthorizationCode{}).Error
+}
@@ -0,0 +1,63 @@
+package user_management
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type OAuthClientRepository interface {
+	Create(ctx context.Context, client *models.OAuthClient) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.OAuthClient, error)
+	FindByClientID(ctx context.Context, clientID string) (*models.OAuthClient, error)
+	FindAll(ctx context.Context) ([]*models.OAuthClient, error)
+	Update(ctx context.Context, client *models.OAuthClient) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type oauthClientRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthClientRepository(db *gorm.DB) OAuthClientRepository {
+	return &oauthClientRepository{db: db}
+}
+
+func (r *oauthClientRepository) Create(ctx context.Context, client *models.OAuthClient) error {
+	return r.db.WithContext(ctx).Create(client).Error
+}
+
+func (r *oauthClientRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	err := r.db.WithContext(ctx).First(&client, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (r *oauthClientRepository) FindByClientID(ctx context.Context, clientID string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	err := r.db.WithContext(ctx).First(&client, "client_id = ?", clientID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (r *oauthClientRepository) FindAll(ctx context.Context) ([]*models.OAuthClient, error) {
+	var clients []*models.OAuthClient
+	err := r.db.WithContext(ctx).Order("name").Find(&clients).Error
+	return clients, err
+}
+
+func (r *oauthClientRepository) Update(ctx context.Context, client *models.OAuthClient) error {
+	return r.db.WithContext(ctx).Save(client).Error
+}
+
+func (r *oauthClientRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.OAuthClient{}, "id = ?", id).Error
+}
@@ -0,0 +1,47 @@
+package user_management
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type OAuthConsentRepository interface {
+	Find(ctx context.Context, userID, clientID uuid.UUID) (*models.OAuthConsent, error)
+	Save(ctx context.Context, consent *models.OAuthConsent) error
+	DeleteByClientID(ctx context.Context, clientID uuid.UUID) error
+}
+
+type oauthConsentRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthConsentRepository(db *gorm.DB) OAuthConsentRepository {
+	return &oauthConsentRepository{db: db}
+}
+
+func (r *oauthConsentRepository) Find(ctx context.Context, userID, clientID uuid.UUID) (*models.OAuthConsent, error) {
+	var consent models.OAuthConsent
+	err := r.db.WithContext(ctx).First(&consent, "user_id = ? AND oauth_client_id = ?", userID, clientID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// Save records consent, replacing the scopes of an earlier consent of the
+// same user to the same client.
+func (r *oauthConsentRepository) Save(ctx context.Context, consent *models.OAuthConsent) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "oauth_client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"scopes", "updated_at"}),
+	}).Create(consent).Error
+}
+
+func (r *oauthConsentRepository) DeleteByClientID(ctx context.Context, clientID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("oauth_client_id = ?", clientID).Delete(&models.OAuthConsent{}).Error
+}
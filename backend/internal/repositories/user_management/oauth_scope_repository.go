@@ -0,0 +1,73 @@
+package user_management
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type OAuthScopeRepository interface {
+	Create(ctx context.Context, scope *models.OAuthScope) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.OAuthScope, error)
+	FindByName(ctx context.Context, name string) (*models.OAuthScope, error)
+	FindByNames(ctx context.Context, names []string) ([]*models.OAuthScope, error)
+	FindAll(ctx context.Context) ([]*models.OAuthScope, error)
+	Update(ctx context.Context, scope *models.OAuthScope) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type oauthScopeRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthScopeRepository(db *gorm.DB) OAuthScopeRepository {
+	return &oauthScopeRepository{db: db}
+}
+
+func (r *oauthScopeRepository) Create(ctx context.Context, scope *models.OAuthScope) error {
+	return r.db.WithContext(ctx).Create(scope).Error
+}
+
+func (r *oauthScopeRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.OAuthScope, error) {
+	var scope models.OAuthScope
+	err := r.db.WithContext(ctx).First(&scope, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &scope, nil
+}
+
+func (r *oauthScopeRepository) FindByName(ctx context.Context, name string) (*models.OAuthScope, error) {
+	var scope models.OAuthScope
+	err := r.db.WithContext(ctx).First(&scope, "name = ?", name).Error
+	if err != nil {
+		return nil, err
+	}
+	return &scope, nil
+}
+
+func (r *oauthScopeRepository) FindByNames(ctx context.Context, names []string) ([]*models.OAuthScope, error) {
+	var scopes []*models.OAuthScope
+	if len(names) == 0 {
+		return scopes, nil
+	}
+	err := r.db.WithContext(ctx).Where("name IN ?", names).Order("name").Find(&scopes).Error
+	return scopes, err
+}
+
+func (r *oauthScopeRepository) FindAll(ctx context.Context) ([]*models.OAuthScope, error) {
+	var scopes []*models.OAuthScope
+	err := r.db.WithContext(ctx).Order("name").Find(&scopes).Error
+	return scopes, err
+}
+
+func (r *oauthScopeRepository) Update(ctx context.Context, scope *models.OAuthScope) error {
+	return r.db.WithContext(ctx).Save(scope).Error
+}
+
+func (r *oauthScopeRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.OAuthScope{}, "id = ?", id).Error
+}
@@ -0,0 +1,49 @@
+package user_management
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, reset *models.PasswordReset) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordReset, error)
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+func (r *passwordResetRepository) Create(ctx context.Context, reset *models.PasswordReset) error {
+	return r.db.WithContext(ctx).Create(reset).Error
+}
+
+func (r *passwordResetRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordReset, error) {
+	var reset models.PasswordReset
+	err := r.db.WithContext(ctx).Preload("Token").
+		Joins("JOIN tokens ON tokens.id = password_resets.token_id AND tokens.deleted_at IS NULL").
+		Where("tokens.token_hash = ? AND tokens.type = ?", tokenHash, models.TokenTypePasswordReset).
+		First(&reset).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reset, nil
+}
+
+func (r *passwordResetRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tokenIDs := tx.Model(&models.PasswordReset{}).Select("token_id").Where("user_id = ?", userID)
+		if err := tx.Where("id IN (?)", tokenIDs).Delete(&models.Token{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.PasswordReset{}).Error
+	})
+}
@@ -1,6 +1,7 @@
 package user_management
 
 import (
+	"context"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
@@ -8,11 +9,12 @@ import (
 )
 
 type PermissionRepository interface {
-	Create(permission *models.Permission) error
-	FindByID(id uuid.UUID) (*models.Permission, error)
-	FindAll() ([]*models.Permission, error)
-	Update(permission *models.Permission) error
-	Delete(id uuid.UUID) error
+	Create(ctx context.Context, permission *models.Permission) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Permission, error)
+	FindByName(ctx context.Context, name string) (*models.Permission, error)
+	FindAll(ctx context.Context) ([]*models.Permission, error)
+	Update(ctx context.Context, permission *models.Permission) error
+	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 type permissionRepository struct {
@@ -23,29 +25,51 @@ func NewPermissionRepository(db *gorm.DB) PermissionRepository {
 	return &permissionRepository{db: db}
 }
 
-func (r *permissionRepository) Create(permission *models.Permission) error {
-	return r.db.Create(permission).Error
+func (r *permissionRepository) Create(ctx context.Context, permission *models.Permission) error {
+	return r.db.WithContext(ctx).Create(permission).Error
 }
 
-func (r *permissionRepository) FindByID(id uuid.UUID) (*models.Permission, error) {
+func (r *permissionRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Permission, error) {
 	var permission models.Permission
-	err := r.db.First(&permission, "id = ?", id).Error
+	err := r.db.WithContext(ctx).First(&permission, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &permission, nil
 }
 
-func (r *permissionRepository) FindAll() ([]*models.Permission, error) {
+func (r *permissionRepository) FindByName(ctx context.Context, name string) (*models.Permission, error) {
+	var permission models.Permission
+	err := r.db.WithContext(ctx).First(&permission, "name = ?", name).Error
+	if err != nil {
+		return nil, err
+	}
+	return &permission, nil
+}
+
+func (r *permissionRepository) FindAll(ctx context.Context) ([]*models.Permission, error) {
 	var permissions []*models.Permission
-	err := r.db.Find(&permissions).Error
+	err := r.db.WithContext(ctx).Order("name").Find(&permissions).Error
 	return permissions, err
 }
 
-func (r *permissionRepository) Update(permission *models.Permission) error {
-	return r.db.Save(permission).Error
+func (r *permissionRepository) Update(ctx context.Context, permission *models.Permission) error {
+	return r.db.WithContext(ctx).Save(permission).Error
 }
 
-func (r *permissionRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.Permission{}, "id = ?", id).Error
+// Delete removes the permission and its role assignments. The permission is
+// deleted first so that the tenant scope decides whether the assignments may
+// be touched at all.
+func (r *permissionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Permission{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Exec("DELETE FROM role_permissions WHERE permission_id = ?", id).Error
+	})
 }
@@ -1,6 +1,7 @@
 package user_management
 
 import (
+	"context"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
@@ -8,11 +9,14 @@ import (
 )
 
 type RoleRepository interface {
-	Create(role *models.Role) error
-	FindByID(id uuid.UUID) (*models.Role, error)
-	FindAll() ([]*models.Role, error)
-	Update(role *models.Role) error
-	Delete(id uuid.UUID) error
+	Create(ctx context.Context, role *models.Role) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Role, error)
+	FindByName(ctx context.Context, name string) (*models.Role, error)
+	FindAll(ctx context.Context) ([]*models.Role, error)
+	Update(ctx context.Context, role *models.Role) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	AddPermission(ctx context.Context, role *models.Role, permission *models.Permission) error
+	RemovePermission(ctx context.Context, role *models.Role, permission *models.Permission) error
 }
 
 type roleRepository struct {
@@ -23,29 +27,65 @@ func NewRoleRepository(db *gorm.DB) RoleRepository {
 	return &roleRepository{db: db}
 }
 
-func (r *roleRepository) Create(role *models.Role) error {
-	return r.db.Create(role).Error
+func (r *roleRepository) Create(ctx context.Context, role *models.Role) error {
+	return r.db.WithContext(ctx).Create(role).Error
 }
 
-func (r *roleRepository) FindByID(id uuid.UUID) (*models.Role, error) {
+func (r *roleRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
 	var role models.Role
-	err := r.db.Preload("Permissions").First(&role, "id = ?", id).Error
+	err := r.db.WithContext(ctx).Preload("Permissions").First(&role, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &role, nil
 }
 
-func (r *roleRepository) FindAll() ([]*models.Role, error) {
+func (r *roleRepository) FindByName(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").First(&role, "name = ?", name).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) FindAll(ctx context.Context) ([]*models.Role, error) {
 	var roles []*models.Role
-	err := r.db.Preload("Permissions").Find(&roles).Error
+	err := r.db.WithContext(ctx).Preload("Permissions").Order("name").Find(&roles).Error
 	return roles, err
 }
 
-func (r *roleRepository) Update(role *models.Role) error {
-	return r.db.Save(role).Error
+func (r *roleRepository) Update(ctx context.Context, role *models.Role) error {
+	return r.db.WithContext(ctx).Save(role).Error
+}
+
+// Delete removes the role together with its user and permission
+// assignments. The role is deleted first so that the tenant scope decides
+// whether the assignments may be touched at all.
+func (r *roleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Role{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Exec("DELETE FROM user_roles WHERE role_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE roles SET parent_id = NULL WHERE parent_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM role_permissions WHERE role_id = ?", id).Error
+	})
+}
+
+func (r *roleRepository) AddPermission(ctx context.Context, role *models.Role, permission *models.Permission) error {
+	return r.db.WithContext(ctx).Model(role).Association("Permissions").Append(permission)
 }
 
-func (r *roleRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.Role{}, "id = ?", id).Error
+func (r *roleRepository) RemovePermission(ctx context.Context, role *models.Role, permission *models.Permission) error {
+	return r.db.WithContext(ctx).Model(role).Association("Permissions").Delete(permission)
 }
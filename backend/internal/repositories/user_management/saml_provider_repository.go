@@ -0,0 +1,53 @@
+package user_management
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type SAMLProviderRepository interface {
+	Create(ctx context.Context, provider *models.SAMLProvider) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.SAMLProvider, error)
+	FindAll(ctx context.Context) ([]*models.SAMLProvider, error)
+	Update(ctx context.Context, provider *models.SAMLProvider) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type samlProviderRepository struct {
+	db *gorm.DB
+}
+
+func NewSAMLProviderRepository(db *gorm.DB) SAMLProviderRepository {
+	return &samlProviderRepository{db: db}
+}
+
+func (r *samlProviderRepository) Create(ctx context.Context, provider *models.SAMLProvider) error {
+	return r.db.WithContext(ctx).Create(provider).Error
+}
+
+func (r *samlProviderRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.SAMLProvider, error) {
+	var provider models.SAMLProvider
+	err := r.db.WithContext(ctx).First(&provider, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &provider, nil
+}
+
+func (r *samlProviderRepository) FindAll(ctx context.Context) ([]*models.SAMLProvider, error) {
+	var providers []*models.SAMLProvider
+	err := r.db.WithContext(ctx).Order("name").Find(&providers).Error
+	return providers, err
+}
+
+func (r *samlProviderRepository) Update(ctx context.Context, provider *models.SAMLProvider) error {
+	return r.db.WithContext(ctx).Save(provider).Error
+}
+
+func (r *samlProviderRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.SAMLProvider{}, "id = ?", id).Error
+}
@@ -0,0 +1,51 @@
+package user_management
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type SAMLRequestRepository interface {
+	Create(ctx context.Context, request *models.SAMLRequest) error
+	Consume(ctx context.Context, requestID string) (*models.SAMLRequest, error)
+	DeleteExpired(ctx context.Context) error
+}
+
+type samlRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewSAMLRequestRepository(db *gorm.DB) SAMLRequestRepository {
+	return &samlRequestRepository{db: db}
+}
+
+func (r *samlRequestRepository) Create(ctx context.Context, request *models.SAMLRequest) error {
+	return r.db.WithContext(ctx).Create(request).Error
+}
+
+// Consume deletes the request with the given request ID and returns it.
+// Only one of several concurrent callers gets the request; the others get
+// gorm.ErrRecordNotFound.
+func (r *samlRequestRepository) Consume(ctx context.Context, requestID string) (*models.SAMLRequest, error) {
+	var request models.SAMLRequest
+	if err := r.db.WithContext(ctx).First(&request, "request_id = ?", requestID).Error; err != nil {
+		return nil, err
+	}
+
+	result := r.db.WithContext(ctx).Unscoped().Delete(&models.SAMLRequest{}, "id = ?", request.ID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected != 1 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &request, nil
+}
+
+func (r *samlRequestRepository) DeleteExpired(ctx context.Context) error {
+	return r.db.WithContext(ctx).Unscoped().Where("expires_at < ?", time.Now()).Delete(&models.SAMLRequest{}).Error
+}
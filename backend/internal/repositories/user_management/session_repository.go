@@ -0,0 +1,83 @@
+package user_management
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Session, error)
+	FindActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error)
+	FindByOAuthClientID(ctx context.Context, oauthClientID uuid.UUID) ([]*models.Session, error)
+	FindByDeviceID(ctx context.Context, deviceID uuid.UUID) ([]*models.Session, error)
+	Update(ctx context.Context, session *models.Session) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByUserID(ctx context.Context, userID uuid.UUID, except *uuid.UUID) (int64, error)
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, session *models.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *sessionRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	var session models.Session
+	err := r.db.WithContext(ctx).First(&session, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepository) FindActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Session, error) {
+	var sessions []*models.Session
+	err := r.db.WithContext(ctx).Preload("Device").
+		Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *sessionRepository) FindByOAuthClientID(ctx context.Context, oauthClientID uuid.UUID) ([]*models.Session, error) {
+	var sessions []*models.Session
+	err := r.db.WithContext(ctx).Where("oauth_client_id = ?", oauthClientID).Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *sessionRepository) FindByDeviceID(ctx context.Context, deviceID uuid.UUID) ([]*models.Session, error) {
+	var sessions []*models.Session
+	err := r.db.WithContext(ctx).Where("device_id = ?", deviceID).Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *sessionRepository) Update(ctx context.Context, session *models.Session) error {
+	return r.db.WithContext(ctx).Save(session).Error
+}
+
+func (r *sessionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Session{}, "id = ?", id).Error
+}
+
+// DeleteByUserID removes every session of the user except the one with ID
+// except, if given, and returns how many were removed.
+func (r *sessionRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID, except *uuid.UUID) (int64, error) {
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if except != nil {
+		query = query.Where("id <> ?", *except)
+	}
+	result := query.Delete(&models.Session{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,47 @@
+package user_management
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type SigningKeyRepository interface {
+	FindAll(ctx context.Context) ([]*models.SigningKey, error)
+	Save(ctx context.Context, added *models.SigningKey, changed []*models.SigningKey) error
+}
+
+type signingKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyRepository(db *gorm.DB) SigningKeyRepository {
+	return &signingKeyRepository{db: db}
+}
+
+func (r *signingKeyRepository) FindAll(ctx context.Context) ([]*models.SigningKey, error) {
+	var keys []*models.SigningKey
+	err := r.db.WithContext(ctx).Order("created_at").Find(&keys).Error
+	return keys, err
+}
+
+// Save creates added, if given, and updates the state of the changed keys,
+// matched by KeyID, in one transaction.
+func (r *signingKeyRepository) Save(ctx context.Context, added *models.SigningKey, changed []*models.SigningKey) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, key := range changed {
+			err := tx.Model(&models.SigningKey{}).
+				Where("key_id = ?", key.KeyID).
+				Updates(map[string]interface{}{"state": key.State, "retire_at": key.RetireAt}).Error
+			if err != nil {
+				return err
+			}
+		}
+		if added == nil {
+			return nil
+		}
+		return tx.Create(added).Error
+	})
+}
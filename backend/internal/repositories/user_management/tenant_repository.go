@@ -0,0 +1,62 @@
+package user_management
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+type TenantRepository interface {
+	Create(ctx context.Context, tenant *models.Tenant) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Tenant, error)
+	FindByDomain(ctx context.Context, domain string) (*models.Tenant, error)
+	FindAll(ctx context.Context) ([]*models.Tenant, error)
+	Update(ctx context.Context, tenant *models.Tenant) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type tenantRepository struct {
+	db *gorm.DB
+}
+
+func NewTenantRepository(db *gorm.DB) TenantRepository {
+	return &tenantRepository{db: db}
+}
+
+func (r *tenantRepository) Create(ctx context.Context, tenant *models.Tenant) error {
+	return r.db.WithContext(ctx).Create(tenant).Error
+}
+
+func (r *tenantRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Tenant, error) {
+	var tenant models.Tenant
+	err := r.db.WithContext(ctx).First(&tenant, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+func (r *tenantRepository) FindByDomain(ctx context.Context, domain string) (*models.Tenant, error) {
+	var tenant models.Tenant
+	err := r.db.WithContext(ctx).First(&tenant, "domain = ?", domain).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+func (r *tenantRepository) FindAll(ctx context.Context) ([]*models.Tenant, error) {
+	var tenants []*models.Tenant
+	err := r.db.WithContext(ctx).Order("name").Find(&tenants).Error
+	return tenants, err
+}
+
+func (r *tenantRepository) Update(ctx context.Context, tenant *models.Tenant) error {
+	return r.db.WithContext(ctx).Save(tenant).Error
+}
+
+func (r *tenantRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Tenant{}, "id = ?", id).Error
+}
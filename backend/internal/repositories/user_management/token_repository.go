@@ -1,6 +1,7 @@
 package user_management
 
 import (
+	"context"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,11 +11,16 @@ import (
 )
 
 type TokenRepository interface {
-	Create(token *models.Token) error
-	FindByToken(token string) (*models.Token, error)
-	FindByUserID(userID uuid.UUID) ([]*models.Token, error)
-	Delete(id uuid.UUID) error
-	DeleteExpired() error
+	Create(ctx context.Context, token *models.Token) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*models.Token, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Token, error)
+	Rotate(ctx context.Context, token *models.Token, next *models.Token) (bool, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByUserID(ctx context.Context, userID uuid.UUID, tokenType models.TokenType) error
+	DeleteByDeviceID(ctx context.Context, deviceID uuid.UUID) error
+	DeleteBySessionID(ctx context.Context, sessionID uuid.UUID) error
+	DeleteByUserIDExceptSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error
+	DeleteExpired(ctx context.Context) error
 }
 
 type tokenRepository struct {
@@ -25,29 +31,73 @@ func NewTokenRepository(db *gorm.DB) TokenRepository {
 	return &tokenRepository{db: db}
 }
 
-func (r *tokenRepository) Create(token *models.Token) error {
-	return r.db.Create(token).Error
+func (r *tokenRepository) Create(ctx context.Context, token *models.Token) error {
+	return r.db.WithContext(ctx).Create(token).Error
 }
 
-func (r *tokenRepository) FindByToken(token string) (*models.Token, error) {
+func (r *tokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*models.Token, error) {
 	var t models.Token
-	err := r.db.Where("token = ?", token).First(&t).Error
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&t).Error
 	if err != nil {
 		return nil, err
 	}
 	return &t, nil
 }
 
-func (r *tokenRepository) FindByUserID(userID uuid.UUID) ([]*models.Token, error) {
+func (r *tokenRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Token, error) {
 	var tokens []*models.Token
-	err := r.db.Where("user_id = ?", userID).Find(&tokens).Error
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&tokens).Error
 	return tokens, err
 }
 
-func (r *tokenRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.Token{}, "id = ?", id).Error
+// Rotate marks token as rotated into next and creates next in the same
+// transaction. It reports false, creating nothing, if token had already
+// been rotated, so concurrent rotations of one token cannot both succeed.
+func (r *tokenRepository) Rotate(ctx context.Context, token *models.Token, next *models.Token) (bool, error) {
+	rotated := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Token{}).
+			Where("id = ? AND rotated_at IS NULL", token.ID).
+			Updates(map[string]interface{}{"rotated_at": time.Now(), "session_id": next.SessionID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
 }
 
-func (r *tokenRepository) DeleteExpired() error {
-	return r.db.Where("expires_at < ?", time.Now()).Delete(&models.Token{}).Error
+func (r *tokenRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Token{}, "id = ?", id).Error
+}
+
+func (r *tokenRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID, tokenType models.TokenType) error {
+	return r.db.WithContext(ctx).Where("user_id = ? AND type = ?", userID, tokenType).Delete(&models.Token{}).Error
+}
+
+func (r *tokenRepository) DeleteByDeviceID(ctx context.Context, deviceID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("device_id = ?", deviceID).Delete(&models.Token{}).Error
+}
+
+func (r *tokenRepository) DeleteBySessionID(ctx context.Context, sessionID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("session_id = ?", sessionID).Delete(&models.Token{}).Error
+}
+
+// DeleteByUserIDExceptSession removes the user's refresh tokens other than
+// those of the given session, including tokens that belong to no session.
+func (r *tokenRepository) DeleteByUserIDExceptSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND type = ? AND (session_id IS NULL OR session_id <> ?)", userID, models.TokenTypeRefresh, sessionID).
+		Delete(&models.Token{}).Error
+}
+
+func (r *tokenRepository) DeleteExpired(ctx context.Context) error {
+	return r.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&models.Token{}).Error
 }
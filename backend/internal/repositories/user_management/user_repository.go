@@ -1,18 +1,30 @@
 package user_management
 
 import (
+	"context"
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/josy-coder/adminsuite/internal/models"
 )
 
 type UserRepository interface {
-	Create(user *models.User) error
-	FindByID(id uuid.UUID) (*models.User, error)
-	FindByEmail(email string) (*models.User, error)
-	Update(user *models.User) error
-	Delete(id uuid.UUID) error
+	Create(ctx context.Context, user *models.User) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	FindByUsername(ctx context.Context, username string) (*models.User, error)
+	Update(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	AddRole(ctx context.Context, user *models.User, role *models.Role) error
+	RemoveRole(ctx context.Context, user *models.User, role *models.Role) error
+	FindSecurityStamp(ctx context.Context, id uuid.UUID) (string, error)
+	UpdateSecurityStamp(ctx context.Context, id uuid.UUID, stamp string) error
+	UpdateSecurityStampsByTenant(ctx context.Context, tenantID uuid.UUID, stamp string) error
+	IncrementFailedLoginCount(ctx context.Context, id uuid.UUID) (int, error)
+	UpdateLockedUntil(ctx context.Context, id uuid.UUID, lockedUntil time.Time) error
 }
 
 type userRepository struct {
@@ -23,32 +35,90 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) Create(user *models.User) error {
-	return r.db.Create(user).Error
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Preload("Roles.Permissions").First(&user, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
 }
 
-func (r *userRepository) FindByID(id uuid.UUID) (*models.User, error) {
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	err := r.db.Preload("Roles.Permissions").First(&user, "id = ?", id).Error
+	err := r.db.WithContext(ctx).Preload("Roles.Permissions").First(&user, "email = ?", email).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) FindByEmail(email string) (*models.User, error) {
+func (r *userRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
 	var user models.User
-	err := r.db.Preload("Roles.Permissions").First(&user, "email = ?", email).Error
+	err := r.db.WithContext(ctx).First(&user, "username = ?", username).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) Update(user *models.User) error {
-	return r.db.Save(user).Error
+// Update saves user. The security stamp is left out so that saving a user
+// loaded before a concurrent rotation cannot restore the old stamp.
+func (r *userRepository) Update(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Omit("security_stamp").Save(user).Error
+}
+
+func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.User{}, "id = ?", id).Error
+}
+
+func (r *userRepository) AddRole(ctx context.Context, user *models.User, role *models.Role) error {
+	return r.db.WithContext(ctx).Model(user).Association("Roles").Append(role)
+}
+
+func (r *userRepository) RemoveRole(ctx context.Context, user *models.User, role *models.Role) error {
+	return r.db.WithContext(ctx).Model(user).Association("Roles").Delete(role)
+}
+
+func (r *userRepository) FindSecurityStamp(ctx context.Context, id uuid.UUID) (string, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Select("id", "security_stamp").First(&user, "id = ?", id).Error
+	if err != nil {
+		return "", err
+	}
+	return user.SecurityStamp, nil
+}
+
+func (r *userRepository) UpdateSecurityStamp(ctx context.Context, id uuid.UUID, stamp string) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Update("security_stamp", stamp).Error
+}
+
+func (r *userRepository) UpdateSecurityStampsByTenant(ctx context.Context, tenantID uuid.UUID, stamp string) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("tenant_id = ?", tenantID).Update("security_stamp", stamp).Error
+}
+
+// IncrementFailedLoginCount adds one to the user's failure counter in the
+// database and returns the new value, so concurrent failed logins are all
+// counted.
+func (r *userRepository) IncrementFailedLoginCount(ctx context.Context, id uuid.UUID) (int, error) {
+	var user models.User
+	result := r.db.WithContext(ctx).Model(&user).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "failed_login_count"}}}).
+		Where("id = ?", id).
+		UpdateColumn("failed_login_count", gorm.Expr("failed_login_count + ?", 1))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return user.FailedLoginCount, nil
 }
 
-func (r *userRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.User{}, "id = ?", id).Error
+func (r *userRepository) UpdateLockedUntil(ctx context.Context, id uuid.UUID, lockedUntil time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).UpdateColumn("locked_until", lockedUntil).Error
 }
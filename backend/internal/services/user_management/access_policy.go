@@ -0,0 +1,668 @@
+package user_management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+var (
+	ErrInvalidAccessPolicy  = errors.New("invalid access policy")
+	ErrAccessPolicyNotFound = errors.New("access policy not found")
+	ErrInvalidUserAttribute = errors.New("user attributes must be a flat object of strings, numbers, booleans or lists of them")
+)
+
+const maxAccessPolicyNameLength = 100
+
+// Condition operators. Ordering operators compare numbers numerically and
+// anything else as strings, so "09:00" lte "17:00" works for times of day.
+const (
+	ConditionEquals      = "eq"
+	ConditionNotEquals   = "ne"
+	ConditionIn          = "in"
+	ConditionNotIn       = "not_in"
+	ConditionContains    = "contains"
+	ConditionGreater     = "gt"
+	ConditionGreaterOrEq = "gte"
+	ConditionLess        = "lt"
+	ConditionLessOrEq    = "lte"
+	ConditionCIDR        = "cidr"
+	ConditionExists      = "exists"
+)
+
+var conditionOperators = map[string]bool{
+	ConditionEquals: true, ConditionNotEquals: true, ConditionIn: true, ConditionNotIn: true,
+	ConditionContains: true, ConditionGreater: true, ConditionGreaterOrEq: true, ConditionLess: true,
+	ConditionLessOrEq: true, ConditionCIDR: true, ConditionExists: true,
+}
+
+// attributeRoots are the namespaces a condition attribute may start with.
+var attributeRoots = []string{"subject.", "resource.", "context."}
+
+// PolicyCondition compares the attribute at the dotted path Attribute, such
+// as subject.attributes.department, either with Value or with the attribute
+// at ValueFrom.
+type PolicyCondition struct {
+	Attribute string      `json:"attribute"`
+	Operator  string      `json:"operator"`
+	Value     interface{} `json:"value,omitempty"`
+	ValueFrom string      `json:"value_from,omitempty"`
+}
+
+// AccessPolicyInput holds the fields of an access policy. Updates replace
+// the whole policy.
+type AccessPolicyInput struct {
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	Effect      models.AccessPolicyEffect `json:"effect"`
+	Actions     []string                  `json:"actions"`
+	Conditions  []PolicyCondition         `json:"conditions"`
+	Enabled     bool                      `json:"enabled"`
+}
+
+// AccessRequest describes an action to authorize. Resource holds what is
+// known about the target, at least its id for routes that address one;
+// users are enriched with their stored attributes.
+type AccessRequest struct {
+	Action   string
+	Resource map[string]interface{}
+	Context  AccessContext
+}
+
+type AccessContext struct {
+	IP        string
+	Time      time.Time
+	ViaAPIKey bool
+	// MFAVerifiedAt is when the caller last passed a second factor in the
+	// current session, or nil if they have not.
+	MFAVerifiedAt *time.Time
+}
+
+// AccessDecision is the outcome of an authorization check together with
+// everything that led to it, so that it can be shown by the explain endpoint.
+type AccessDecision struct {
+	Allowed     bool                   `json:"allowed"`
+	Reason      string                 `json:"reason"`
+	RoleGranted bool                   `json:"role_granted"`
+	Policies    []PolicyEvaluation     `json:"policies"`
+	Attributes  map[string]interface{} `json:"attributes"`
+}
+
+// PolicyEvaluation records how one policy that applies to the requested
+// action was evaluated. Matched is true when all of its conditions held.
+type PolicyEvaluation struct {
+	PolicyID   uuid.UUID                 `json:"policy_id"`
+	Name       string                    `json:"name"`
+	Effect     models.AccessPolicyEffect `json:"effect"`
+	Matched    bool                      `json:"matched"`
+	Conditions []ConditionResult         `json:"conditions"`
+}
+
+type ConditionResult struct {
+	PolicyCondition
+	Actual interface{} `json:"actual"`
+	Passed bool        `json:"passed"`
+}
+
+// AccessPolicyService evaluates tenant access policies together with role
+// permissions. Decisions are deny-overrides: a matching deny policy refuses
+// the action even if a role grants it; otherwise the action is allowed if a
+// role grants it or a matching allow policy does. Allow policies cannot
+// extend API keys beyond their scopes, and super admins are never denied.
+type AccessPolicyService struct {
+	accessPolicyRepo     user_management.AccessPolicyRepository
+	userRepo             user_management.UserRepository
+	authorizationService *AuthorizationService
+	auditService         *AuditService
+}
+
+func NewAccessPolicyService(
+	accessPolicyRepo user_management.AccessPolicyRepository,
+	userRepo user_management.UserRepository,
+	authorizationService *AuthorizationService,
+	auditService *AuditService,
+) *AccessPolicyService {
+	return &AccessPolicyService{
+		accessPolicyRepo:     accessPolicyRepo,
+		userRepo:             userRepo,
+		authorizationService: authorizationService,
+		auditService:         auditService,
+	}
+}
+
+func (s *AccessPolicyService) ListPolicies(ctx context.Context) ([]*models.AccessPolicy, error) {
+	return s.accessPolicyRepo.FindAll(ctx)
+}
+
+func (s *AccessPolicyService) GetPolicy(ctx context.Context, policyID string) (*models.AccessPolicy, error) {
+	id, err := parseID(policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := s.accessPolicyRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, ErrAccessPolicyNotFound
+	}
+	return policy, nil
+}
+
+func (s *AccessPolicyService) CreatePolicy(ctx context.Context, actor *models.User, input AccessPolicyInput, client ClientInfo) (*models.AccessPolicy, error) {
+	policy := &models.AccessPolicy{}
+	if err := applyAccessPolicyInput(policy, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.accessPolicyRepo.Create(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	s.recordChange(ctx, actor, AuditActionAccessPolicyCreate, policy, client)
+	return policy, nil
+}
+
+func (s *AccessPolicyService) UpdatePolicy(ctx context.Context, actor *models.User, policyID string, input AccessPolicyInput, client ClientInfo) (*models.AccessPolicy, error) {
+	policy, err := s.GetPolicy(ctx, policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyAccessPolicyInput(policy, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.accessPolicyRepo.Update(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	s.recordChange(ctx, actor, AuditActionAccessPolicyUpdate, policy, client)
+	return policy, nil
+}
+
+func (s *AccessPolicyService) DeletePolicy(ctx context.Context, actor *models.User, policyID string, client ClientInfo) error {
+	policy, err := s.GetPolicy(ctx, policyID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.accessPolicyRepo.Delete(ctx, policy.ID); err != nil {
+		return err
+	}
+
+	s.recordChange(ctx, actor, AuditActionAccessPolicyDelete, policy, client)
+	return nil
+}
+
+// SetUserAttributes replaces the attributes of the user with the given ID.
+func (s *AccessPolicyService) SetUserAttributes(ctx context.Context, actor *models.User, userID string, attributes map[string]interface{}, client ClientInfo) (*models.User, error) {
+	id, err := parseID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, value := range attributes {
+		if !isAttributeValue(value) {
+			return nil, ErrInvalidUserAttribute
+		}
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	encoded, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, err
+	}
+	user.Attributes = string(encoded)
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    actor.ID,
+		Action:     AuditActionUserAttributesUpdate,
+		Resource:   AuditResourceUser,
+		ResourceID: user.ID.String(),
+		Details:    map[string]interface{}{"attributes": attributes},
+		Client:     client,
+	})
+
+	return user, nil
+}
+
+// Explain evaluates req for the user with the given ID without performing
+// it. It is the dry run behind the explain endpoint.
+func (s *AccessPolicyService) Explain(ctx context.Context, userID string, req AccessRequest) (*AccessDecision, error) {
+	id, err := parseID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	permissions, err := s.authorizationService.UserPermissions(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Evaluate(ctx, user, permissions, req)
+}
+
+// Evaluate decides whether subject, holding permissions, may perform req.
+func (s *AccessPolicyService) Evaluate(ctx context.Context, subject *models.User, permissions PermissionSet, req AccessRequest) (*AccessDecision, error) {
+	policies, err := s.accessPolicyRepo.FindEnabled(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := s.attributes(ctx, subject, req)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := &AccessDecision{
+		RoleGranted: permissions.Has(req.Action),
+		Policies:    []PolicyEvaluation{},
+		Attributes:  attributes,
+	}
+
+	var allowedBy, deniedBy *PolicyEvaluation
+	for _, policy := range policies {
+		evaluation, applies, err := evaluatePolicy(policy, req.Action, attributes)
+		if err != nil {
+			return nil, err
+		}
+		if !applies {
+			continue
+		}
+
+		decision.Policies = append(decision.Policies, evaluation)
+		last := &decision.Policies[len(decision.Policies)-1]
+		if !evaluation.Matched {
+			continue
+		}
+		if evaluation.Effect == models.AccessPolicyEffectDeny && deniedBy == nil {
+			deniedBy = last
+		}
+		if evaluation.Effect == models.AccessPolicyEffectAllow && allowedBy == nil {
+			allowedBy = last
+		}
+	}
+
+	switch {
+	case subject.IsSuperAdmin:
+		decision.Allowed, decision.Reason = true, "caller is a super admin"
+	case deniedBy != nil:
+		decision.Reason = fmt.Sprintf("denied by policy %q", deniedBy.Name)
+	case decision.RoleGranted:
+		decision.Allowed, decision.Reason = true, "granted by role permission "+req.Action
+	case allowedBy != nil && req.Context.ViaAPIKey:
+		decision.Reason = fmt.Sprintf("policy %q cannot extend an API key beyond its scopes", allowedBy.Name)
+	case allowedBy != nil:
+		decision.Allowed, decision.Reason = true, fmt.Sprintf("allowed by policy %q", allowedBy.Name)
+	default:
+		decision.Reason = "no role permission or policy allows " + req.Action
+	}
+
+	return decision, nil
+}
+
+// attributes builds the subject, resource and context attributes conditions
+// are evaluated against.
+func (s *AccessPolicyService) attributes(ctx context.Context, subject *models.User, req AccessRequest) (map[string]interface{}, error) {
+	subjectAttributes, err := userAttributes(subject)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := make(map[string]interface{}, len(req.Resource)+1)
+	if resourceType, _, ok := strings.Cut(req.Action, ":"); ok {
+		resource["type"] = resourceType
+	}
+	for key, value := range req.Resource {
+		resource[key] = value
+	}
+	if resource["type"] == "users" {
+		if id, err := parseID(fmt.Sprint(resource["id"])); err == nil {
+			if target, err := s.userRepo.FindByID(ctx, id); err == nil {
+				targetAttributes, err := userAttributes(target)
+				if err != nil {
+					return nil, err
+				}
+				for key, value := range targetAttributes {
+					resource[key] = value
+				}
+			}
+		}
+	}
+
+	now := req.Context.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	now = now.UTC()
+	contextAttributes := map[string]interface{}{
+		"ip":      req.Context.IP,
+		"time":    now.Format("15:04"),
+		"weekday": strings.ToLower(now.Weekday().String()),
+		"hour":    float64(now.Hour()),
+		"api_key": req.Context.ViaAPIKey,
+	}
+	if verifiedAt := req.Context.MFAVerifiedAt; verifiedAt != nil {
+		contextAttributes["mfa_age_seconds"] = float64(int64(now.Sub(*verifiedAt).Seconds()))
+	}
+
+	return map[string]interface{}{
+		"subject":  subjectAttributes,
+		"resource": resource,
+		"context":  contextAttributes,
+	}, nil
+}
+
+func (s *AccessPolicyService) recordChange(ctx context.Context, actor *models.User, action string, policy *models.AccessPolicy, client ClientInfo) {
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    actor.ID,
+		Action:     action,
+		Resource:   AuditResourceAccessPolicy,
+		ResourceID: policy.ID.String(),
+		Details:    map[string]interface{}{"name": policy.Name, "effect": policy.Effect},
+		Client:     client,
+	})
+}
+
+// DecodeAccessPolicy returns the actions and conditions stored on policy.
+func DecodeAccessPolicy(policy *models.AccessPolicy) ([]string, []PolicyCondition, error) {
+	var actions []string
+	if err := json.Unmarshal([]byte(policy.Actions), &actions); err != nil {
+		return nil, nil, fmt.Errorf("invalid access policy actions: %v", err)
+	}
+
+	conditions := []PolicyCondition{}
+	if strings.TrimSpace(policy.Conditions) != "" {
+		if err := json.Unmarshal([]byte(policy.Conditions), &conditions); err != nil {
+			return nil, nil, fmt.Errorf("invalid access policy conditions: %v", err)
+		}
+	}
+
+	return actions, conditions, nil
+}
+
+func applyAccessPolicyInput(policy *models.AccessPolicy, input AccessPolicyInput) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidAccessPolicy, fmt.Sprintf(format, args...))
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > maxAccessPolicyNameLength {
+		return invalid("name must be between 1 and %d characters", maxAccessPolicyNameLength)
+	}
+	if input.Effect != models.AccessPolicyEffectAllow && input.Effect != models.AccessPolicyEffectDeny {
+		return invalid("effect must be allow or deny")
+	}
+
+	if len(input.Actions) == 0 {
+		return invalid("at least one action is required")
+	}
+	for _, action := range input.Actions {
+		if action != wildcardPermission && !permissionNamePattern.MatchString(action) {
+			return invalid("action %q must have the form resource:action", action)
+		}
+	}
+
+	if input.Conditions == nil {
+		input.Conditions = []PolicyCondition{}
+	}
+	for i, condition := range input.Conditions {
+		if !isAttributePath(condition.Attribute) {
+			return invalid("condition %d: attribute must start with subject., resource. or context.", i)
+		}
+		if !conditionOperators[condition.Operator] {
+			return invalid("condition %d: unknown operator %q", i, condition.Operator)
+		}
+		if condition.Operator == ConditionExists {
+			continue
+		}
+		if condition.ValueFrom != "" {
+			if condition.Value != nil {
+				return invalid("condition %d: only one of value and value_from may be set", i)
+			}
+			if !isAttributePath(condition.ValueFrom) {
+				return invalid("condition %d: value_from must start with subject., resource. or context.", i)
+			}
+			continue
+		}
+		if condition.Value == nil {
+			return invalid("condition %d: value or value_from is required", i)
+		}
+		if condition.Operator == ConditionCIDR {
+			for _, cidr := range listOf(condition.Value) {
+				if _, _, err := net.ParseCIDR(fmt.Sprint(cidr)); err != nil {
+					return invalid("condition %d: %v is not a CIDR range", i, cidr)
+				}
+			}
+		}
+	}
+
+	actions, err := json.Marshal(input.Actions)
+	if err != nil {
+		return err
+	}
+	conditions, err := json.Marshal(input.Conditions)
+	if err != nil {
+		return err
+	}
+
+	policy.Name = name
+	policy.Description = strings.TrimSpace(input.Description)
+	policy.Effect = input.Effect
+	policy.Actions = string(actions)
+	policy.Conditions = string(conditions)
+	policy.Enabled = input.Enabled
+	return nil
+}
+
+// evaluatePolicy reports whether policy applies to action and, if so, how
+// each of its conditions evaluated.
+func evaluatePolicy(policy *models.AccessPolicy, action string, attributes map[string]interface{}) (PolicyEvaluation, bool, error) {
+	actions, conditions, err := DecodeAccessPolicy(policy)
+	if err != nil {
+		return PolicyEvaluation{}, false, err
+	}
+
+	applies := false
+	for _, pattern := range actions {
+		if matchPermission(pattern, action) {
+			applies = true
+			break
+		}
+	}
+	if !applies {
+		return PolicyEvaluation{}, false, nil
+	}
+
+	evaluation := PolicyEvaluation{
+		PolicyID:   policy.ID,
+		Name:       policy.Name,
+		Effect:     policy.Effect,
+		Matched:    true,
+		Conditions: make([]ConditionResult, 0, len(conditions)),
+	}
+	for _, condition := range conditions {
+		result := evaluateCondition(condition, attributes)
+		evaluation.Conditions = append(evaluation.Conditions, result)
+		evaluation.Matched = evaluation.Matched && result.Passed
+	}
+
+	return evaluation, true, nil
+}
+
+func evaluateCondition(condition PolicyCondition, attributes map[string]interface{}) ConditionResult {
+	actual := lookupAttribute(attributes, condition.Attribute)
+	expected := condition.Value
+	if condition.ValueFrom != "" {
+		expected = lookupAttribute(attributes, condition.ValueFrom)
+	}
+
+	result := ConditionResult{PolicyCondition: condition, Actual: actual}
+	if condition.Operator == ConditionExists {
+		result.Passed = actual != nil
+		return result
+	}
+	// A missing attribute never satisfies a condition, not even ne or not_in.
+	if actual == nil || expected == nil {
+		return result
+	}
+
+	switch condition.Operator {
+	case ConditionEquals:
+		result.Passed = valuesEqual(actual, expected)
+	case ConditionNotEquals:
+		result.Passed = !valuesEqual(actual, expected)
+	case ConditionIn:
+		result.Passed = containsValue(listOf(expected), actual)
+	case ConditionNotIn:
+		result.Passed = !containsValue(listOf(expected), actual)
+	case ConditionContains:
+		result.Passed = containsValue(listOf(actual), expected)
+	case ConditionGreater, ConditionGreaterOrEq, ConditionLess, ConditionLessOrEq:
+		order := compareValues(actual, expected)
+		switch condition.Operator {
+		case ConditionGreater:
+			result.Passed = order > 0
+		case ConditionGreaterOrEq:
+			result.Passed = order >= 0
+		case ConditionLess:
+			result.Passed = order < 0
+		case ConditionLessOrEq:
+			result.Passed = order <= 0
+		}
+	case ConditionCIDR:
+		ip := net.ParseIP(fmt.Sprint(actual))
+		for _, cidr := range listOf(expected) {
+			if _, network, err := net.ParseCIDR(fmt.Sprint(cidr)); err == nil && ip != nil && network.Contains(ip) {
+				result.Passed = true
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+func userAttributes(user *models.User) (map[string]interface{}, error) {
+	custom := map[string]interface{}{}
+	if strings.TrimSpace(user.Attributes) != "" {
+		if err := json.Unmarshal([]byte(user.Attributes), &custom); err != nil {
+			return nil, fmt.Errorf("invalid user attributes: %v", err)
+		}
+	}
+
+	roles := make([]interface{}, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		roles = append(roles, role.Name)
+	}
+
+	return map[string]interface{}{
+		"id":             user.ID.String(),
+		"tenant_id":      user.TenantID.String(),
+		"email":          user.Email,
+		"roles":          roles,
+		"super_admin":    user.IsSuperAdmin,
+		"mfa_enabled":    user.MFAEnabled,
+		"email_verified": user.EmailVerified,
+		"attributes":     custom,
+	}, nil
+}
+
+func lookupAttribute(attributes map[string]interface{}, path string) interface{} {
+	var current interface{} = attributes
+	for _, key := range strings.Split(path, ".") {
+		values, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = values[key]
+	}
+	return current
+}
+
+func isAttributePath(path string) bool {
+	for _, root := range attributeRoots {
+		if strings.HasPrefix(path, root) && len(path) > len(root) {
+			return true
+		}
+	}
+	return false
+}
+
+func isAttributeValue(value interface{}) bool {
+	switch v := value.(type) {
+	case string, float64, bool:
+		return true
+	case []interface{}:
+		for _, item := range v {
+			switch item.(type) {
+			case string, float64, bool:
+			default:
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func listOf(value interface{}) []interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{value}
+}
+
+func containsValue(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if valuesEqual(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func valuesEqual(a, b interface{}) bool {
+	if x, ok := a.(float64); ok {
+		if y, ok := b.(float64); ok {
+			return x == y
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func compareValues(a, b interface{}) int {
+	if x, ok := a.(float64); ok {
+		if y, ok := b.(float64); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
@@ -0,0 +1,175 @@
+package user_management
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/o1egl/paseto"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+// Authentication methods recorded in the amr claim of access tokens.
+const (
+	AuthMethodPassword      = "password"
+	AuthMethodMFA           = "mfa"
+	AuthMethodTrustedDevice = "trusted_device"
+	AuthMethodFederated     = "federated"
+)
+
+const (
+	accessTokenAudience = "adminsuite"
+	mfaTokenAudience    = "adminsuite-mfa"
+	tokenIssuer         = "adminsuite-auth"
+)
+
+// Custom claim keys of access tokens. PASETO custom claims are strings, so
+// list claims are stored as JSON arrays.
+const (
+	claimTenantID      = "tid"
+	claimRoles         = "roles"
+	claimPermissions   = "perms"
+	claimAuthMethod    = "amr"
+	claimSecurityStamp = "sst"
+	claimSuperAdmin    = "sa"
+	claimMFAEnabled    = "mfa"
+	claimMFAMethod     = "mfm"
+	claimMFAVerifiedAt = "mfat"
+	claimSessionID     = "sid"
+	claimClientID      = "cid"
+	claimScope         = "scope"
+)
+
+// claimRoleSync is the custom claim of temporary MFA tokens holding the
+// JSON encoded role sync of a federated sign-in.
+const claimRoleSync = "rsync"
+
+var ErrInvalidAccessToken = errors.New("invalid or expired access token")
+
+// AccessClaims are the claims of an access token. Besides the standard
+// claims they carry enough about the user to authorize requests without
+// loading the user, for as long as SecurityStamp is the user's current stamp.
+type AccessClaims struct {
+	paseto.JSONToken
+	UserID        uuid.UUID
+	TenantID      uuid.UUID
+	Roles         []string
+	Permissions   []string
+	AuthMethod    string
+	SecurityStamp string
+	SuperAdmin    bool
+	MFAEnabled    bool
+	MFAMethod     models.MFAMethod
+	// MFAVerifiedAt is when the user last passed a second factor in the
+	// token's session, or the zero time if they have not.
+	MFAVerifiedAt time.Time
+	SessionID     uuid.UUID
+	// ClientID and Scope are set on tokens issued to OAuth clients: the
+	// client's public ID and the space-separated scopes granted to it.
+	// Permissions are then limited to those the scopes allow.
+	ClientID string
+	Scope    string
+}
+
+// User returns the user described by the claims. It only has the fields the
+// claims carry and must not be saved.
+func (c *AccessClaims) User() *models.User {
+	roles := make([]models.Role, 0, len(c.Roles))
+	for _, name := range c.Roles {
+		roles = append(roles, models.Role{TenantID: c.TenantID, Name: name})
+	}
+
+	return &models.User{
+		BaseModel:     models.BaseModel{ID: c.UserID},
+		TenantID:      c.TenantID,
+		IsActive:      true,
+		IsSuperAdmin:  c.SuperAdmin,
+		MFAEnabled:    c.MFAEnabled,
+		MFAMethod:     c.MFAMethod,
+		SecurityStamp: c.SecurityStamp,
+		Roles:         roles,
+	}
+}
+
+func (c *AccessClaims) token() (paseto.JSONToken, error) {
+	token := c.JSONToken
+	roles, err := json.Marshal(c.Roles)
+	if err != nil {
+		return token, err
+	}
+	permissions, err := json.Marshal(c.Permissions)
+	if err != nil {
+		return token, err
+	}
+
+	token.Set(claimTenantID, c.TenantID.String())
+	token.Set(claimRoles, string(roles))
+	token.Set(claimPermissions, string(permissions))
+	token.Set(claimAuthMethod, c.AuthMethod)
+	token.Set(claimSecurityStamp, c.SecurityStamp)
+	token.Set(claimSuperAdmin, strconv.FormatBool(c.SuperAdmin))
+	token.Set(claimMFAEnabled, strconv.FormatBool(c.MFAEnabled))
+	if c.MFAEnabled {
+		token.Set(claimMFAMethod, string(c.MFAMethod))
+	}
+	if !c.MFAVerifiedAt.IsZero() {
+		token.Set(claimMFAVerifiedAt, c.MFAVerifiedAt.UTC().Format(time.RFC3339))
+	}
+	if c.SessionID != uuid.Nil {
+		token.Set(claimSessionID, c.SessionID.String())
+	}
+	if c.ClientID != "" {
+		token.Set(claimClientID, c.ClientID)
+		token.Set(claimScope, c.Scope)
+	}
+	return token, nil
+}
+
+// parseAccessClaims checks the standard claims of token and decodes the
+// custom ones.
+func parseAccessClaims(token paseto.JSONToken) (*AccessClaims, error) {
+	if err := token.Validate(paseto.ForAudience(accessTokenAudience), paseto.IssuedBy(tokenIssuer), paseto.ValidAt(time.Now())); err != nil {
+		return nil, ErrInvalidAccessToken
+	}
+
+	claims := &AccessClaims{
+		JSONToken:     token,
+		AuthMethod:    token.Get(claimAuthMethod),
+		SecurityStamp: token.Get(claimSecurityStamp),
+		SuperAdmin:    token.Get(claimSuperAdmin) == "true",
+		MFAEnabled:    token.Get(claimMFAEnabled) == "true",
+		MFAMethod:     models.MFAMethod(token.Get(claimMFAMethod)),
+		ClientID:      token.Get(claimClientID),
+		Scope:         token.Get(claimScope),
+	}
+
+	var err error
+	if claims.UserID, err = uuid.Parse(token.Subject); err != nil {
+		return nil, ErrInvalidAccessToken
+	}
+	if claims.TenantID, err = uuid.Parse(token.Get(claimTenantID)); err != nil {
+		return nil, ErrInvalidAccessToken
+	}
+	if err := json.Unmarshal([]byte(token.Get(claimRoles)), &claims.Roles); err != nil {
+		return nil, fmt.Errorf("%w: roles: %v", ErrInvalidAccessToken, err)
+	}
+	if err := json.Unmarshal([]byte(token.Get(claimPermissions)), &claims.Permissions); err != nil {
+		return nil, fmt.Errorf("%w: permissions: %v", ErrInvalidAccessToken, err)
+	}
+	if verifiedAt := token.Get(claimMFAVerifiedAt); verifiedAt != "" {
+		if claims.MFAVerifiedAt, err = time.Parse(time.RFC3339, verifiedAt); err != nil {
+			return nil, ErrInvalidAccessToken
+		}
+	}
+	if sid := token.Get(claimSessionID); sid != "" {
+		if claims.SessionID, err = uuid.Parse(sid); err != nil {
+			return nil, ErrInvalidAccessToken
+		}
+	}
+
+	return claims, nil
+}
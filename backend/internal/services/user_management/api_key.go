@@ -0,0 +1,269 @@
+package user_management
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+var (
+	ErrInvalidAPIKey           = errors.New("invalid or expired API key")
+	ErrAPIKeyNotFound          = errors.New("API key not found")
+	ErrAPIKeyPermissionDenied  = errors.New("API key permissions must be a subset of the owner's permissions")
+	ErrInvalidAPIKeyExpiration = errors.New("API key expiration must be in the future")
+)
+
+const (
+	apiKeyScheme     = "ask"
+	apiKeyPrefixSize = 6
+	apiKeySecretSize = 32
+)
+
+type APIKeyService struct {
+	apiKeyRepo           user_management.APIKeyRepository
+	userRepo             user_management.UserRepository
+	authorizationService *AuthorizationService
+	auditService         *AuditService
+}
+
+func NewAPIKeyService(
+	apiKeyRepo user_management.APIKeyRepository,
+	userRepo user_management.UserRepository,
+	authorizationService *AuthorizationService,
+	auditService *AuditService,
+) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo:           apiKeyRepo,
+		userRepo:             userRepo,
+		authorizationService: authorizationService,
+		auditService:         auditService,
+	}
+}
+
+// CreateAPIKey issues a new key for user. The returned plaintext key is not
+// stored and cannot be retrieved again.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, user *models.User, name string, permissions []string, expiresAt *time.Time, client ClientInfo) (string, *models.APIKey, error) {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return "", nil, ErrInvalidAPIKeyExpiration
+	}
+
+	permissions = normalizePermissions(permissions)
+	granted, err := s.authorizationService.UserPermissions(ctx, user)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, permission := range permissions {
+		if !granted.Has(permission) {
+			return "", nil, ErrAPIKeyPermissionDenied
+		}
+	}
+
+	encodedPermissions, err := json.Marshal(permissions)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rawKey, prefix, hash, err := generateAPIKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	apiKey := &models.APIKey{
+		UserID:      user.ID,
+		TenantID:    user.TenantID,
+		Prefix:      prefix,
+		Key:         hash,
+		Name:        name,
+		Permissions: string(encodedPermissions),
+		ExpiresAt:   expiresAt,
+	}
+	if err := s.apiKeyRepo.Create(ctx, apiKey); err != nil {
+		return "", nil, err
+	}
+
+	s.recordAPIKeyAction(ctx, user, AuditActionAPIKeyCreate, apiKey, map[string]interface{}{
+		"name":        name,
+		"permissions": permissions,
+	}, client)
+
+	return rawKey, apiKey, nil
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, user *models.User) ([]*models.APIKey, error) {
+	return s.apiKeyRepo.FindByUserID(ctx, user.ID)
+}
+
+// RotateAPIKey replaces the secret of an existing key, keeping its name,
+// permissions and expiry. The previous secret stops working immediately.
+func (s *APIKeyService) RotateAPIKey(ctx context.Context, user *models.User, apiKeyID string, client ClientInfo) (string, *models.APIKey, error) {
+	apiKey, err := s.findOwnedAPIKey(ctx, user, apiKeyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rawKey, prefix, hash, err := generateAPIKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	apiKey.Prefix = prefix
+	apiKey.Key = hash
+	apiKey.LastUsedAt = nil
+	if err := s.apiKeyRepo.Update(ctx, apiKey); err != nil {
+		return "", nil, err
+	}
+
+	s.recordAPIKeyAction(ctx, user, AuditActionAPIKeyRotate, apiKey, nil, client)
+
+	return rawKey, apiKey, nil
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, user *models.User, apiKeyID string, client ClientInfo) error {
+	apiKey, err := s.findOwnedAPIKey(ctx, user, apiKeyID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.apiKeyRepo.Delete(ctx, apiKey.ID); err != nil {
+		return err
+	}
+
+	s.recordAPIKeyAction(ctx, user, AuditActionAPIKeyRevoke, apiKey, nil, client)
+
+	return nil
+}
+
+// AuthenticateAPIKey resolves a plaintext key to its owner. The returned
+// permissions are the key's scopes narrowed to what the owner currently
+// holds, so removing a role from the owner also removes it from their keys.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, rawKey string) (*models.User, *models.APIKey, []string, error) {
+	prefix, ok := parseAPIKeyPrefix(rawKey)
+	if !ok {
+		return nil, nil, nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.apiKeyRepo.FindByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, nil, nil, ErrInvalidAPIKey
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(rawKey)), []byte(apiKey.Key)) != 1 {
+		return nil, nil, nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if apiKey.ExpiresAt != nil && apiKey.ExpiresAt.Before(now) {
+		return nil, nil, nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.FindByID(ctx, apiKey.UserID)
+	if err != nil || !user.IsActive {
+		return nil, nil, nil, ErrInvalidAPIKey
+	}
+
+	var scopes []string
+	if err := json.Unmarshal([]byte(apiKey.Permissions), &scopes); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid API key permissions: %v", err)
+	}
+
+	granted, err := s.authorizationService.UserPermissions(ctx, user)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	permissions := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if granted.Has(scope) {
+			permissions = append(permissions, scope)
+		}
+	}
+
+	if err := s.apiKeyRepo.UpdateLastUsedAt(ctx, apiKey.ID, now); err != nil {
+		return nil, nil, nil, err
+	}
+	apiKey.LastUsedAt = &now
+
+	return user, apiKey, permissions, nil
+}
+
+func (s *APIKeyService) findOwnedAPIKey(ctx context.Context, user *models.User, apiKeyID string) (*models.APIKey, error) {
+	id, err := uuid.Parse(apiKeyID)
+	if err != nil {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	apiKey, err := s.apiKeyRepo.FindByID(ctx, id)
+	if err != nil || apiKey.UserID != user.ID {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	return apiKey, nil
+}
+
+func (s *APIKeyService) recordAPIKeyAction(ctx context.Context, user *models.User, action string, apiKey *models.APIKey, details map[string]interface{}, client ClientInfo) {
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    user.ID,
+		Action:     action,
+		Resource:   AuditResourceAPIKey,
+		ResourceID: apiKey.ID.String(),
+		Details:    details,
+		Client:     client,
+	})
+}
+
+// generateAPIKey returns a key of the form ask_<prefix>_<secret> together
+// with its lookup prefix and the hash that is persisted.
+func generateAPIKey() (string, string, string, error) {
+	prefixBytes, err := generateRandomBytes(apiKeyPrefixSize)
+	if err != nil {
+		return "", "", "", err
+	}
+	secretBytes, err := generateRandomBytes(apiKeySecretSize)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	prefix := hex.EncodeToString(prefixBytes)
+	rawKey := fmt.Sprintf("%s_%s_%s", apiKeyScheme, prefix, base64.RawURLEncoding.EncodeToString(secretBytes))
+
+	return rawKey, prefix, hashToken(rawKey), nil
+}
+
+func parseAPIKeyPrefix(rawKey string) (string, bool) {
+	parts := strings.SplitN(rawKey, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyScheme || len(parts[1]) != hex.EncodedLen(apiKeyPrefixSize) || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// hashToken hashes a high-entropy opaque credential for storage.
+func hashToken(rawToken string) string {
+	sum := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizePermissions(permissions []string) []string {
+	seen := make(map[string]bool, len(permissions))
+	normalized := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		permission = strings.TrimSpace(permission)
+		if permission == "" || seen[permission] {
+			continue
+		}
+		seen[permission] = true
+		normalized = append(normalized, permission)
+	}
+	return normalized
+}
@@ -0,0 +1,254 @@
+package user_management
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+const (
+	AuditActionRegister               = "user.register"
+	AuditActionLogin                  = "auth.login"
+	AuditActionLoginFailed            = "auth.login_failed"
+	AuditActionLogout                 = "auth.logout"
+	AuditActionMFAVerifyFailed        = "auth.mfa_verify_failed"
+	AuditActionTokenRefresh           = "auth.token_refresh"
+	AuditActionRefreshTokenReuse      = "auth.refresh_token_reuse"
+	AuditActionPasswordReset          = "auth.password_reset"
+	AuditActionAccountUnlock          = "user.unlock"
+	AuditActionUserActivate           = "user.activate"
+	AuditActionUserDeactivate         = "user.deactivate"
+	AuditActionMFASetup               = "mfa.setup"
+	AuditActionMFAEnable              = "mfa.enable"
+	AuditActionMFADisable             = "mfa.disable"
+	AuditActionMFABackupCodes         = "mfa.backup_codes"
+	AuditActionMFABackupUsed          = "mfa.backup_code_used"
+	AuditActionRoleCreate             = "role.create"
+	AuditActionRoleUpdate             = "role.update"
+	AuditActionRoleDelete             = "role.delete"
+	AuditActionRoleAssign             = "role.assign"
+	AuditActionRoleUnassign           = "role.unassign"
+	AuditActionPermissionCreate       = "permission.create"
+	AuditActionPermissionUpdate       = "permission.update"
+	AuditActionPermissionDelete       = "permission.delete"
+	AuditActionPermissionAssign       = "permission.assign"
+	AuditActionPermissionUnassign     = "permission.unassign"
+	AuditActionAPIKeyCreate           = "api_key.create"
+	AuditActionAPIKeyRotate           = "api_key.rotate"
+	AuditActionAPIKeyRevoke           = "api_key.revoke"
+	AuditActionDeviceTrust            = "device.trust"
+	AuditActionDeviceRename           = "device.rename"
+	AuditActionDeviceRevoke           = "device.revoke"
+	AuditActionSessionRevoke          = "session.revoke"
+	AuditActionSessionRevokeAll       = "session.revoke_all"
+	AuditActionTenantCreate           = "tenant.create"
+	AuditActionTenantUpdate           = "tenant.update"
+	AuditActionTenantDelete           = "tenant.delete"
+	AuditActionAuthPolicyUpdate       = "auth_policy.update"
+	AuditActionBrandingUpdate         = "branding.update"
+	AuditActionAccessPolicyCreate     = "access_policy.create"
+	AuditActionAccessPolicyUpdate     = "access_policy.update"
+	AuditActionAccessPolicyDelete     = "access_policy.delete"
+	AuditActionUserAttributesUpdate   = "user.attributes_update"
+	AuditActionSigningKeyRotate       = "signing_key.rotate"
+	AuditActionSigningKeyRetire       = "signing_key.retire"
+	AuditActionOAuthAuthorize         = "oauth.authorize"
+	AuditActionOAuthConsentDenied     = "oauth.consent_denied"
+	AuditActionOAuthToken             = "oauth.token"
+	AuditActionOAuthCodeReuse         = "oauth.code_reuse"
+	AuditActionOIDCLogout             = "oidc.logout"
+	AuditActionOAuthClientCreate      = "oauth_client.create"
+	AuditActionOAuthClientUpdate      = "oauth_client.update"
+	AuditActionOAuthClientDelete      = "oauth_client.delete"
+	AuditActionOAuthClientRotate      = "oauth_client.rotate_secret"
+	AuditActionOAuthScopeCreate       = "oauth_scope.create"
+	AuditActionOAuthScopeUpdate       = "oauth_scope.update"
+	AuditActionOAuthScopeDelete       = "oauth_scope.delete"
+	AuditActionFederatedLogin         = "auth.federated_login"
+	AuditActionFederatedLink          = "user.federated_link"
+	AuditActionFederatedLinkRequest   = "user.federated_link_request"
+	AuditActionFederatedUnlink        = "user.federated_unlink"
+	AuditActionIdentityProviderCreate = "identity_provider.create"
+	AuditActionIdentityProviderUpdate = "identity_provider.update"
+	AuditActionIdentityProviderDelete = "identity_provider.delete"
+	AuditActionSAMLProviderCreate     = "saml_provider.create"
+	AuditActionSAMLProviderUpdate     = "saml_provider.update"
+	AuditActionSAMLProviderDelete     = "saml_provider.delete"
+)
+
+const (
+	AuditResourceUser             = "user"
+	AuditResourceRole             = "role"
+	AuditResourcePermission       = "permission"
+	AuditResourceAPIKey           = "api_key"
+	AuditResourceDevice           = "device"
+	AuditResourceSession          = "session"
+	AuditResourceTenant           = "tenant"
+	AuditResourceAccessPolicy     = "access_policy"
+	AuditResourceSigningKey       = "signing_key"
+	AuditResourceOAuthClient      = "oauth_client"
+	AuditResourceOAuthScope       = "oauth_scope"
+	AuditResourceIdentityProvider = "identity_provider"
+	AuditResourceSAMLProvider     = "saml_provider"
+)
+
+const (
+	defaultAuditLogPageSize = 50
+	maxAuditLogPageSize     = 200
+	auditCursorTimeFormat   = time.RFC3339Nano
+)
+
+var ErrInvalidAuditCursor = errors.New("invalid audit log cursor")
+
+// AuditEntry is a single security-relevant event. ActorID is the user who
+// performed the action, which is not necessarily the user it was performed on.
+// The entry is recorded in the tenant carried by the context.
+type AuditEntry struct {
+	ActorID    uuid.UUID
+	Action     string
+	Resource   string
+	ResourceID string
+	Details    map[string]interface{}
+	Client     ClientInfo
+}
+
+type AuditLogQuery struct {
+	UserID   *uuid.UUID
+	Action   string
+	Resource string
+	From     *time.Time
+	To       *time.Time
+	Cursor   string
+	Limit    int
+}
+
+type AuditService struct {
+	auditLogRepo user_management.AuditLogRepository
+}
+
+func NewAuditService(auditLogRepo user_management.AuditLogRepository) *AuditService {
+	return &AuditService{auditLogRepo: auditLogRepo}
+}
+
+// Record persists an audit entry. Failures are logged rather than returned
+// so that auditing never breaks the operation being audited.
+func (s *AuditService) Record(ctx context.Context, entry AuditEntry) {
+	details := "{}"
+	if len(entry.Details) > 0 {
+		encoded, err := json.Marshal(entry.Details)
+		if err != nil {
+			log.Printf("Failed to encode audit details for %s: %v", entry.Action, err)
+		} else {
+			details = string(encoded)
+		}
+	}
+
+	auditLog := &models.AuditLog{
+		UserID:     entry.ActorID,
+		Action:     entry.Action,
+		Resource:   entry.Resource,
+		ResourceID: entry.ResourceID,
+		Details:    details,
+		IP:         entry.Client.IP,
+		RequestID:  entry.Client.RequestID,
+	}
+
+	if err := s.auditLogRepo.Create(ctx, auditLog); err != nil {
+		log.Printf("Failed to write audit log for %s: %v", entry.Action, err)
+	}
+}
+
+// RecordUserAction is a shorthand for actions a user performs on their own account.
+func (s *AuditService) RecordUserAction(ctx context.Context, user *models.User, action string, details map[string]interface{}, client ClientInfo) {
+	s.Record(ctx, AuditEntry{
+		ActorID:    user.ID,
+		Action:     action,
+		Resource:   AuditResourceUser,
+		ResourceID: user.ID.String(),
+		Details:    details,
+		Client:     client,
+	})
+}
+
+// Query returns one page of the current tenant's audit logs, newest first,
+// together with the cursor for the next page. The cursor is empty on the
+// last page.
+func (s *AuditService) Query(ctx context.Context, query AuditLogQuery) ([]*models.AuditLog, string, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultAuditLogPageSize
+	}
+	if limit > maxAuditLogPageSize {
+		limit = maxAuditLogPageSize
+	}
+
+	filter := user_management.AuditLogFilter{
+		UserID:   query.UserID,
+		Action:   query.Action,
+		Resource: query.Resource,
+		From:     query.From,
+		To:       query.To,
+		Limit:    limit + 1,
+	}
+
+	if query.Cursor != "" {
+		createdAt, id, err := decodeAuditCursor(query.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.BeforeCreatedAt = &createdAt
+		filter.BeforeID = id
+	}
+
+	logs, err := s.auditLogRepo.Find(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(logs) > limit {
+		logs = logs[:limit]
+		last := logs[len(logs)-1]
+		nextCursor = encodeAuditCursor(last.CreatedAt, last.ID)
+	}
+
+	return logs, nextCursor, nil
+}
+
+func encodeAuditCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := createdAt.UTC().Format(auditCursorTimeFormat) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeAuditCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidAuditCursor
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return time.Time{}, uuid.Nil, ErrInvalidAuditCursor
+	}
+
+	createdAt, err := time.Parse(auditCursorTimeFormat, parts[0])
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidAuditCursor
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidAuditCursor
+	}
+
+	return createdAt, id, nil
+}
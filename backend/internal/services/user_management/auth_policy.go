@@ -0,0 +1,330 @@
+package user_management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+var (
+	ErrInvalidAuthPolicy     = errors.New("invalid auth policy")
+	ErrPasswordPolicy        = errors.New("password does not meet the tenant's password policy")
+	ErrEmailDomainNotAllowed = errors.New("email domain is not allowed for this tenant")
+)
+
+const (
+	minPasswordLength      = 8
+	maxPasswordLength      = 128
+	minAccessTokenSeconds  = 60
+	maxAccessTokenSeconds  = 24 * 60 * 60
+	maxRefreshTokenSeconds = 90 * 24 * 60 * 60
+	maxTrustedDeviceDays   = 365
+	maxLockoutSeconds      = 30 * 24 * 60 * 60
+)
+
+// AuthPolicy is the typed form of Tenant.AuthPolicyConfig. Fields that are
+// missing from the stored JSON keep the values from DefaultAuthPolicy.
+type AuthPolicy struct {
+	RequireEmailVerification bool           `json:"require_email_verification"`
+	Password                 PasswordPolicy `json:"password"`
+	MFA                      MFAPolicy      `json:"mfa"`
+	Session                  SessionPolicy  `json:"session"`
+	Lockout                  LockoutPolicy  `json:"lockout"`
+	// TrustedDeviceDays is how long a remembered device may skip MFA.
+	// Zero disables remembering devices.
+	TrustedDeviceDays int `json:"trusted_device_days"`
+	// AllowedEmailDomains restricts self-registration to these domains.
+	// An empty list allows any domain.
+	AllowedEmailDomains []string `json:"allowed_email_domains"`
+}
+
+type PasswordPolicy struct {
+	MinLength        int  `json:"min_length"`
+	RequireUppercase bool `json:"require_uppercase"`
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireDigit     bool `json:"require_digit"`
+	RequireSymbol    bool `json:"require_symbol"`
+}
+
+// MFAPolicy controls second factors. When Required is set, users without MFA
+// can only reach the MFA enrollment endpoints until they enable one of the
+// AllowedMethods.
+type MFAPolicy struct {
+	Required       bool               `json:"required"`
+	AllowedMethods []models.MFAMethod `json:"allowed_methods"`
+}
+
+type SessionPolicy struct {
+	AccessTokenTTLSeconds  int `json:"access_token_ttl_seconds"`
+	RefreshTokenTTLSeconds int `json:"refresh_token_ttl_seconds"`
+}
+
+// LockoutPolicy controls how failed logins are throttled. An account is
+// locked once it reaches MaxFailedAttempts consecutive failures; every
+// further failure doubles the lock, starting at BaseLockoutSeconds and
+// capped at MaxLockoutSeconds. A client IP is refused once it produces
+// IPMaxFailedAttempts failures within IPWindowSeconds. Zero thresholds
+// disable the respective check.
+type LockoutPolicy struct {
+	MaxFailedAttempts   int `json:"max_failed_attempts"`
+	BaseLockoutSeconds  int `json:"base_lockout_seconds"`
+	MaxLockoutSeconds   int `json:"max_lockout_seconds"`
+	IPMaxFailedAttempts int `json:"ip_max_failed_attempts"`
+	IPWindowSeconds     int `json:"ip_window_seconds"`
+}
+
+func DefaultAuthPolicy() *AuthPolicy {
+	return &AuthPolicy{
+		RequireEmailVerification: false,
+		Password: PasswordPolicy{
+			MinLength: minPasswordLength,
+		},
+		MFA: MFAPolicy{
+			Required: false,
+			AllowedMethods: []models.MFAMethod{
+				models.MFAMethodTOTP,
+				models.MFAMethodSMS,
+				models.MFAMethodEmail,
+				models.MFAMethodHOTP,
+			},
+		},
+		Session: SessionPolicy{
+			AccessTokenTTLSeconds:  15 * 60,
+			RefreshTokenTTLSeconds: 7 * 24 * 60 * 60,
+		},
+		Lockout: LockoutPolicy{
+			MaxFailedAttempts:   5,
+			BaseLockoutSeconds:  60,
+			MaxLockoutSeconds:   3600,
+			IPMaxFailedAttempts: 50,
+			IPWindowSeconds:     900,
+		},
+		TrustedDeviceDays:   30,
+		AllowedEmailDomains: []string{},
+	}
+}
+
+// Validate normalizes the policy in place and reports the first setting that
+// is out of range. The returned error wraps ErrInvalidAuthPolicy.
+func (p *AuthPolicy) Validate() error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidAuthPolicy, fmt.Sprintf(format, args...))
+	}
+
+	if p.Password.MinLength < minPasswordLength || p.Password.MinLength > maxPasswordLength {
+		return invalid("password.min_length must be between %d and %d", minPasswordLength, maxPasswordLength)
+	}
+
+	if len(p.MFA.AllowedMethods) == 0 {
+		return invalid("mfa.allowed_methods must not be empty")
+	}
+	for _, method := range p.MFA.AllowedMethods {
+		switch method {
+		case models.MFAMethodTOTP, models.MFAMethodSMS, models.MFAMethodEmail, models.MFAMethodHOTP:
+		default:
+			return invalid("mfa.allowed_methods contains unknown method %q", method)
+		}
+	}
+
+	session := p.Session
+	if session.AccessTokenTTLSeconds < minAccessTokenSeconds || session.AccessTokenTTLSeconds > maxAccessTokenSeconds {
+		return invalid("session.access_token_ttl_seconds must be between %d and %d", minAccessTokenSeconds, maxAccessTokenSeconds)
+	}
+	if session.RefreshTokenTTLSeconds < session.AccessTokenTTLSeconds || session.RefreshTokenTTLSeconds > maxRefreshTokenSeconds {
+		return invalid("session.refresh_token_ttl_seconds must be between the access token lifetime and %d", maxRefreshTokenSeconds)
+	}
+
+	lockout := p.Lockout
+	if lockout.MaxFailedAttempts < 0 || lockout.IPMaxFailedAttempts < 0 {
+		return invalid("lockout thresholds must not be negative")
+	}
+	if lockout.MaxFailedAttempts > 0 && (lockout.BaseLockoutSeconds <= 0 || lockout.MaxLockoutSeconds < lockout.BaseLockoutSeconds) {
+		return invalid("lockout.base_lockout_seconds must be positive and not exceed lockout.max_lockout_seconds")
+	}
+	if lockout.BaseLockoutSeconds > maxLockoutSeconds || lockout.MaxLockoutSeconds > maxLockoutSeconds {
+		return invalid("lockout durations must not exceed %d seconds", maxLockoutSeconds)
+	}
+	if lockout.IPMaxFailedAttempts > 0 && lockout.IPWindowSeconds <= 0 {
+		return invalid("lockout.ip_window_seconds must be positive")
+	}
+
+	if p.TrustedDeviceDays < 0 || p.TrustedDeviceDays > maxTrustedDeviceDays {
+		return invalid("trusted_device_days must be between 0 and %d", maxTrustedDeviceDays)
+	}
+
+	domains := make([]string, 0, len(p.AllowedEmailDomains))
+	for _, domain := range p.AllowedEmailDomains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain == "" || strings.ContainsAny(domain, "@ ") {
+			return invalid("allowed_email_domains contains invalid domain %q", domain)
+		}
+		domains = append(domains, domain)
+	}
+	p.AllowedEmailDomains = domains
+
+	return nil
+}
+
+// CheckPassword reports whether password satisfies the password policy. The
+// returned error wraps ErrPasswordPolicy and names the unmet requirement.
+func (p *AuthPolicy) CheckPassword(password string) error {
+	if len([]rune(password)) < p.Password.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters long", ErrPasswordPolicy, p.Password.MinLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	switch {
+	case p.Password.RequireUppercase && !hasUpper:
+		return fmt.Errorf("%w: must contain an uppercase letter", ErrPasswordPolicy)
+	case p.Password.RequireLowercase && !hasLower:
+		return fmt.Errorf("%w: must contain a lowercase letter", ErrPasswordPolicy)
+	case p.Password.RequireDigit && !hasDigit:
+		return fmt.Errorf("%w: must contain a digit", ErrPasswordPolicy)
+	case p.Password.RequireSymbol && !hasSymbol:
+		return fmt.Errorf("%w: must contain a symbol", ErrPasswordPolicy)
+	}
+
+	return nil
+}
+
+func (p *AuthPolicy) CheckEmailDomain(email string) error {
+	if len(p.AllowedEmailDomains) == 0 {
+		return nil
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ErrEmailDomainNotAllowed
+	}
+
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range p.AllowedEmailDomains {
+		if domain == allowed {
+			return nil
+		}
+	}
+
+	return ErrEmailDomainNotAllowed
+}
+
+func (p *AuthPolicy) AllowsMFAMethod(method models.MFAMethod) bool {
+	for _, allowed := range p.MFA.AllowedMethods {
+		if allowed == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *AuthPolicy) AccessTokenTTL() time.Duration {
+	return time.Duration(p.Session.AccessTokenTTLSeconds) * time.Second
+}
+
+func (p *AuthPolicy) RefreshTokenTTL() time.Duration {
+	return time.Duration(p.Session.RefreshTokenTTLSeconds) * time.Second
+}
+
+type AuthPolicyService struct {
+	tenantRepo   user_management.TenantRepository
+	auditService *AuditService
+}
+
+func NewAuthPolicyService(tenantRepo user_management.TenantRepository, auditService *AuditService) *AuthPolicyService {
+	return &AuthPolicyService{
+		tenantRepo:   tenantRepo,
+		auditService: auditService,
+	}
+}
+
+func (s *AuthPolicyService) GetPolicy(ctx context.Context, tenantID uuid.UUID) (*AuthPolicy, error) {
+	policy := DefaultAuthPolicy()
+	if tenantID == uuid.Nil {
+		return policy, nil
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tenant: %v", err)
+	}
+
+	if strings.TrimSpace(tenant.AuthPolicyConfig) == "" {
+		return policy, nil
+	}
+
+	if err := json.Unmarshal([]byte(tenant.AuthPolicyConfig), policy); err != nil {
+		return nil, fmt.Errorf("invalid auth policy config: %v", err)
+	}
+
+	return policy, nil
+}
+
+// UpdatePolicy validates policy and stores it as the tenant's auth policy.
+// It takes effect for the next login, registration or token refresh.
+func (s *AuthPolicyService) UpdatePolicy(ctx context.Context, actor *models.User, tenantID uuid.UUID, policy *AuthPolicy, client ClientInfo) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return ErrTenantNotFound
+	}
+
+	encoded, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	tenant.AuthPolicyConfig = string(encoded)
+	if err := s.tenantRepo.Update(ctx, tenant); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    actor.ID,
+		Action:     AuditActionAuthPolicyUpdate,
+		Resource:   AuditResourceTenant,
+		ResourceID: tenant.ID.String(),
+		Details:    map[string]interface{}{"policy": policy},
+		Client:     client,
+	})
+
+	return nil
+}
+
+// RequiresMFAEnrollment reports whether user must enable MFA, or switch to
+// a method the policy allows, before using anything but the MFA enrollment
+// endpoints.
+func (s *AuthPolicyService) RequiresMFAEnrollment(ctx context.Context, user *models.User) (bool, error) {
+	policy, err := s.GetPolicy(ctx, user.TenantID)
+	if err != nil {
+		return false, err
+	}
+
+	if user.MFAEnabled {
+		return !policy.AllowsMFAMethod(user.MFAMethod), nil
+	}
+	return policy.MFA.Required, nil
+}
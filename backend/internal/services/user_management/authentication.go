@@ -1,10 +1,14 @@
 package user_management
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
@@ -14,30 +18,78 @@ import (
 
 	"github.com/josy-coder/adminsuite/internal/models"
 	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+	"github.com/josy-coder/adminsuite/internal/tenancy"
 )
 
-var ErrMFARequired = errors.New("MFA required")
+var (
+	ErrMFARequired          = errors.New("MFA required")
+	ErrInvalidResetToken    = errors.New("invalid or expired password reset token")
+	ErrUnsupportedMFAMethod = errors.New("unsupported MFA method")
+	ErrAccountDisabled      = errors.New("account is disabled")
+)
+
+const passwordResetTokenTTL = time.Hour
 
 type AuthenticationService struct {
-	userRepo   user_management.UserRepository
-	tokenRepo  user_management.TokenRepository
-	paseto     paseto.V2
-	pasetoKey  []byte
-	mfaService *MFAService
+	userRepo             user_management.UserRepository
+	tokenRepo            user_management.TokenRepository
+	passwordResetRepo    user_management.PasswordResetRepository
+	tokenSigner          *TokenSigner
+	tokenHasher          *TokenHasher
+	mfaService           *MFAService
+	emailService         *EmailService
+	brandingService      *BrandingService
+	verificationService  *EmailVerificationService
+	policyService        *AuthPolicyService
+	loginProtection      *LoginProtectionService
+	auditService         *AuditService
+	deviceService        *DeviceService
+	authorizationService *AuthorizationService
+	securityStamps       *SecurityStampService
+	sessionService       *SessionService
+	revocations          *TokenRevocationService
+	frontendURL          string
 }
 
 func NewAuthenticationService(
 	userRepo user_management.UserRepository,
 	tokenRepo user_management.TokenRepository,
-	pasetoKey []byte,
+	passwordResetRepo user_management.PasswordResetRepository,
+	tokenSigner *TokenSigner,
+	tokenHasher *TokenHasher,
 	mfaService *MFAService,
+	emailService *EmailService,
+	brandingService *BrandingService,
+	verificationService *EmailVerificationService,
+	policyService *AuthPolicyService,
+	loginProtection *LoginProtectionService,
+	auditService *AuditService,
+	deviceService *DeviceService,
+	authorizationService *AuthorizationService,
+	securityStamps *SecurityStampService,
+	sessionService *SessionService,
+	revocations *TokenRevocationService,
+	frontendURL string,
 ) *AuthenticationService {
 	return &AuthenticationService{
-		userRepo:   userRepo,
-		tokenRepo:  tokenRepo,
-		paseto:     paseto.V2{},
-		pasetoKey:  pasetoKey,
-		mfaService: mfaService,
+		userRepo:             userRepo,
+		tokenRepo:            tokenRepo,
+		passwordResetRepo:    passwordResetRepo,
+		tokenSigner:          tokenSigner,
+		tokenHasher:          tokenHasher,
+		mfaService:           mfaService,
+		emailService:         emailService,
+		brandingService:      brandingService,
+		verificationService:  verificationService,
+		policyService:        policyService,
+		loginProtection:      loginProtection,
+		auditService:         auditService,
+		deviceService:        deviceService,
+		authorizationService: authorizationService,
+		securityStamps:       securityStamps,
+		sessionService:       sessionService,
+		revocations:          revocations,
+		frontendURL:          strings.TrimRight(frontendURL, "/"),
 	}
 }
 
@@ -57,40 +109,141 @@ var params = &argon2Params{
 	keyLength:   32,
 }
 
-func (s *AuthenticationService) RegisterUser(user *models.User) error {
+// RegisterUser creates user as a member of the tenant carried by ctx after
+// checking the email domain and password against the tenant's auth policy.
+func (s *AuthenticationService) RegisterUser(ctx context.Context, user *models.User, client ClientInfo) error {
+	tenantID, _ := tenancy.TenantID(ctx)
+	policy, err := s.policyService.GetPolicy(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if err := policy.CheckEmailDomain(user.Email); err != nil {
+		return err
+	}
+	if err := policy.CheckPassword(user.Password); err != nil {
+		return err
+	}
+
 	hashedPassword, err := s.hashPassword(user.Password)
 	if err != nil {
 		return err
 	}
 	user.Password = hashedPassword
+	user.EmailVerified = false
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return err
+	}
 
-	return s.userRepo.Create(user)
+	s.auditService.RecordUserAction(ctx, user, AuditActionRegister, map[string]interface{}{
+		"email":    user.Email,
+		"username": user.Username,
+	}, client)
+
+	if err := s.verificationService.SendVerificationEmail(ctx, user); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+	}
+
+	return nil
 }
 
-func (s *AuthenticationService) AuthenticateUser(email, password string) (*models.User, string, string, error) {
-	user, err := s.userRepo.FindByEmail(email)
+// AuthenticateUser verifies a password login against the accounts of the
+// tenant carried by ctx. For MFA-enabled accounts it returns ErrMFARequired
+// unless deviceToken identifies a trusted device.
+func (s *AuthenticationService) AuthenticateUser(ctx context.Context, email, password, deviceToken string, client ClientInfo) (*models.User, string, string, error) {
+	user, err := s.userRepo.FindByEmail(ctx, email)
 	if err != nil {
+		tenantID, _ := tenancy.TenantID(ctx)
+		policy, err := s.policyService.GetPolicy(ctx, tenantID)
+		if err != nil {
+			return nil, "", "", err
+		}
+		if err := s.loginProtection.CheckIP(ctx, client, policy); err != nil {
+			return nil, "", "", err
+		}
+		// Hashing the password costs as much as verifying it, so the
+		// response does not tell whether the account exists.
+		if _, err := s.hashPassword(password); err != nil {
+			return nil, "", "", err
+		}
+		if err := s.loginProtection.RecordFailure(ctx, nil, email, models.LoginAttemptMethodPassword, client, policy); err != nil {
+			return nil, "", "", err
+		}
+		s.auditService.Record(ctx, AuditEntry{
+			Action:   AuditActionLoginFailed,
+			Resource: AuditResourceUser,
+			Details:  map[string]interface{}{"email": email, "reason": "unknown_user"},
+			Client:   client,
+		})
 		return nil, "", "", errors.New("invalid credentials")
 	}
 
+	policy, err := s.policyService.GetPolicy(ctx, user.TenantID)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	if err := s.loginProtection.CheckIP(ctx, client, policy); err != nil {
+		s.auditService.RecordUserAction(ctx, user, AuditActionLoginFailed, map[string]interface{}{"reason": "ip_throttled"}, client)
+		return nil, "", "", err
+	}
+	if err := s.loginProtection.CheckUser(user); err != nil {
+		s.auditService.RecordUserAction(ctx, user, AuditActionLoginFailed, map[string]interface{}{"reason": "account_locked"}, client)
+		return nil, "", "", err
+	}
+
 	match, err := s.verifyPassword(user.Password, password)
 	if err != nil {
 		return nil, "", "", errors.New("error verifying password")
 	}
 	if !match {
+		if err := s.loginProtection.RecordFailure(ctx, user, email, models.LoginAttemptMethodPassword, client, policy); err != nil {
+			return nil, "", "", err
+		}
+		s.auditService.RecordUserAction(ctx, user, AuditActionLoginFailed, map[string]interface{}{
+			"reason":          "invalid_password",
+			"failed_attempts": user.FailedLoginCount,
+			"locked":          user.LockedUntil != nil,
+		}, client)
 		return nil, "", "", errors.New("invalid credentials")
 	}
 
+	if !user.IsActive {
+		s.auditService.RecordUserAction(ctx, user, AuditActionLoginFailed, map[string]interface{}{"reason": "account_disabled"}, client)
+		return nil, "", "", ErrAccountDisabled
+	}
+
+	if policy.RequireEmailVerification && !user.EmailVerified {
+		return nil, "", "", ErrEmailNotVerified
+	}
+
+	var deviceID *uuid.UUID
+	method := AuthMethodPassword
 	if user.MFAEnabled {
-		return user, "", "", errors.New("multi factor authentication required")
+		if device, trusted := s.deviceService.FindTrustedDevice(ctx, user, deviceToken, policy); trusted {
+			deviceID = &device.ID
+			method = AuthMethodTrustedDevice
+			if err := s.deviceService.TouchDevice(ctx, device.ID); err != nil {
+				return nil, "", "", err
+			}
+		}
 	}
 
-	accessToken, err := s.generateAccessToken(user)
-	if err != nil {
+	if user.MFAEnabled && deviceID == nil {
+		// The failure counter is only reset once the second factor has been
+		// verified, otherwise a known password would allow unlimited MFA guesses.
+		if err := s.loginProtection.RecordSuccess(ctx, user, models.LoginAttemptMethodPassword, client, false); err != nil {
+			return nil, "", "", err
+		}
+		return user, "", "", ErrMFARequired
+	}
+
+	if err := s.loginProtection.RecordSuccess(ctx, user, models.LoginAttemptMethodPassword, client, true); err != nil {
 		return nil, "", "", err
 	}
+	s.auditService.RecordUserAction(ctx, user, AuditActionLogin, map[string]interface{}{"method": method}, client)
 
-	refreshToken, err := s.generateRefreshToken(user)
+	accessToken, refreshToken, err := s.GenerateTokensForDevice(ctx, user, deviceID, method, client)
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -98,36 +251,331 @@ func (s *AuthenticationService) AuthenticateUser(email, password string) (*model
 	return user, accessToken, refreshToken, nil
 }
 
-func (s *AuthenticationService) RefreshToken(refreshToken string) (string, string, error) {
-	tokenData, err := s.tokenRepo.FindByToken(refreshToken)
+// VerifyMFALogin checks the second factor of a pending login and records
+// the attempt against the account's lockout counters. A code of a method
+// the tenant's policy no longer allows still completes the login, but the
+// user then has to enroll an allowed method before using the rest of the
+// API; see RequiresMFAEnrollment. roleSync, from the temporary token, is
+// applied once the code is verified.
+func (s *AuthenticationService) VerifyMFALogin(ctx context.Context, user *models.User, code string, roleSync *RoleSync, client ClientInfo) (bool, error) {
+	policy, err := s.policyService.GetPolicy(ctx, user.TenantID)
 	if err != nil {
-		return "", "", errors.New("invalid refresh token")
+		return false, err
 	}
 
-	if tokenData.ExpiresAt.Before(time.Now()) {
-		return "", "", errors.New("refresh token expired")
+	if err := s.loginProtection.CheckIP(ctx, client, policy); err != nil {
+		return false, err
+	}
+	if err := s.loginProtection.CheckUser(user); err != nil {
+		return false, err
 	}
 
-	user, err := s.userRepo.FindByID(tokenData.UserID)
+	var valid bool
+	switch user.MFAMethod {
+	case models.MFAMethodTOTP:
+		valid, err = s.mfaService.VerifyTOTP(ctx, user, code)
+	case models.MFAMethodSMS:
+		valid, err = s.mfaService.VerifySMSCode(ctx, user, code)
+	case models.MFAMethodEmail:
+		valid, err = s.mfaService.VerifyEmailCode(ctx, user, code)
+	case models.MFAMethodHOTP:
+		valid, err = s.mfaService.VerifyHOTP(ctx, user, code)
+	default:
+		return false, ErrUnsupportedMFAMethod
+	}
+
+	if err != nil || !valid {
+		if recordErr := s.loginProtection.RecordFailure(ctx, user, user.Email, models.LoginAttemptMethodMFA, client, policy); recordErr != nil {
+			return false, recordErr
+		}
+		s.auditService.RecordUserAction(ctx, user, AuditActionMFAVerifyFailed, map[string]interface{}{
+			"mfa_method":      user.MFAMethod,
+			"failed_attempts": user.FailedLoginCount,
+			"locked":          user.LockedUntil != nil,
+		}, client)
+		return false, err
+	}
+
+	if err := s.loginProtection.RecordSuccess(ctx, user, models.LoginAttemptMethodMFA, client, true); err != nil {
+		return false, err
+	}
+	if err := s.authorizationService.ApplyRoleSync(ctx, user, roleSync, client); err != nil {
+		return false, err
+	}
+	s.auditService.RecordUserAction(ctx, user, AuditActionLogin, map[string]interface{}{
+		"method":                  models.LoginAttemptMethodMFA,
+		"mfa_method":              user.MFAMethod,
+		"mfa_enrollment_required": !policy.AllowsMFAMethod(user.MFAMethod),
+	}, client)
+
+	return true, nil
+}
+
+func (s *AuthenticationService) UnlockUser(ctx context.Context, actor *models.User, userID string, client ClientInfo) error {
+	id, err := uuid.Parse(userID)
 	if err != nil {
-		return "", "", errors.New("user not found")
+		return err
 	}
 
-	newAccessToken, err := s.generateAccessToken(user)
+	if err := s.loginProtection.UnlockUser(ctx, id); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    actor.ID,
+		Action:     AuditActionAccountUnlock,
+		Resource:   AuditResourceUser,
+		ResourceID: id.String(),
+		Client:     client,
+	})
+
+	return nil
+}
+
+// SetUserActive activates or deactivates the user with ID userID.
+// Deactivated users cannot log in, and their sessions and outstanding access
+// tokens are revoked.
+func (s *AuthenticationService) SetUserActive(ctx context.Context, actor *models.User, userID string, active bool, client ClientInfo) error {
+	id, err := uuid.Parse(userID)
 	if err != nil {
-		return "", "", err
+		return ErrUserNotFound
 	}
 
-	newRefreshToken, err := s.generateRefreshToken(user)
+	user, err := s.userRepo.FindByID(ctx, id)
 	if err != nil {
-		return "", "", err
+		return ErrUserNotFound
 	}
 
-	if err := s.tokenRepo.Delete(tokenData.ID); err != nil {
+	user.IsActive = active
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	action := AuditActionUserActivate
+	if !active {
+		action = AuditActionUserDeactivate
+		if err := s.securityStamps.Rotate(ctx, user); err != nil {
+			return err
+		}
+		if err := s.sessionService.EndAllSessions(ctx, user); err != nil {
+			return err
+		}
+	}
+
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    actor.ID,
+		Action:     action,
+		Resource:   AuditResourceUser,
+		ResourceID: user.ID.String(),
+		Client:     client,
+	})
+
+	return nil
+}
+
+// RefreshToken exchanges refreshToken for a new token pair of the same
+// session. Each refresh token can be exchanged once; presenting it again
+// revokes the session as a whole with ErrRefreshTokenReused.
+func (s *AuthenticationService) RefreshToken(ctx context.Context, refreshToken string, client ClientInfo) (string, string, error) {
+	tokens, err := s.refresh(ctx, refreshToken, nil, nil, client)
+	if err != nil {
 		return "", "", err
 	}
+	return tokens.AccessToken, tokens.RefreshToken, nil
+}
 
-	return newAccessToken, newRefreshToken, nil
+// RefreshClientTokens exchanges a refresh token issued to oauthClient like
+// RefreshToken does. scopes, if not nil, must be the scopes of the session:
+// the token of a narrower grant is not issued.
+func (s *AuthenticationService) RefreshClientTokens(ctx context.Context, refreshToken string, oauthClient *models.OAuthClient, scopes []string, client ClientInfo) (*ClientTokens, error) {
+	return s.refresh(ctx, refreshToken, oauthClient, scopes, client)
+}
+
+func (s *AuthenticationService) refresh(ctx context.Context, refreshToken string, oauthClient *models.OAuthClient, scopes []string, client ClientInfo) (*ClientTokens, error) {
+	tokenData, err := s.tokenRepo.FindByTokenHash(ctx, s.tokenHasher.Hash(refreshToken))
+	if err != nil || tokenData.Type != models.TokenTypeRefresh {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if tokenData.RotatedAt != nil {
+		return nil, s.refreshTokenReused(ctx, tokenData, client)
+	}
+
+	if tokenData.ExpiresAt.Before(time.Now()) {
+		return nil, errors.New("refresh token expired")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, tokenData.UserID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if !user.IsActive {
+		return nil, ErrAccountDisabled
+	}
+
+	policy, err := s.policyService.GetPolicy(ctx, user.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionService.ResumeSession(ctx, user, tokenData, oauthClient, time.Now().Add(policy.RefreshTokenTTL()), client)
+	if err != nil {
+		if err == ErrSessionNotFound {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if scopes != nil && !sameScopes(strings.Fields(session.Scopes), scopes) {
+		return nil, ErrInvalidScope
+	}
+
+	// Losing a race against a concurrent refresh of the same token is
+	// indistinguishable from replaying it afterwards.
+	next, nextToken, err := s.newRefreshToken(user, session)
+	if err != nil {
+		return nil, err
+	}
+	rotated, err := s.tokenRepo.Rotate(ctx, tokenData, next)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		tokenData.SessionID = &session.ID
+		return nil, s.refreshTokenReused(ctx, tokenData, client)
+	}
+
+	newAccessToken, err := s.generateAccessToken(ctx, user, policy, session, oauthClient)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.DeviceID != nil {
+		if err := s.deviceService.TouchDevice(ctx, *session.DeviceID); err != nil {
+			return nil, err
+		}
+	}
+
+	s.auditService.RecordUserAction(ctx, user, AuditActionTokenRefresh, nil, client)
+
+	return &ClientTokens{
+		AccessToken:  newAccessToken,
+		RefreshToken: nextToken,
+		ExpiresIn:    policy.AccessTokenTTL(),
+		Scopes:       strings.Fields(session.Scopes),
+		Session:      session,
+	}, nil
+}
+
+func (s *AuthenticationService) refreshTokenReused(ctx context.Context, token *models.Token, client ClientInfo) error {
+	if err := s.sessionService.RevokeFamily(ctx, token, client); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// RequestPasswordReset issues a single-use reset token for the account
+// registered under email in the tenant carried by ctx and mails a reset link
+// to it. Unknown addresses are ignored and failures only logged, so callers
+// cannot tell from the outcome whether an account exists.
+func (s *AuthenticationService) RequestPasswordReset(ctx context.Context, email string) {
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return
+	}
+
+	if err := s.sendPasswordReset(ctx, user); err != nil {
+		log.Printf("Failed to send password reset email to user %s: %v", user.ID, err)
+	}
+}
+
+func (s *AuthenticationService) sendPasswordReset(ctx context.Context, user *models.User) error {
+	branding, err := s.brandingService.GetBranding(ctx, user.TenantID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.passwordResetRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	resetToken, resetTokenHash, err := s.tokenHasher.NewToken()
+	if err != nil {
+		return err
+	}
+
+	reset := &models.PasswordReset{
+		UserID: user.ID,
+		Token: models.Token{
+			UserID:    user.ID,
+			TokenHash: resetTokenHash,
+			Type:      models.TokenTypePasswordReset,
+			ExpiresAt: time.Now().Add(passwordResetTokenTTL),
+		},
+	}
+	if err := s.passwordResetRepo.Create(ctx, reset); err != nil {
+		return err
+	}
+
+	link := frontendLink(s.frontendURL, "/reset-password", user.TenantID, resetToken)
+	body := fmt.Sprintf("We received a request to reset your %s password.\r\n\r\n"+
+		"Use the link below within %d minutes to choose a new password:\r\n%s\r\n\r\n"+
+		"If you did not request this, you can ignore this email.", branding.ProductName, int(passwordResetTokenTTL.Minutes()), link)
+
+	return s.emailService.SendBrandedEmail(branding, user.Email, fmt.Sprintf("Reset your %s password", branding.ProductName), body)
+}
+
+// ResetPassword consumes a reset token, sets the new password and signs
+// the user out of every existing session.
+func (s *AuthenticationService) ResetPassword(ctx context.Context, resetToken, newPassword string, client ClientInfo) error {
+	reset, err := s.passwordResetRepo.FindByTokenHash(ctx, s.tokenHasher.Hash(resetToken))
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+
+	if reset.Token.ExpiresAt.Before(time.Now()) {
+		_ = s.passwordResetRepo.DeleteByUserID(ctx, reset.UserID)
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.FindByID(ctx, reset.UserID)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+
+	policy, err := s.policyService.GetPolicy(ctx, user.TenantID)
+	if err != nil {
+		return err
+	}
+	if err := policy.CheckPassword(newPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.Password = hashedPassword
+	user.PasswordChangedAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	if err := s.securityStamps.Rotate(ctx, user); err != nil {
+		return err
+	}
+
+	if err := s.passwordResetRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err := s.sessionService.EndAllSessions(ctx, user); err != nil {
+		return err
+	}
+
+	s.auditService.RecordUserAction(ctx, user, AuditActionPasswordReset, nil, client)
+
+	return nil
 }
 
 func (s *AuthenticationService) hashPassword(password string) (string, error) {
@@ -154,7 +602,7 @@ func (s *AuthenticationService) verifyPassword(encodedhash, password string) (bo
 
 	otherHash := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, p.keyLength)
 
-	return base64.RawStdEncoding.EncodeToString(hash) == base64.RawStdEncoding.EncodeToString(otherHash), nil
+	return subtle.ConstantTimeCompare(hash, otherHash) == 1, nil
 }
 
 func generateRandomBytes(n uint32) ([]byte, error) {
@@ -192,72 +640,168 @@ func decodeHash(encodedHash string) (p *argon2Params, salt, hash []byte, err err
 		return nil, nil, nil, err
 	}
 
+	hash, err = base64.RawStdEncoding.DecodeString(vals[5])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
 	p.keyLength = uint32(len(hash))
 
 	return p, salt, hash, nil
 }
 
-func (s *AuthenticationService) ValidateToken(token string) (*paseto.JSONToken, error) {
-	var claims paseto.JSONToken
-	err := s.paseto.Decrypt(token, s.pasetoKey, &claims, nil)
+// PublicKeys returns the keys that verify the tokens this service signs.
+func (s *AuthenticationService) PublicKeys() []PublicKey {
+	return s.tokenSigner.PublicKeys()
+}
+
+// ValidateToken verifies the signature of an access token, checks its
+// audience, issuer and lifetime and makes sure it has not been revoked.
+func (s *AuthenticationService) ValidateToken(ctx context.Context, token string) (*AccessClaims, error) {
+	var jsonToken paseto.JSONToken
+	err := s.tokenSigner.Verify(token, &jsonToken)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := parseAccessClaims(jsonToken)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.revocations.IsRevoked(ctx, claims)
 	if err != nil {
 		return nil, err
 	}
-	return &claims, nil
+	if revoked {
+		return nil, ErrInvalidAccessToken
+	}
+
+	return claims, nil
+}
+
+// Principal returns the user an access token was issued to. While the
+// token's security stamp is the user's current one the user is rebuilt from
+// the claims without touching the database and fromClaims is true; such a
+// user must not be saved. Otherwise the claims are stale and the user is
+// loaded.
+func (s *AuthenticationService) Principal(ctx context.Context, claims *AccessClaims) (user *models.User, fromClaims bool, err error) {
+	stamp, err := s.securityStamps.Current(ctx, claims.UserID)
+	if err != nil {
+		return nil, false, err
+	}
+	if stamp == claims.SecurityStamp {
+		return claims.User(), true, nil
+	}
+
+	user, err = s.userRepo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, false, err
+	}
+	if !user.IsActive {
+		return nil, false, ErrAccountDisabled
+	}
+	return user, false, nil
 }
 
-func (s *AuthenticationService) GetUserByID(userID string) (*models.User, error) {
+func (s *AuthenticationService) GetUserByID(ctx context.Context, userID string) (*models.User, error) {
 	id, err := uuid.Parse(userID)
 	if err != nil {
 		return nil, err
 	}
-	return s.userRepo.FindByID(id)
+	return s.userRepo.FindByID(ctx, id)
 }
 
-func (s *AuthenticationService) GenerateTempToken(userID uuid.UUID) (string, error) {
+// GenerateTempToken issues the token a login that requires MFA is
+// completed with. roleSync, if not nil, is applied once the second factor
+// is verified.
+func (s *AuthenticationService) GenerateTempToken(userID uuid.UUID, roleSync *RoleSync) (string, error) {
 	now := time.Now()
 	exp := now.Add(5 * time.Minute)
 	nbt := now
 
 	token := paseto.JSONToken{
-		Audience:   "adminsuite-mfa",
-		Issuer:     "adminsuite-auth",
+		Audience:   mfaTokenAudience,
+		Issuer:     tokenIssuer,
 		Jti:        uuid.New().String(),
 		Subject:    userID.String(),
 		IssuedAt:   now,
 		Expiration: exp,
 		NotBefore:  nbt,
 	}
+	if roleSync != nil {
+		encoded, err := json.Marshal(roleSync)
+		if err != nil {
+			return "", err
+		}
+		token.Set(claimRoleSync, string(encoded))
+	}
 
-	return s.paseto.Encrypt(s.pasetoKey, token, nil)
+	return s.tokenSigner.Sign(token)
 }
 
-func (s *AuthenticationService) GetUserByTempToken(tempToken string) (*models.User, error) {
+// GetUserByTempToken returns the user a temporary token was issued to and
+// the role sync to apply once they verified their second factor.
+func (s *AuthenticationService) GetUserByTempToken(ctx context.Context, tempToken string) (*models.User, *RoleSync, error) {
 	var token paseto.JSONToken
-	err := s.paseto.Decrypt(tempToken, s.pasetoKey, &token, nil)
+	err := s.tokenSigner.Verify(tempToken, &token)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if time.Now().After(token.Expiration) {
-		return nil, errors.New("temporary token expired")
+	// Access tokens are signed by the same key, so the audience tells the
+	// two apart.
+	if err := token.Validate(paseto.ForAudience(mfaTokenAudience), paseto.ValidAt(time.Now())); err != nil {
+		return nil, nil, errors.New("invalid or expired temporary token")
 	}
 
 	userID, err := uuid.Parse(token.Subject)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	var roleSync *RoleSync
+	if encoded := token.Get(claimRoleSync); encoded != "" {
+		if err := json.Unmarshal([]byte(encoded), &roleSync); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	return s.userRepo.FindByID(userID)
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !user.IsActive {
+		return nil, nil, ErrAccountDisabled
+	}
+	return user, roleSync, nil
 }
 
-func (s *AuthenticationService) GenerateTokens(user *models.User) (string, string, error) {
-	accessToken, err := s.generateAccessToken(user)
+func (s *AuthenticationService) GenerateTokens(ctx context.Context, user *models.User, method string, client ClientInfo) (string, string, error) {
+	return s.GenerateTokensForDevice(ctx, user, nil, method, client)
+}
+
+// GenerateTokensForDevice starts a session for client and issues its first
+// token pair. A session bound to a trusted device keeps the device's
+// LastUsedAt current when refreshed. Token lifetimes come from the session
+// settings of the user's tenant, and method is how the user authenticated.
+func (s *AuthenticationService) GenerateTokensForDevice(ctx context.Context, user *models.User, deviceID *uuid.UUID, method string, client ClientInfo) (string, string, error) {
+	policy, err := s.policyService.GetPolicy(ctx, user.TenantID)
 	if err != nil {
 		return "", "", err
 	}
 
-	refreshToken, err := s.generateRefreshToken(user)
+	session, err := s.sessionService.StartSession(ctx, user, deviceID, method, time.Now().Add(policy.RefreshTokenTTL()), client)
+	if err != nil {
+		return "", "", err
+	}
+
+	accessToken, err := s.generateAccessToken(ctx, user, policy, session, nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, err := s.generateRefreshToken(ctx, user, session)
 	if err != nil {
 		return "", "", err
 	}
@@ -265,35 +809,171 @@ func (s *AuthenticationService) GenerateTokens(user *models.User) (string, strin
 	return accessToken, refreshToken, nil
 }
 
-func (s *AuthenticationService) generateAccessToken(user *models.User) (string, error) {
+// ClientTokens are the tokens issued to an OAuth client in Session. The
+// access token expires after ExpiresIn and carries Scopes. RefreshToken is
+// empty unless the client was granted offline_access, and IDToken unless it
+// was granted openid.
+type ClientTokens struct {
+	AccessToken  string
+	RefreshToken string
+	IDToken      string
+	ExpiresIn    time.Duration
+	Scopes       []string
+	Session      *models.Session
+}
+
+// GenerateClientTokens starts a session in which user grants oauthClient
+// scopes and issues its first tokens. method is how the user authenticated
+// to approve the grant, in the session with ID parentSessionID if known.
+func (s *AuthenticationService) GenerateClientTokens(ctx context.Context, user *models.User, oauthClient *models.OAuthClient, parentSessionID *uuid.UUID, scopes []string, method string, client ClientInfo) (*ClientTokens, error) {
+	policy, err := s.policyService.GetPolicy(ctx, user.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionService.StartClientSession(ctx, user, oauthClient, parentSessionID, scopes, method, time.Now().Add(policy.RefreshTokenTTL()), client)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := &ClientTokens{
+		ExpiresIn: policy.AccessTokenTTL(),
+		Scopes:    scopes,
+		Session:   session,
+	}
+	if tokens.AccessToken, err = s.generateAccessToken(ctx, user, policy, session, oauthClient); err != nil {
+		return nil, err
+	}
+	if hasScope(scopes, ScopeOfflineAccess) {
+		if tokens.RefreshToken, err = s.generateRefreshToken(ctx, user, session); err != nil {
+			return nil, err
+		}
+	}
+
+	return tokens, nil
+}
+
+// Logout ends the session refreshToken was issued in, which revokes the
+// access tokens issued in it. accessToken, the caller's current access
+// token, is revoked as well if given, even when it belongs to no session.
+func (s *AuthenticationService) Logout(ctx context.Context, refreshToken, accessToken string, client ClientInfo) error {
+	if err := s.sessionService.Logout(ctx, refreshToken, client); err != nil {
+		return err
+	}
+
+	if accessToken == "" {
+		return nil
+	}
+	claims, err := s.ValidateToken(ctx, accessToken)
+	if err != nil {
+		// Invalid, expired and already revoked tokens need no revoking.
+		return nil
+	}
+	return s.revocations.RevokeToken(ctx, claims)
+}
+
+// RequiresMFAEnrollment reports whether the tenant's policy obliges user to
+// enable MFA before using the rest of the API.
+func (s *AuthenticationService) RequiresMFAEnrollment(ctx context.Context, user *models.User) (bool, error) {
+	return s.policyService.RequiresMFAEnrollment(ctx, user)
+}
+
+// generateAccessToken issues an access token whose claims describe the
+// user's tenant, roles and effective permissions as of now, versioned by
+// the user's security stamp, within session. Tokens of a session granted to
+// oauthClient only carry the permissions its scopes allow.
+func (s *AuthenticationService) generateAccessToken(ctx context.Context, user *models.User, policy *AuthPolicy, session *models.Session, oauthClient *models.OAuthClient) (string, error) {
+	permissions, err := s.authorizationService.UserPermissions(ctx, user)
+	if err != nil {
+		return "", err
+	}
+
+	var clientID string
+	if oauthClient != nil {
+		scopePermissions, err := s.authorizationService.ScopePermissions(ctx, strings.Fields(session.Scopes))
+		if err != nil {
+			return "", err
+		}
+		permissions = permissions.Intersect(scopePermissions)
+		clientID = oauthClient.ClientID
+	}
+
+	roles := make([]string, 0, len(user.Roles))
+	for _, role := range user.Roles {
+		roles = append(roles, role.Name)
+	}
+
 	now := time.Now()
-	exp := now.Add(15 * time.Minute)
+	exp := now.Add(policy.AccessTokenTTL())
 	nbt := now
 
-	token := paseto.JSONToken{
-		Audience:   "adminsuite",
-		Issuer:     "adminsuite-auth",
-		Jti:        uuid.New().String(),
-		Subject:    user.ID.String(),
-		IssuedAt:   now,
-		Expiration: exp,
-		NotBefore:  nbt,
+	claims := &AccessClaims{
+		JSONToken: paseto.JSONToken{
+			Audience:   accessTokenAudience,
+			Issuer:     tokenIssuer,
+			Jti:        uuid.New().String(),
+			Subject:    user.ID.String(),
+			IssuedAt:   now,
+			Expiration: exp,
+			NotBefore:  nbt,
+		},
+		UserID:        user.ID,
+		TenantID:      user.TenantID,
+		Roles:         roles,
+		Permissions:   permissions.Digest(),
+		AuthMethod:    session.AuthMethod,
+		SecurityStamp: user.SecurityStamp,
+		SuperAdmin:    user.IsSuperAdmin,
+		MFAEnabled:    user.MFAEnabled,
+		MFAMethod:     user.MFAMethod,
+		SessionID:     session.ID,
+		ClientID:      clientID,
+	}
+	if oauthClient != nil {
+		claims.Scope = session.Scopes
+	}
+	if session.MFAVerifiedAt != nil {
+		claims.MFAVerifiedAt = *session.MFAVerifiedAt
+	}
+	if err := s.revocations.ExemptIssued(ctx, claims); err != nil {
+		return "", err
+	}
+
+	token, err := claims.token()
+	if err != nil {
+		return "", err
 	}
 
-	return s.paseto.Encrypt(s.pasetoKey, token, nil)
+	return s.tokenSigner.Sign(token)
 }
 
-func (s *AuthenticationService) generateRefreshToken(user *models.User) (string, error) {
-	token := &models.Token{
-		UserID:    user.ID,
-		Token:     uuid.New().String(),
-		Type:      models.TokenTypeRefresh,
-		ExpiresAt: time.Now().Add(7 * 24 * time.Hour),
+func (s *AuthenticationService) generateRefreshToken(ctx context.Context, user *models.User, session *models.Session) (string, error) {
+	token, rawToken, err := s.newRefreshToken(user, session)
+	if err != nil {
+		return "", err
 	}
-
-	if err := s.tokenRepo.Create(token); err != nil {
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
 		return "", err
 	}
 
-	return token.Token, nil
+	return rawToken, nil
+}
+
+// newRefreshToken returns an unsaved refresh token of session that expires
+// with it, together with the token to hand to the client.
+func (s *AuthenticationService) newRefreshToken(user *models.User, session *models.Session) (*models.Token, string, error) {
+	rawToken, tokenHash, err := s.tokenHasher.NewToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &models.Token{
+		UserID:     user.ID,
+		DeviceID:   session.DeviceID,
+		SessionID:  &session.ID,
+		TokenHash:  tokenHash,
+		Type:       models.TokenTypeRefresh,
+		ExpiresAt:  session.ExpiresAt,
+		AuthMethod: session.AuthMethod,
+	}, rawToken, nil
 }
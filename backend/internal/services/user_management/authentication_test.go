@@ -0,0 +1,59 @@
+package user_management
+
+import "testing"
+
+func TestRefreshTokensRotate(t *testing.T) {
+	st := newServiceTest(t)
+	user := st.createUser(t, "omar@acme.test", "Secret-pass-1")
+
+	_, refreshToken, err := st.authService.GenerateTokens(st.ctx, user, AuthMethodPassword, ClientInfo{})
+	if err != nil {
+		t.Fatalf("failed to generate tokens: %v", err)
+	}
+
+	accessToken, next, err := st.authService.RefreshToken(st.ctx, refreshToken, ClientInfo{})
+	if err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if next == "" || next == refreshToken {
+		t.Fatalf("refresh did not rotate the refresh token")
+	}
+	if _, err := st.authService.ValidateToken(st.ctx, accessToken); err != nil {
+		t.Fatalf("refreshed access token was rejected: %v", err)
+	}
+	if _, _, err := st.authService.RefreshToken(st.ctx, next, ClientInfo{}); err != nil {
+		t.Fatalf("refreshing with the rotated token failed: %v", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	st := newServiceTest(t)
+	user := st.createUser(t, "pia@acme.test", "Secret-pass-1")
+
+	_, stolen, err := st.authService.GenerateTokens(st.ctx, user, AuthMethodPassword, ClientInfo{})
+	if err != nil {
+		t.Fatalf("failed to generate tokens: %v", err)
+	}
+	accessToken, next, err := st.authService.RefreshToken(st.ctx, stolen, ClientInfo{})
+	if err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+
+	if _, _, err := st.authService.RefreshToken(st.ctx, stolen, ClientInfo{}); err != ErrRefreshTokenReused {
+		t.Fatalf("reusing a rotated refresh token returned %v, want ErrRefreshTokenReused", err)
+	}
+	if _, _, err := st.authService.RefreshToken(st.ctx, next, ClientInfo{}); err == nil {
+		t.Fatalf("the latest refresh token of the session survived the reuse")
+	}
+	if _, err := st.authService.ValidateToken(st.ctx, accessToken); err != ErrInvalidAccessToken {
+		t.Fatalf("access token of the session returned %v, want ErrInvalidAccessToken", err)
+	}
+
+	accessToken, _, err = st.authService.GenerateTokens(st.ctx, user, AuthMethodPassword, ClientInfo{})
+	if err != nil {
+		t.Fatalf("signing in again after the reuse failed: %v", err)
+	}
+	if _, err := st.authService.ValidateToken(st.ctx, accessToken); err != nil {
+		t.Fatalf("access token of a new session was rejected: %v", err)
+	}
+}
@@ -1,7 +1,12 @@
 package user_management
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/google/uuid"
 
@@ -9,75 +14,753 @@ import (
 	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
 )
 
+var (
+	ErrInvalidID           = errors.New("invalid ID")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrRoleNotFound        = errors.New("role not found")
+	ErrPermissionNotFound  = errors.New("permission not found")
+	ErrRoleNameTaken       = errors.New("role name is already in use")
+	ErrPermissionNameTaken = errors.New("permission name is already in use")
+	ErrProtectedRole       = errors.New("the Admin role cannot be renamed or deleted")
+	ErrReservedRoleName    = errors.New("the role name Admin is reserved for the built-in role")
+	ErrInvalidParentRole   = errors.New("parent role not found")
+	ErrRoleCycle           = errors.New("role inheritance would create a cycle")
+	ErrInvalidPermission   = errors.New("permission names must have the form resource:action, where either part may be *")
+	ErrPermissionNotHeld   = errors.New("you can only grant permissions you hold yourself")
+	ErrWildcardPermission  = errors.New("permissions cannot be named with wildcards")
+)
+
+var permissionNamePattern = regexp.MustCompile(`^(\*|[A-Za-z0-9_.-]+):(\*|[A-Za-z0-9_.-]+)$`)
+
+// AdminRoleName is the name of the built-in role that holds every
+// permission without having them assigned explicitly. The role is
+// recognized by models.Role.IsAdmin; other roles cannot take its name.
+const AdminRoleName = "Admin"
+
+// RoleInput holds the editable role fields. Nil fields are left unchanged
+// on update; an empty ParentID removes the role's parent.
+type RoleInput struct {
+	Name        *string
+	Description *string
+	ParentID    *string
+}
+
+// PermissionInput holds the editable permission fields. Nil fields are left
+// unchanged on update.
+type PermissionInput struct {
+	Name        *string
+	Description *string
+}
+
+// PermissionGrant explains one permission a user holds. Permission is
+// assigned to Role, and the user holds it because they were assigned
+// AssignedRole, which is either Role itself or a role that inherits from it.
+// Grants that come from being a super admin have no roles.
+type PermissionGrant struct {
+	Permission   string
+	Role         *models.Role
+	AssignedRole *models.Role
+}
+
 type AuthorizationService struct {
 	userRepo       user_management.UserRepository
 	roleRepo       user_management.RoleRepository
 	permissionRepo user_management.PermissionRepository
+	oauthScopeRepo user_management.OAuthScopeRepository
+	securityStamps *SecurityStampService
+	auditService   *AuditService
 }
 
 func NewAuthorizationService(
 	userRepo user_management.UserRepository,
 	roleRepo user_management.RoleRepository,
 	permissionRepo user_management.PermissionRepository,
+	oauthScopeRepo user_management.OAuthScopeRepository,
+	securityStamps *SecurityStampService,
+	auditService *AuditService,
 ) *AuthorizationService {
 	return &AuthorizationService{
 		userRepo:       userRepo,
 		roleRepo:       roleRepo,
 		permissionRepo: permissionRepo,
+		oauthScopeRepo: oauthScopeRepo,
+		securityStamps: securityStamps,
+		auditService:   auditService,
+	}
+}
+
+func (s *AuthorizationService) ListRoles(ctx context.Context) ([]*models.Role, error) {
+	return s.roleRepo.FindAll(ctx)
+}
+
+func (s *AuthorizationService) GetRole(ctx context.Context, roleID string) (*models.Role, error) {
+	id, err := parseID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.roleRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+	return role, nil
+}
+
+// CreateRole creates a role. The actor must hold every permission the role
+// inherits from its parent.
+func (s *AuthorizationService) CreateRole(ctx context.Context, actor *models.User, input RoleInput, client ClientInfo) (*models.Role, error) {
+	role := &models.Role{}
+	if err := s.applyRoleInput(ctx, role, input); err != nil {
+		return nil, err
+	}
+	if err := s.checkGrantable(ctx, actor, role); err != nil {
+		return nil, err
+	}
+
+	if err := s.roleRepo.Create(ctx, role); err != nil {
+		return nil, err
+	}
+
+	s.recordChange(ctx, actor, AuditActionRoleCreate, AuditResourceRole, role.ID, map[string]interface{}{
+		"name": role.Name,
+	}, client)
+
+	return role, nil
+}
+
+// UpdateRole changes a role. The actor must hold every permission the
+// role grants once changed, including those it inherits.
+func (s *AuthorizationService) UpdateRole(ctx context.Context, actor *models.User, roleID string, input RoleInput, client ClientInfo) (*models.Role, error) {
+	role, err := s.GetRole(ctx, roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if role.IsAdmin && input.Name != nil && strings.TrimSpace(*input.Name) != AdminRoleName {
+		return nil, ErrProtectedRole
+	}
+
+	if err := s.applyRoleInput(ctx, role, input); err != nil {
+		return nil, err
+	}
+	if err := s.checkGrantable(ctx, actor, role); err != nil {
+		return nil, err
+	}
+
+	if err := s.roleRepo.Update(ctx, role); err != nil {
+		return nil, err
+	}
+	if err := s.securityStamps.RotateTenant(ctx, role.TenantID); err != nil {
+		return nil, err
+	}
+
+	s.recordChange(ctx, actor, AuditActionRoleUpdate, AuditResourceRole, role.ID, map[string]interface{}{
+		"name":        role.Name,
+		"description": role.Description,
+	}, client)
+
+	return role, nil
+}
+
+func (s *AuthorizationService) DeleteRole(ctx context.Context, actor *models.User, roleID string, client ClientInfo) error {
+	role, err := s.GetRole(ctx, roleID)
+	if err != nil {
+		return err
+	}
+
+	if role.IsAdmin {
+		return ErrProtectedRole
+	}
+
+	if err := s.roleRepo.Delete(ctx, role.ID); err != nil {
+		return err
+	}
+	if err := s.securityStamps.RotateTenant(ctx, role.TenantID); err != nil {
+		return err
 	}
+
+	s.recordChange(ctx, actor, AuditActionRoleDelete, AuditResourceRole, role.ID, map[string]interface{}{
+		"name": role.Name,
+	}, client)
+
+	return nil
 }
 
-func (s *AuthorizationService) AssignRoleToUser(userID, roleID string) error {
-	user, err := s.userRepo.FindByID(uuid.MustParse(userID))
+func (s *AuthorizationService) ListPermissions(ctx context.Context) ([]*models.Permission, error) {
+	return s.permissionRepo.FindAll(ctx)
+}
+
+func (s *AuthorizationService) GetPermission(ctx context.Context, permissionID string) (*models.Permission, error) {
+	id, err := parseID(permissionID)
 	if err != nil {
-		return errors.New("user not found")
+		return nil, err
 	}
 
-	role, err := s.roleRepo.FindByID(uuid.MustParse(roleID))
+	permission, err := s.permissionRepo.FindByID(ctx, id)
 	if err != nil {
-		return errors.New("role not found")
+		return nil, ErrPermissionNotFound
+	}
+	return permission, nil
+}
+
+func (s *AuthorizationService) CreatePermission(ctx context.Context, actor *models.User, input PermissionInput, client ClientInfo) (*models.Permission, error) {
+	permission := &models.Permission{}
+	if err := s.applyPermissionInput(ctx, permission, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.permissionRepo.Create(ctx, permission); err != nil {
+		return nil, err
 	}
 
-	user.Roles = append(user.Roles, *role)
-	return s.userRepo.Update(user)
+	s.recordChange(ctx, actor, AuditActionPermissionCreate, AuditResourcePermission, permission.ID, map[string]interface{}{
+		"name": permission.Name,
+	}, client)
+
+	return permission, nil
 }
 
-func (s *AuthorizationService) CheckUserPermission(userID, permissionName string) (bool, error) {
-	user, err := s.userRepo.FindByID(uuid.MustParse(userID))
+// UpdatePermission changes a permission. Renaming it changes what every
+// role holding it grants, so the actor must hold both the old and the new
+// name.
+func (s *AuthorizationService) UpdatePermission(ctx context.Context, actor *models.User, permissionID string, input PermissionInput, client ClientInfo) (*models.Permission, error) {
+	permission, err := s.GetPermission(ctx, permissionID)
 	if err != nil {
-		return false, errors.New("user not found")
+		return nil, err
 	}
 
+	oldName := permission.Name
+	if err := s.applyPermissionInput(ctx, permission, input); err != nil {
+		return nil, err
+	}
+	if permission.Name != oldName {
+		held, err := s.UserPermissions(ctx, actor)
+		if err != nil {
+			return nil, err
+		}
+		if !held.Has(oldName) || !held.Has(permission.Name) {
+			return nil, ErrPermissionNotHeld
+		}
+	}
+
+	if err := s.permissionRepo.Update(ctx, permission); err != nil {
+		return nil, err
+	}
+	if err := s.securityStamps.RotateTenant(ctx, permission.TenantID); err != nil {
+		return nil, err
+	}
+
+	s.recordChange(ctx, actor, AuditActionPermissionUpdate, AuditResourcePermission, permission.ID, map[string]interface{}{
+		"name":        permission.Name,
+		"description": permission.Description,
+	}, client)
+
+	return permission, nil
+}
+
+func (s *AuthorizationService) DeletePermission(ctx context.Context, actor *models.User, permissionID string, client ClientInfo) error {
+	permission, err := s.GetPermission(ctx, permissionID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.permissionRepo.Delete(ctx, permission.ID); err != nil {
+		return err
+	}
+	if err := s.securityStamps.RotateTenant(ctx, permission.TenantID); err != nil {
+		return err
+	}
+
+	s.recordChange(ctx, actor, AuditActionPermissionDelete, AuditResourcePermission, permission.ID, map[string]interface{}{
+		"name": permission.Name,
+	}, client)
+
+	return nil
+}
+
+// AssignRoleToUser grants a role to a user. The actor must hold every
+// permission the role grants, including those it inherits.
+func (s *AuthorizationService) AssignRoleToUser(ctx context.Context, actor *models.User, userID, roleID string, client ClientInfo) error {
+	user, role, err := s.findUserAndRole(ctx, userID, roleID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkGrantable(ctx, actor, role); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.AddRole(ctx, user, role); err != nil {
+		return err
+	}
+	if err := s.securityStamps.Rotate(ctx, user); err != nil {
+		return err
+	}
+
+	s.recordChange(ctx, actor, AuditActionRoleAssign, AuditResourceUser, user.ID, map[string]interface{}{
+		"role_id":   role.ID,
+		"role_name": role.Name,
+	}, client)
+
+	return nil
+}
+
+func (s *AuthorizationService) UnassignRoleFromUser(ctx context.Context, actor *models.User, userID, roleID string, client ClientInfo) error {
+	user, role, err := s.findUserAndRole(ctx, userID, roleID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.RemoveRole(ctx, user, role); err != nil {
+		return err
+	}
+	if err := s.securityStamps.Rotate(ctx, user); err != nil {
+		return err
+	}
+
+	s.recordChange(ctx, actor, AuditActionRoleUnassign, AuditResourceUser, user.ID, map[string]interface{}{
+		"role_id":   role.ID,
+		"role_name": role.Name,
+	}, client)
+
+	return nil
+}
+
+// SyncRoles makes the roles among managed that user holds exactly those in
+// granted, leaving the user's other roles alone, and returns the roles it
+// added and removed. Role IDs that do not name a role are ignored. The
+// user's security stamp is rotated if anything changed; callers audit the
+// change.
+func (s *AuthorizationService) SyncRoles(ctx context.Context, user *models.User, managed, granted []uuid.UUID) ([]*models.Role, []*models.Role, error) {
+	held := make(map[uuid.UUID]bool, len(user.Roles))
 	for _, role := range user.Roles {
-		for _, permission := range role.Permissions {
-			if permission.Name == permissionName {
-				return true, nil
+		held[role.ID] = true
+	}
+	wanted := make(map[uuid.UUID]bool, len(granted))
+	for _, id := range granted {
+		wanted[id] = true
+	}
+
+	var added, removed []*models.Role
+	seen := make(map[uuid.UUID]bool, len(managed))
+	for _, id := range managed {
+		if seen[id] || held[id] == wanted[id] {
+			continue
+		}
+		seen[id] = true
+
+		role, err := s.roleRepo.FindByID(ctx, id)
+		if err != nil {
+			continue
+		}
+		if wanted[id] {
+			if err := s.userRepo.AddRole(ctx, user, role); err != nil {
+				return nil, nil, err
 			}
+			added = append(added, role)
+		} else {
+			if err := s.userRepo.RemoveRole(ctx, user, role); err != nil {
+				return nil, nil, err
+			}
+			removed = append(removed, role)
+		}
+	}
+
+	if len(added) > 0 || len(removed) > 0 {
+		if err := s.securityStamps.Rotate(ctx, user); err != nil {
+			return nil, nil, err
+		}
+	}
+	return added, removed, nil
+}
+
+// RoleSync is a change to the roles of a user that an identity provider
+// maps from its groups: of the Managed roles, the user is to hold exactly
+// those Granted. Federated sign-ins of MFA-enabled users carry it in their
+// temporary token until the second factor is verified.
+type RoleSync struct {
+	ProviderID uuid.UUID   `json:"provider_id"`
+	Managed    []uuid.UUID `json:"managed"`
+	Granted    []uuid.UUID `json:"granted"`
+}
+
+// ApplyRoleSync applies sync to user with SyncRoles and audits the roles
+// it added and removed. A nil sync changes nothing.
+func (s *AuthorizationService) ApplyRoleSync(ctx context.Context, user *models.User, sync *RoleSync, client ClientInfo) error {
+	if sync == nil {
+		return nil
+	}
+
+	added, removed, err := s.SyncRoles(ctx, user, sync.Managed, sync.Granted)
+	if err != nil {
+		return err
+	}
+
+	record := func(action string, role *models.Role) {
+		s.auditService.RecordUserAction(ctx, user, action, map[string]interface{}{
+			"role_id":              role.ID,
+			"role_name":            role.Name,
+			"identity_provider_id": sync.ProviderID,
+		}, client)
+	}
+	for _, role := range added {
+		record(AuditActionRoleAssign, role)
+	}
+	for _, role := range removed {
+		record(AuditActionRoleUnassign, role)
+	}
+	return nil
+}
+
+// AssignPermissionToRole adds a permission to a role, which the actor must
+// hold, as it is granted to everyone holding the role.
+func (s *AuthorizationService) AssignPermissionToRole(ctx context.Context, actor *models.User, roleID, permissionID string, client ClientInfo) error {
+	role, permission, err := s.findRoleAndPermission(ctx, roleID, permissionID)
+	if err != nil {
+		return err
+	}
+	held, err := s.UserPermissions(ctx, actor)
+	if err != nil {
+		return err
+	}
+	if !held.Has(permission.Name) {
+		return ErrPermissionNotHeld
+	}
+
+	if err := s.roleRepo.AddPermission(ctx, role, permission); err != nil {
+		return err
+	}
+	if err := s.securityStamps.RotateTenant(ctx, role.TenantID); err != nil {
+		return err
+	}
+
+	s.recordChange(ctx, actor, AuditActionPermissionAssign, AuditResourceRole, role.ID, map[string]interface{}{
+		"permission_id":   permission.ID,
+		"permission_name": permission.Name,
+	}, client)
+
+	return nil
+}
+
+func (s *AuthorizationService) UnassignPermissionFromRole(ctx context.Context, actor *models.User, roleID, permissionID string, client ClientInfo) error {
+	role, permission, err := s.findRoleAndPermission(ctx, roleID, permissionID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.roleRepo.RemovePermission(ctx, role, permission); err != nil {
+		return err
+	}
+	if err := s.securityStamps.RotateTenant(ctx, role.TenantID); err != nil {
+		return err
+	}
+
+	s.recordChange(ctx, actor, AuditActionPermissionUnassign, AuditResourceRole, role.ID, map[string]interface{}{
+		"permission_id":   permission.ID,
+		"permission_name": permission.Name,
+	}, client)
+
+	return nil
+}
+
+func (s *AuthorizationService) CheckUserPermission(ctx context.Context, userID, permissionName string) (bool, error) {
+	id, err := parseID(userID)
+	if err != nil {
+		return false, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return false, ErrUserNotFound
+	}
+
+	permissions, err := s.UserPermissions(ctx, user)
+	if err != nil {
+		return false, err
+	}
+
+	return permissions.Has(permissionName), nil
+}
+
+// UserPermissions returns the permissions of a user whose roles are already
+// loaded, such as the user stored in the request context by AuthMiddleware,
+// including those inherited from parent roles.
+func (s *AuthorizationService) UserPermissions(ctx context.Context, user *models.User) (PermissionSet, error) {
+	grants, err := s.resolveGrants(ctx, user)
+	if err != nil {
+		return PermissionSet{}, err
+	}
+
+	set := PermissionSet{names: make(map[string]bool, len(grants))}
+	for _, grant := range grants {
+		set.add(grant.Permission)
+	}
+	return set, nil
+}
+
+// ScopePermissions returns the permissions the OAuth scopes grant a client
+// in the tenant carried by ctx. Scopes that are not registered, such as
+// offline_access, grant none.
+func (s *AuthorizationService) ScopePermissions(ctx context.Context, scopes []string) (PermissionSet, error) {
+	registered, err := s.oauthScopeRepo.FindByNames(ctx, scopes)
+	if err != nil {
+		return PermissionSet{}, err
+	}
+
+	set := PermissionSet{names: make(map[string]bool)}
+	for _, scope := range registered {
+		var permissions []string
+		if err := json.Unmarshal([]byte(scope.Permissions), &permissions); err != nil {
+			return PermissionSet{}, fmt.Errorf("invalid permissions of OAuth scope %s: %v", scope.Name, err)
 		}
+		for _, permission := range permissions {
+			set.add(permission)
+		}
+	}
+	return set, nil
+}
+
+// EffectivePermissions returns every permission grant of the user with the
+// given ID, so administrators can see which role a permission comes from.
+func (s *AuthorizationService) EffectivePermissions(ctx context.Context, userID string) (*models.User, []PermissionGrant, error) {
+	id, err := parseID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, ErrUserNotFound
 	}
 
-	return false, nil
+	grants, err := s.resolveGrants(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, grants, nil
 }
 
-func (s *AuthorizationService) CreateRole(role *models.Role) error {
-	return s.roleRepo.Create(role)
+// resolveGrants walks each of the user's roles up its parent chain and
+// collects the permissions assigned along the way. The Admin role grants
+// every permission. Chains are cut at the first repeated role, so a cycle
+// that predates cycle detection cannot loop forever.
+func (s *AuthorizationService) resolveGrants(ctx context.Context, user *models.User) ([]PermissionGrant, error) {
+	var grants []PermissionGrant
+	if user.IsSuperAdmin {
+		grants = append(grants, PermissionGrant{Permission: wildcardPermission})
+	}
+	if len(user.Roles) == 0 {
+		return grants, nil
+	}
+
+	byID, err := s.rolesByID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range user.Roles {
+		grants = appendRoleGrants(grants, byID, byID[user.Roles[i].ID])
+	}
+
+	return grants, nil
 }
 
-func (s *AuthorizationService) CreatePermission(permission *models.Permission) error {
-	return s.permissionRepo.Create(permission)
+func (s *AuthorizationService) rolesByID(ctx context.Context) (map[uuid.UUID]*models.Role, error) {
+	roles, err := s.roleRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*models.Role, len(roles))
+	for _, role := range roles {
+		byID[role.ID] = role
+	}
+	return byID, nil
 }
 
-func (s *AuthorizationService) AssignPermissionToRole(roleID, permissionID string) error {
-	role, err := s.roleRepo.FindByID(uuid.MustParse(roleID))
+// appendRoleGrants appends the grants of assigned and its ancestors to
+// grants.
+func appendRoleGrants(grants []PermissionGrant, byID map[uuid.UUID]*models.Role, assigned *models.Role) []PermissionGrant {
+	visited := make(map[uuid.UUID]bool)
+	for role := assigned; role != nil && !visited[role.ID]; role = parentRole(byID, role) {
+		visited[role.ID] = true
+
+		if role.IsAdmin {
+			grants = append(grants, PermissionGrant{Permission: wildcardPermission, Role: role, AssignedRole: assigned})
+		}
+		for _, permission := range role.Permissions {
+			grants = append(grants, PermissionGrant{Permission: permission.Name, Role: role, AssignedRole: assigned})
+		}
+	}
+	return grants
+}
+
+func parentRole(byID map[uuid.UUID]*models.Role, role *models.Role) *models.Role {
+	if role.ParentID == nil {
+		return nil
+	}
+	return byID[*role.ParentID]
+}
+
+// GrantableRole returns the role with ID roleID if actor may grant it, as
+// when mapping identity provider groups to it.
+func (s *AuthorizationService) GrantableRole(ctx context.Context, actor *models.User, roleID uuid.UUID) (*models.Role, error) {
+	role, err := s.roleRepo.FindByID(ctx, roleID)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+	if err := s.checkGrantable(ctx, actor, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// checkGrantable makes sure actor holds every permission role grants,
+// including those it inherits, so nobody can hand out more than they hold.
+// role is checked as given, so changes can be checked before they are
+// saved.
+func (s *AuthorizationService) checkGrantable(ctx context.Context, actor *models.User, role *models.Role) error {
+	held, err := s.UserPermissions(ctx, actor)
+	if err != nil {
+		return err
+	}
+	byID, err := s.rolesByID(ctx)
+	if err != nil {
+		return err
+	}
+	byID[role.ID] = role
+
+	for _, grant := range appendRoleGrants(nil, byID, role) {
+		if !held.Has(grant.Permission) {
+			return ErrPermissionNotHeld
+		}
+	}
+	return nil
+}
+
+func (s *AuthorizationService) findUserAndRole(ctx context.Context, userID, roleID string) (*models.User, *models.Role, error) {
+	id, err := parseID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, ErrUserNotFound
+	}
+
+	role, err := s.GetRole(ctx, roleID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, role, nil
+}
+
+func (s *AuthorizationService) findRoleAndPermission(ctx context.Context, roleID, permissionID string) (*models.Role, *models.Permission, error) {
+	role, err := s.GetRole(ctx, roleID)
 	if err != nil {
-		return errors.New("role not found")
+		return nil, nil, err
 	}
 
-	permission, err := s.permissionRepo.FindByID(uuid.MustParse(permissionID))
+	permission, err := s.GetPermission(ctx, permissionID)
 	if err != nil {
-		return errors.New("permission not found")
+		return nil, nil, err
 	}
 
-	role.Permissions = append(role.Permissions, *permission)
-	return s.roleRepo.Update(role)
+	return role, permission, nil
+}
+
+func (s *AuthorizationService) applyRoleInput(ctx context.Context, role *models.Role, input RoleInput) error {
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if !role.IsAdmin && strings.EqualFold(name, AdminRoleName) {
+			return ErrReservedRoleName
+		}
+		if existing, err := s.roleRepo.FindByName(ctx, name); err == nil && existing.ID != role.ID {
+			return ErrRoleNameTaken
+		}
+		role.Name = name
+	}
+	if input.Description != nil {
+		role.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.ParentID != nil {
+		if *input.ParentID == "" {
+			role.ParentID = nil
+			return nil
+		}
+
+		parentID, err := parseID(*input.ParentID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkParentRole(ctx, role, parentID); err != nil {
+			return err
+		}
+		role.ParentID = &parentID
+	}
+	return nil
+}
+
+// checkParentRole makes sure parentID exists and that making it the parent
+// of role does not close a loop, by walking up from the new parent.
+func (s *AuthorizationService) checkParentRole(ctx context.Context, role *models.Role, parentID uuid.UUID) error {
+	visited := make(map[uuid.UUID]bool)
+	for id := &parentID; id != nil; {
+		if *id == role.ID || visited[*id] {
+			return ErrRoleCycle
+		}
+		visited[*id] = true
+
+		ancestor, err := s.roleRepo.FindByID(ctx, *id)
+		if err != nil {
+			if *id == parentID {
+				return ErrInvalidParentRole
+			}
+			return nil
+		}
+		id = ancestor.ParentID
+	}
+	return nil
+}
+
+// applyPermissionInput applies input to permission. Wildcards are only
+// matched, never stored: a permission named users:* would hand every users
+// permission to the roles holding it.
+func (s *AuthorizationService) applyPermissionInput(ctx context.Context, permission *models.Permission, input PermissionInput) error {
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if strings.Contains(name, wildcardPermission) {
+			return ErrWildcardPermission
+		}
+		if !permissionNamePattern.MatchString(name) {
+			return ErrInvalidPermission
+		}
+		if existing, err := s.permissionRepo.FindByName(ctx, name); err == nil && existing.ID != permission.ID {
+			return ErrPermissionNameTaken
+		}
+		permission.Name = name
+	}
+	if input.Description != nil {
+		permission.Description = strings.TrimSpace(*input.Description)
+	}
+	return nil
+}
+
+func (s *AuthorizationService) recordChange(ctx context.Context, actor *models.User, action, resource string, resourceID uuid.UUID, details map[string]interface{}, client ClientInfo) {
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    actor.ID,
+		Action:     action,
+		Resource:   resource,
+		ResourceID: resourceID.String(),
+		Details:    details,
+		Client:     client,
+	})
+}
+
+func parseID(value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, ErrInvalidID
+	}
+	return id, nil
 }
@@ -0,0 +1,149 @@
+package user_management
+
+import (
+	"testing"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+)
+
+func TestPermissionsCannotBeNamedWithWildcards(t *testing.T) {
+	st := newServiceTest(t)
+	admin := &models.User{IsSuperAdmin: true}
+
+	for _, name := range []string{"*", "users:*", "*:read", "*:*"} {
+		if _, err := st.authz.CreatePermission(st.ctx, admin, PermissionInput{Name: &name}, ClientInfo{}); err != ErrWildcardPermission {
+			t.Errorf("creating permission %q returned %v, want ErrWildcardPermission", name, err)
+		}
+	}
+
+	permission := st.permission(t, "users:read")
+	for _, name := range []string{"*", "users:*"} {
+		if _, err := st.authz.UpdatePermission(st.ctx, admin, permission.ID.String(), PermissionInput{Name: &name}, ClientInfo{}); err != ErrWildcardPermission {
+			t.Errorf("renaming a permission to %q returned %v, want ErrWildcardPermission", name, err)
+		}
+	}
+}
+
+func TestUpdatePermissionRequiresHoldingOldAndNewName(t *testing.T) {
+	st := newServiceTest(t)
+
+	actor := st.createUser(t, "pat@acme.test", "Secret-pass-1")
+	actor = st.assignRole(t, actor, st.createRole(t, "permission-editor", "permissions:write", "users:read"))
+	held := st.permission(t, "users:read")
+	notHeld := st.permission(t, "billing:read")
+
+	rename := func(permission *models.Permission, name string) error {
+		_, err := st.authz.UpdatePermission(st.ctx, actor, permission.ID.String(), PermissionInput{Name: &name}, ClientInfo{})
+		return err
+	}
+	if err := rename(held, "tenants:write"); err != ErrPermissionNotHeld {
+		t.Fatalf("renaming a held permission to one not held returned %v, want ErrPermissionNotHeld", err)
+	}
+	if err := rename(notHeld, "billing:list"); err != ErrPermissionNotHeld {
+		t.Fatalf("renaming a permission not held returned %v, want ErrPermissionNotHeld", err)
+	}
+	if stored, err := st.permissionRepo.FindByID(st.ctx, held.ID); err != nil || stored.Name != "users:read" {
+		t.Fatalf("refused rename was stored: %v, %v", stored, err)
+	}
+
+	description := "Read users"
+	if _, err := st.authz.UpdatePermission(st.ctx, actor, held.ID.String(), PermissionInput{Description: &description}, ClientInfo{}); err != nil {
+		t.Fatalf("changing the description failed: %v", err)
+	}
+	if err := rename(held, "users:read"); err != nil {
+		t.Fatalf("keeping the name failed: %v", err)
+	}
+
+	admin := &models.User{IsSuperAdmin: true}
+	name := "tenants:write"
+	if _, err := st.authz.UpdatePermission(st.ctx, admin, held.ID.String(), PermissionInput{Name: &name}, ClientInfo{}); err != nil {
+		t.Fatalf("rename by a super admin failed: %v", err)
+	}
+}
+
+func TestOnlyTheFlaggedAdminRoleHoldsEveryPermission(t *testing.T) {
+	st := newServiceTest(t)
+
+	impostor := st.createUser(t, "ivy@acme.test", "Secret-pass-1")
+	impostor = st.assignRole(t, impostor, st.createRole(t, AdminRoleName))
+	if permissions, err := st.authz.UserPermissions(st.ctx, impostor); err != nil || permissions.Has("users:write") {
+		t.Fatalf("a role merely named Admin granted users:write (err %v)", err)
+	}
+
+	admin := &models.Role{Name: "Administrators", IsAdmin: true}
+	if err := st.roleRepo.Create(st.ctx, admin); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	user := st.assignRole(t, st.createUser(t, "jay@acme.test", "Secret-pass-1"), admin)
+	if permissions, err := st.authz.UserPermissions(st.ctx, user); err != nil || !permissions.Has("users:write") {
+		t.Fatalf("the flagged Admin role did not grant users:write (err %v)", err)
+	}
+}
+
+func TestRoleNameAdminIsReserved(t *testing.T) {
+	st := newServiceTest(t)
+	actor := &models.User{IsSuperAdmin: true}
+
+	for _, name := range []string{"Admin", "admin", " ADMIN "} {
+		if _, err := st.authz.CreateRole(st.ctx, actor, RoleInput{Name: &name}, ClientInfo{}); err != ErrReservedRoleName {
+			t.Errorf("creating role %q returned %v, want ErrReservedRoleName", name, err)
+		}
+	}
+
+	support := st.createRole(t, "support")
+	name := AdminRoleName
+	if _, err := st.authz.UpdateRole(st.ctx, actor, support.ID.String(), RoleInput{Name: &name}, ClientInfo{}); err != ErrReservedRoleName {
+		t.Fatalf("renaming a role to Admin returned %v, want ErrReservedRoleName", err)
+	}
+
+	admin := &models.Role{Name: AdminRoleName, IsAdmin: true}
+	if err := st.roleRepo.Create(st.ctx, admin); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	renamed := "Owners"
+	if _, err := st.authz.UpdateRole(st.ctx, actor, admin.ID.String(), RoleInput{Name: &renamed}, ClientInfo{}); err != ErrProtectedRole {
+		t.Fatalf("renaming the Admin role returned %v, want ErrProtectedRole", err)
+	}
+	if err := st.authz.DeleteRole(st.ctx, actor, admin.ID.String(), ClientInfo{}); err != ErrProtectedRole {
+		t.Fatalf("deleting the Admin role returned %v, want ErrProtectedRole", err)
+	}
+}
+
+func TestRoleChangesCannotGrantBeyondActor(t *testing.T) {
+	st := newServiceTest(t)
+
+	actor := st.createUser(t, "kim@acme.test", "Secret-pass-1")
+	actor = st.assignRole(t, actor, st.createRole(t, "role-editor", "roles:write", "users:read"))
+	helpers := st.createRole(t, "helpers", "users:read")
+	operators := st.createRole(t, "operators", "tenants:write")
+	admin := &models.Role{Name: AdminRoleName, IsAdmin: true}
+	if err := st.roleRepo.Create(st.ctx, admin); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	for _, parent := range []*models.Role{operators, admin} {
+		name, parentID := "escalated-"+parent.Name, parent.ID.String()
+		if _, err := st.authz.CreateRole(st.ctx, actor, RoleInput{Name: &name, ParentID: &parentID}, ClientInfo{}); err != ErrPermissionNotHeld {
+			t.Errorf("creating a role inheriting from %s returned %v, want ErrPermissionNotHeld", parent.Name, err)
+		}
+		if _, err := st.authz.UpdateRole(st.ctx, actor, helpers.ID.String(), RoleInput{ParentID: &parentID}, ClientInfo{}); err != ErrPermissionNotHeld {
+			t.Errorf("making %s the parent returned %v, want ErrPermissionNotHeld", parent.Name, err)
+		}
+	}
+	if stored, err := st.roleRepo.FindByID(st.ctx, helpers.ID); err != nil || stored.ParentID != nil {
+		t.Fatalf("refused parent was stored: %v, %v", stored, err)
+	}
+
+	description := "Operations"
+	if _, err := st.authz.UpdateRole(st.ctx, actor, operators.ID.String(), RoleInput{Description: &description}, ClientInfo{}); err != ErrPermissionNotHeld {
+		t.Fatalf("editing a role granting more than the actor holds returned %v, want ErrPermissionNotHeld", err)
+	}
+	if _, err := st.authz.UpdateRole(st.ctx, actor, helpers.ID.String(), RoleInput{Description: &description}, ClientInfo{}); err != nil {
+		t.Fatalf("editing a grantable role failed: %v", err)
+	}
+
+	name, parentID := "junior-helpers", helpers.ID.String()
+	if _, err := st.authz.CreateRole(st.ctx, actor, RoleInput{Name: &name, ParentID: &parentID}, ClientInfo{}); err != nil {
+		t.Fatalf("creating a role inheriting grantable permissions failed: %v", err)
+	}
+}
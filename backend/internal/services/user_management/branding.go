@@ -0,0 +1,195 @@
+package user_management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+var ErrInvalidBranding = errors.New("invalid branding")
+
+const (
+	defaultProductName   = "AdminSuite"
+	maxProductNameLength = 100
+	maxLogoURLLength     = 2048
+	maxEmailFooterLength = 1000
+	maxTOTPIssuerLength  = 100
+)
+
+var hexColorPattern = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Branding is the typed form of Tenant.BrandingConfig. Fields that are
+// missing from the stored JSON keep the values from DefaultBranding.
+type Branding struct {
+	ProductName    string `json:"product_name"`
+	LogoURL        string `json:"logo_url"`
+	PrimaryColor   string `json:"primary_color"`
+	SecondaryColor string `json:"secondary_color"`
+	SupportEmail   string `json:"support_email"`
+	EmailFooter    string `json:"email_footer"`
+	// TOTPIssuer is the account issuer shown in authenticator apps. It
+	// falls back to ProductName when empty.
+	TOTPIssuer string `json:"totp_issuer"`
+}
+
+func DefaultBranding() *Branding {
+	return &Branding{
+		ProductName:    defaultProductName,
+		PrimaryColor:   "#1f2937",
+		SecondaryColor: "#3b82f6",
+	}
+}
+
+// Validate trims the branding in place and reports the first setting that is
+// malformed. The returned error wraps ErrInvalidBranding.
+func (b *Branding) Validate() error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidBranding, fmt.Sprintf(format, args...))
+	}
+
+	b.ProductName = strings.TrimSpace(b.ProductName)
+	b.LogoURL = strings.TrimSpace(b.LogoURL)
+	b.PrimaryColor = strings.TrimSpace(b.PrimaryColor)
+	b.SecondaryColor = strings.TrimSpace(b.SecondaryColor)
+	b.SupportEmail = strings.TrimSpace(b.SupportEmail)
+	b.EmailFooter = strings.TrimSpace(b.EmailFooter)
+	b.TOTPIssuer = strings.TrimSpace(b.TOTPIssuer)
+
+	if b.ProductName == "" || len(b.ProductName) > maxProductNameLength {
+		return invalid("product_name must be between 1 and %d characters", maxProductNameLength)
+	}
+	if strings.ContainsAny(b.ProductName, "\r\n") {
+		return invalid("product_name must be a single line")
+	}
+
+	if b.LogoURL != "" {
+		logoURL, err := url.Parse(b.LogoURL)
+		if err != nil || len(b.LogoURL) > maxLogoURLLength || (logoURL.Scheme != "https" && logoURL.Scheme != "http") || logoURL.Host == "" {
+			return invalid("logo_url must be an absolute http(s) URL")
+		}
+	}
+
+	if b.PrimaryColor != "" && !hexColorPattern.MatchString(b.PrimaryColor) {
+		return invalid("primary_color must be a hex color such as #1f2937")
+	}
+	if b.SecondaryColor != "" && !hexColorPattern.MatchString(b.SecondaryColor) {
+		return invalid("secondary_color must be a hex color such as #3b82f6")
+	}
+
+	if b.SupportEmail != "" {
+		address, err := mail.ParseAddress(b.SupportEmail)
+		if err != nil || address.Address != b.SupportEmail {
+			return invalid("support_email must be a plain email address")
+		}
+	}
+
+	if len(b.EmailFooter) > maxEmailFooterLength {
+		return invalid("email_footer must be at most %d characters", maxEmailFooterLength)
+	}
+
+	// Authenticator apps split "issuer:account" labels on the colon.
+	if len(b.TOTPIssuer) > maxTOTPIssuerLength || strings.Contains(b.TOTPIssuer, ":") {
+		return invalid("totp_issuer must be at most %d characters and must not contain ':'", maxTOTPIssuerLength)
+	}
+
+	return nil
+}
+
+func (b *Branding) Issuer() string {
+	if b.TOTPIssuer != "" {
+		return b.TOTPIssuer
+	}
+	return b.ProductName
+}
+
+type BrandingService struct {
+	tenantRepo   user_management.TenantRepository
+	auditService *AuditService
+}
+
+func NewBrandingService(tenantRepo user_management.TenantRepository, auditService *AuditService) *BrandingService {
+	return &BrandingService{
+		tenantRepo:   tenantRepo,
+		auditService: auditService,
+	}
+}
+
+func (s *BrandingService) GetBranding(ctx context.Context, tenantID uuid.UUID) (*Branding, error) {
+	if tenantID == uuid.Nil {
+		return DefaultBranding(), nil
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tenant: %v", err)
+	}
+
+	return decodeBranding(tenant)
+}
+
+// GetBrandingByDomain returns the branding of the active tenant served on
+// domain. It backs the public endpoint the frontend calls before login.
+func (s *BrandingService) GetBrandingByDomain(ctx context.Context, domain string) (*Branding, error) {
+	tenant, err := s.tenantRepo.FindByDomain(ctx, normalizeDomain(domain))
+	if err != nil || !tenant.IsActive {
+		return nil, ErrTenantNotFound
+	}
+
+	return decodeBranding(tenant)
+}
+
+// UpdateBranding validates branding and stores it as the tenant's branding.
+func (s *BrandingService) UpdateBranding(ctx context.Context, actor *models.User, tenantID uuid.UUID, branding *Branding, client ClientInfo) error {
+	if err := branding.Validate(); err != nil {
+		return err
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return ErrTenantNotFound
+	}
+
+	encoded, err := json.Marshal(branding)
+	if err != nil {
+		return err
+	}
+
+	tenant.BrandingConfig = string(encoded)
+	if err := s.tenantRepo.Update(ctx, tenant); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    actor.ID,
+		Action:     AuditActionBrandingUpdate,
+		Resource:   AuditResourceTenant,
+		ResourceID: tenant.ID.String(),
+		Details:    map[string]interface{}{"branding": branding},
+		Client:     client,
+	})
+
+	return nil
+}
+
+func decodeBranding(tenant *models.Tenant) (*Branding, error) {
+	branding := DefaultBranding()
+	if strings.TrimSpace(tenant.BrandingConfig) == "" {
+		return branding, nil
+	}
+
+	if err := json.Unmarshal([]byte(tenant.BrandingConfig), branding); err != nil {
+		return nil, fmt.Errorf("invalid branding config: %v", err)
+	}
+
+	return branding, nil
+}
@@ -0,0 +1,207 @@
+package user_management
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+var (
+	ErrDeviceNotFound         = errors.New("device not found")
+	ErrTrustedDevicesDisabled = errors.New("trusted devices are disabled by tenant policy")
+)
+
+const (
+	defaultDeviceType = "browser"
+	maxDeviceNameSize = 50
+	maxDeviceTypeSize = 20
+)
+
+type DeviceService struct {
+	deviceRepo     user_management.DeviceRepository
+	sessionService *SessionService
+	policyService  *AuthPolicyService
+	auditService   *AuditService
+}
+
+func NewDeviceService(
+	deviceRepo user_management.DeviceRepository,
+	sessionService *SessionService,
+	policyService *AuthPolicyService,
+	auditService *AuditService,
+) *DeviceService {
+	return &DeviceService{
+		deviceRepo:     deviceRepo,
+		sessionService: sessionService,
+		policyService:  policyService,
+		auditService:   auditService,
+	}
+}
+
+// TrustDevice registers the client as a trusted device for the period set
+// by the tenant policy and returns the device token the client must present
+// on later logins. Only a hash of the token is stored.
+func (s *DeviceService) TrustDevice(ctx context.Context, user *models.User, name, deviceType string, client ClientInfo) (string, *models.Device, error) {
+	policy, err := s.policyService.GetPolicy(ctx, user.TenantID)
+	if err != nil {
+		return "", nil, err
+	}
+	if policy.TrustedDeviceDays <= 0 {
+		return "", nil, ErrTrustedDevicesDisabled
+	}
+
+	rawBytes, err := generateRandomBytes(32)
+	if err != nil {
+		return "", nil, err
+	}
+	deviceToken := base64.RawURLEncoding.EncodeToString(rawBytes)
+
+	if name = strings.TrimSpace(name); name == "" {
+		name = client.UserAgent
+	}
+	if deviceType = strings.TrimSpace(deviceType); deviceType == "" {
+		deviceType = defaultDeviceType
+	}
+
+	now := time.Now()
+	device := &models.Device{
+		UserID:       user.ID,
+		Name:         truncate(name, maxDeviceNameSize),
+		Type:         truncate(deviceType, maxDeviceTypeSize),
+		TokenHash:    hashToken(deviceToken),
+		IP:           client.IP,
+		UserAgent:    truncate(client.UserAgent, maxUserAgentLength),
+		TrustedUntil: now.AddDate(0, 0, policy.TrustedDeviceDays),
+		LastUsedAt:   now,
+	}
+	if err := s.deviceRepo.Create(ctx, device); err != nil {
+		return "", nil, err
+	}
+
+	s.recordDeviceAction(ctx, user, AuditActionDeviceTrust, device, map[string]interface{}{
+		"name":          device.Name,
+		"trusted_until": device.TrustedUntil,
+	}, client)
+
+	return deviceToken, device, nil
+}
+
+// FindTrustedDevice returns the device identified by deviceToken if it
+// belongs to user and is still within its trust period. The period is also
+// checked against the tenant's current policy, so disabling trusted devices
+// or shortening the period applies to devices trusted before the change.
+func (s *DeviceService) FindTrustedDevice(ctx context.Context, user *models.User, deviceToken string, policy *AuthPolicy) (*models.Device, bool) {
+	if deviceToken == "" || policy.TrustedDeviceDays <= 0 {
+		return nil, false
+	}
+
+	device, err := s.deviceRepo.FindByTokenHash(ctx, hashToken(deviceToken))
+	if err != nil || device.UserID != user.ID {
+		return nil, false
+	}
+
+	now := time.Now()
+	if device.TrustedUntil.Before(now) || device.CreatedAt.AddDate(0, 0, policy.TrustedDeviceDays).Before(now) {
+		return nil, false
+	}
+
+	return device, true
+}
+
+func (s *DeviceService) TouchDevice(ctx context.Context, deviceID uuid.UUID) error {
+	return s.deviceRepo.UpdateLastUsedAt(ctx, deviceID, time.Now())
+}
+
+func (s *DeviceService) ListDevices(ctx context.Context, user *models.User) ([]*models.Device, error) {
+	return s.deviceRepo.FindByUserID(ctx, user.ID)
+}
+
+func (s *DeviceService) RenameDevice(ctx context.Context, user *models.User, deviceID, name string, client ClientInfo) (*models.Device, error) {
+	device, err := s.findOwnedDevice(ctx, user, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	previousName := device.Name
+	device.Name = truncate(strings.TrimSpace(name), maxDeviceNameSize)
+	if err := s.deviceRepo.Update(ctx, device); err != nil {
+		return nil, err
+	}
+
+	s.recordDeviceAction(ctx, user, AuditActionDeviceRename, device, map[string]interface{}{
+		"previous_name": previousName,
+		"name":          device.Name,
+	}, client)
+
+	return device, nil
+}
+
+// RevokeDevice removes a trusted device and signs out the sessions that
+// were started from it, revoking their access tokens as well.
+func (s *DeviceService) RevokeDevice(ctx context.Context, user *models.User, deviceID string, client ClientInfo) error {
+	device, err := s.findOwnedDevice(ctx, user, deviceID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.sessionService.EndDeviceSessions(ctx, device.ID); err != nil {
+		return err
+	}
+	if err := s.deviceRepo.Delete(ctx, device.ID); err != nil {
+		return err
+	}
+
+	s.recordDeviceAction(ctx, user, AuditActionDeviceRevoke, device, nil, client)
+
+	return nil
+}
+
+func (s *DeviceService) findOwnedDevice(ctx context.Context, user *models.User, deviceID string) (*models.Device, error) {
+	id, err := uuid.Parse(deviceID)
+	if err != nil {
+		return nil, ErrDeviceNotFound
+	}
+
+	device, err := s.deviceRepo.FindByID(ctx, id)
+	if err != nil || device.UserID != user.ID {
+		return nil, ErrDeviceNotFound
+	}
+
+	return device, nil
+}
+
+func (s *DeviceService) recordDeviceAction(ctx context.Context, user *models.User, action string, device *models.Device, details map[string]interface{}, client ClientInfo) {
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    user.ID,
+		Action:     action,
+		Resource:   AuditResourceDevice,
+		ResourceID: device.ID.String(),
+		Details:    details,
+		Client:     client,
+	})
+}
+
+// truncate shortens value to at most size characters, the unit column sizes
+// are given in, without splitting a multi-byte character.
+func truncate(value string, size int) string {
+	if utf8.RuneCountInString(value) <= size {
+		return value
+	}
+
+	runes := 0
+	for i := range value {
+		if runes == size {
+			return value[:i]
+		}
+		runes++
+	}
+	return value
+}
@@ -0,0 +1,60 @@
+package user_management
+
+import (
+	"fmt"
+	"net/mail"
+	"net/smtp"
+	"strings"
+
+	"github.com/josy-coder/adminsuite/internal/config"
+)
+
+type EmailService struct {
+	config *config.Config
+}
+
+func NewEmailService(config *config.Config) *EmailService {
+	return &EmailService{config: config}
+}
+
+func (s *EmailService) SendEmail(to, subject, body string) error {
+	return s.send(s.config.SMTPFrom, to, subject, body)
+}
+
+// SendBrandedEmail sends an email on behalf of a tenant: the sender is shown
+// under the tenant's product name and the body ends with its support
+// contact and footer.
+func (s *EmailService) SendBrandedEmail(branding *Branding, to, subject, body string) error {
+	from := (&mail.Address{Name: branding.ProductName, Address: s.config.SMTPFrom}).String()
+
+	var footer []string
+	if branding.SupportEmail != "" {
+		footer = append(footer, fmt.Sprintf("Need help? Contact %s.", branding.SupportEmail))
+	}
+	if branding.EmailFooter != "" {
+		footer = append(footer, strings.ReplaceAll(strings.ReplaceAll(branding.EmailFooter, "\r\n", "\n"), "\n", "\r\n"))
+	}
+	if len(footer) > 0 {
+		body += "\r\n\r\n-- \r\n" + strings.Join(footer, "\r\n\r\n")
+	}
+
+	return s.send(from, to, subject, body)
+}
+
+func (s *EmailService) send(from, to, subject, body string) error {
+	auth := smtp.PlainAuth("", s.config.SMTPUsername, s.config.SMTPPassword, s.config.SMTPHost)
+
+	msg := []byte(fmt.Sprintf("To: %s\r\n"+
+		"From: %s\r\n"+
+		"Subject: %s\r\n"+
+		"\r\n"+
+		"%s\r\n", to, from, subject, body))
+
+	err := smtp.SendMail(fmt.Sprintf("%s:%d", s.config.SMTPHost, s.config.SMTPPort),
+		auth,
+		s.config.SMTPFrom,
+		[]string{to},
+		msg)
+
+	return err
+}
@@ -0,0 +1,151 @@
+package user_management
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/o1egl/paseto"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+var (
+	ErrEmailNotVerified         = errors.New("email address not verified")
+	ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+)
+
+const (
+	emailVerificationAudience = "adminsuite-email-verification"
+	emailVerificationTTL      = 24 * time.Hour
+)
+
+type EmailVerificationService struct {
+	userRepo        user_management.UserRepository
+	emailService    *EmailService
+	brandingService *BrandingService
+	tokenSigner     *TokenSigner
+	frontendURL     string
+}
+
+func NewEmailVerificationService(
+	userRepo user_management.UserRepository,
+	emailService *EmailService,
+	brandingService *BrandingService,
+	tokenSigner *TokenSigner,
+	frontendURL string,
+) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo:        userRepo,
+		emailService:    emailService,
+		brandingService: brandingService,
+		tokenSigner:     tokenSigner,
+		frontendURL:     strings.TrimRight(frontendURL, "/"),
+	}
+}
+
+// SendVerificationEmail mails a signed, expiring verification link to the
+// user's current email address. The address is part of the signed claims so
+// a link stops working if the user changes their email before using it.
+func (s *EmailVerificationService) SendVerificationEmail(ctx context.Context, user *models.User) error {
+	if user.EmailVerified {
+		return nil
+	}
+
+	branding, err := s.brandingService.GetBranding(ctx, user.TenantID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	token := paseto.JSONToken{
+		Audience:   emailVerificationAudience,
+		Issuer:     "adminsuite-auth",
+		Jti:        uuid.New().String(),
+		Subject:    user.ID.String(),
+		IssuedAt:   now,
+		Expiration: now.Add(emailVerificationTTL),
+		NotBefore:  now,
+	}
+	token.Set("email", user.Email)
+
+	verificationToken, err := s.tokenSigner.Sign(token)
+	if err != nil {
+		return err
+	}
+
+	link := frontendLink(s.frontendURL, "/verify-email", user.TenantID, verificationToken)
+	body := fmt.Sprintf("Welcome to %s!\r\n\r\n"+
+		"Please confirm your email address within %d hours using the link below:\r\n%s", branding.ProductName, int(emailVerificationTTL.Hours()), link)
+
+	return s.emailService.SendBrandedEmail(branding, user.Email, fmt.Sprintf("Verify your %s email address", branding.ProductName), body)
+}
+
+// ResendVerificationEmail sends a fresh link for the account registered under
+// email in the tenant carried by ctx. Unknown and already verified addresses
+// are ignored and failures only logged, so callers cannot tell from the
+// outcome whether an account exists.
+func (s *EmailVerificationService) ResendVerificationEmail(ctx context.Context, email string) {
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return
+	}
+
+	if err := s.SendVerificationEmail(ctx, user); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+	}
+}
+
+// frontendLink returns the link to path of the frontend that carries token.
+// Users of every tenant share the frontend, so the link names the user's
+// tenant in the tenant parameter, which the frontend sends back as the
+// X-Tenant header when it redeems the token.
+func frontendLink(frontendURL, path string, tenantID uuid.UUID, token string) string {
+	query := url.Values{"token": {token}}
+	if tenantID != uuid.Nil {
+		query.Set("tenant", tenantID.String())
+	}
+	return frontendURL + path + "?" + query.Encode()
+}
+
+func (s *EmailVerificationService) VerifyEmail(ctx context.Context, verificationToken string) (*models.User, error) {
+	var token paseto.JSONToken
+	if err := s.tokenSigner.Verify(verificationToken, &token); err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	if err := token.Validate(paseto.ForAudience(emailVerificationAudience), paseto.ValidAt(time.Now())); err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	userID, err := uuid.Parse(token.Subject)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	if !strings.EqualFold(user.Email, token.Get("email")) {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	if user.EmailVerified {
+		return user, nil
+	}
+
+	user.EmailVerified = true
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,531 @@
+package user_management
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+	"github.com/josy-coder/adminsuite/internal/tenancy"
+)
+
+const (
+	federatedLoginStateTTL = 10 * time.Minute
+	// federatedNonceSize and codeVerifierSize are the random bytes in the
+	// nonce and PKCE code verifier of a federated sign-in.
+	federatedNonceSize = 32
+	codeVerifierSize   = 32
+	// maxUsernameAttempts bounds the suffixes tried when the username of a
+	// provisioned user is already taken.
+	maxUsernameAttempts = 5
+)
+
+var (
+	ErrInvalidFederatedState     = errors.New("federated login state is invalid or expired")
+	ErrFederatedEmailNotVerified = errors.New("identity provider did not report a verified email address")
+	ErrNoAvailableUsername       = errors.New("no available username could be derived for the user")
+	ErrFederatedLinkPending      = errors.New("a user with this email address already exists and must link the identity provider account while signed in")
+	ErrFederatedIdentityNotFound = errors.New("federated identity not found")
+)
+
+// federatedAccount is the account at an identity provider a user signs in
+// with, as the provider described it. RoleMappings are applied to Groups
+// if the provider reports groups.
+type federatedAccount struct {
+	ProviderID    uuid.UUID
+	ProviderName  string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string
+	FirstName     string
+	LastName      string
+	Groups        []string
+	RoleMappings  []GroupRoleMapping
+}
+
+// FederationService signs users in through the external OpenID Connect
+// providers of their tenant with the authorization code flow and PKCE.
+// Users are found by their link to the provider account; unknown provider
+// accounts provision a user, or else request a link that the existing user
+// with the same email address confirms. The roles mapped from the
+// provider's groups are synchronized on every sign-in.
+type FederationService struct {
+	providerRepo         user_management.IdentityProviderRepository
+	identityRepo         user_management.FederatedIdentityRepository
+	stateRepo            user_management.FederatedLoginStateRepository
+	userRepo             user_management.UserRepository
+	authService          *AuthenticationService
+	authorizationService *AuthorizationService
+	policyService        *AuthPolicyService
+	idpClient            *IdentityProviderClient
+	tokenHasher          *TokenHasher
+	auditService         *AuditService
+	frontendURL          string
+}
+
+func NewFederationService(
+	providerRepo user_management.IdentityProviderRepository,
+	identityRepo user_management.FederatedIdentityRepository,
+	stateRepo user_management.FederatedLoginStateRepository,
+	userRepo user_management.UserRepository,
+	authService *AuthenticationService,
+	authorizationService *AuthorizationService,
+	policyService *AuthPolicyService,
+	idpClient *IdentityProviderClient,
+	tokenHasher *TokenHasher,
+	auditService *AuditService,
+	frontendURL string,
+) *FederationService {
+	return &FederationService{
+		providerRepo:         providerRepo,
+		identityRepo:         identityRepo,
+		stateRepo:            stateRepo,
+		userRepo:             userRepo,
+		authService:          authService,
+		authorizationService: authorizationService,
+		policyService:        policyService,
+		idpClient:            idpClient,
+		tokenHasher:          tokenHasher,
+		auditService:         auditService,
+		frontendURL:          strings.TrimSuffix(frontendURL, "/"),
+	}
+}
+
+// RedirectURI is where providers send users back to. The frontend page
+// there posts the code and state to the callback endpoint.
+func (s *FederationService) RedirectURI() string {
+	return s.frontendURL + "/auth/federated/callback"
+}
+
+// StartLogin begins a sign-in through the provider with ID providerID and
+// returns the provider URL to send the user agent to.
+func (s *FederationService) StartLogin(ctx context.Context, providerID string) (string, error) {
+	provider, err := s.findEnabledProvider(ctx, providerID)
+	if err != nil {
+		return "", err
+	}
+
+	endpoints, err := s.idpClient.Discover(ctx, provider.Issuer)
+	if err != nil {
+		return "", err
+	}
+	authorizationURL, err := url.Parse(endpoints.AuthorizationEndpoint)
+	if err != nil {
+		return "", ErrIdentityProviderUnavailable
+	}
+
+	state, stateHash, err := s.tokenHasher.NewToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := generateRandomBytes(federatedNonceSize)
+	if err != nil {
+		return "", err
+	}
+	verifier, err := generateRandomBytes(codeVerifierSize)
+	if err != nil {
+		return "", err
+	}
+
+	loginState := &models.FederatedLoginState{
+		IdentityProviderID: provider.ID,
+		StateHash:          stateHash,
+		Nonce:              base64.RawURLEncoding.EncodeToString(nonce),
+		CodeVerifier:       base64.RawURLEncoding.EncodeToString(verifier),
+		ExpiresAt:          time.Now().Add(federatedLoginStateTTL),
+	}
+	if err := s.stateRepo.Create(ctx, loginState); err != nil {
+		return "", err
+	}
+	// Sign-ins that were started but never completed are cleared out here,
+	// as no other path would remove them.
+	if err := s.stateRepo.DeleteExpired(ctx); err != nil {
+		log.Printf("Failed to delete expired federated login states: %v", err)
+	}
+
+	challenge := sha256.Sum256([]byte(loginState.CodeVerifier))
+	query := authorizationURL.Query()
+	query.Set("response_type", ResponseTypeCode)
+	query.Set("client_id", provider.ClientID)
+	query.Set("redirect_uri", s.RedirectURI())
+	query.Set("scope", provider.Scopes)
+	query.Set("state", state)
+	query.Set("nonce", loginState.Nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", CodeChallengeMethodS256)
+	authorizationURL.RawQuery = query.Encode()
+
+	return authorizationURL.String(), nil
+}
+
+// CompleteLogin finishes the sign-in state belongs to by redeeming code at
+// the provider. Each state can be used once. For MFA-enabled accounts, it
+// returns ErrMFARequired with the user and the temporary token that
+// completes the sign-in in place of the access token; otherwise it returns
+// the user with a new token pair.
+func (s *FederationService) CompleteLogin(ctx context.Context, code, state string, client ClientInfo) (*models.User, string, string, error) {
+	loginState, err := s.stateRepo.Consume(ctx, s.tokenHasher.Hash(state))
+	if err != nil || time.Now().After(loginState.ExpiresAt) {
+		return nil, "", "", ErrInvalidFederatedState
+	}
+
+	provider, err := s.providerRepo.FindByID(ctx, loginState.IdentityProviderID)
+	if err != nil || !provider.Enabled {
+		return nil, "", "", ErrIdentityProviderNotFound
+	}
+
+	endpoints, err := s.idpClient.Discover(ctx, provider.Issuer)
+	if err != nil {
+		return nil, "", "", err
+	}
+	idToken, err := s.idpClient.Exchange(ctx, endpoints, provider.ClientID, provider.ClientSecret, code, s.RedirectURI(), loginState.CodeVerifier)
+	if err != nil {
+		return nil, "", "", err
+	}
+	claims, err := s.idpClient.VerifyIDToken(ctx, endpoints, provider.ClientID, idToken, loginState.Nonce)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	mappings, err := decodeRoleMappings(provider.RoleMappings)
+	if err != nil {
+		return nil, "", "", err
+	}
+	account := federatedAccount{
+		ProviderID:    provider.ID,
+		ProviderName:  provider.Name,
+		Subject:       claimString(claims, "sub"),
+		Email:         strings.TrimSpace(claimString(claims, "email")),
+		EmailVerified: claimBool(claims, "email_verified"),
+		Username:      claimString(claims, "preferred_username"),
+		FirstName:     claimString(claims, "given_name"),
+		LastName:      claimString(claims, "family_name"),
+	}
+	if provider.GroupsClaim != "" {
+		account.Groups = claimStrings(claims, provider.GroupsClaim)
+		account.RoleMappings = mappings
+	}
+
+	return s.signIn(ctx, account, client)
+}
+
+// signIn signs in the user account belongs to, resolving or provisioning
+// them and synchronizing their mapped roles. For MFA-enabled accounts, it
+// returns ErrMFARequired with the user and, in place of the access token,
+// the temporary token that completes the sign-in.
+func (s *FederationService) signIn(ctx context.Context, account federatedAccount, client ClientInfo) (*models.User, string, string, error) {
+	user, err := s.resolveUser(ctx, account, client)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	if !user.IsActive {
+		s.auditService.RecordUserAction(ctx, user, AuditActionLoginFailed, map[string]interface{}{
+			"reason":               "account_disabled",
+			"identity_provider_id": account.ProviderID,
+		}, client)
+		return nil, "", "", ErrAccountDisabled
+	}
+
+	// The mapped roles of MFA-enabled users are only synchronized once the
+	// second factor is verified, so the temporary token carries them.
+	if user.MFAEnabled {
+		tempToken, err := s.authService.GenerateTempToken(user.ID, account.roleSync())
+		if err != nil {
+			return nil, "", "", err
+		}
+		return user, tempToken, "", ErrMFARequired
+	}
+
+	if err := s.authorizationService.ApplyRoleSync(ctx, user, account.roleSync(), client); err != nil {
+		return nil, "", "", err
+	}
+
+	s.auditService.RecordUserAction(ctx, user, AuditActionFederatedLogin, map[string]interface{}{
+		"identity_provider_id": account.ProviderID,
+		"identity_provider":    account.ProviderName,
+	}, client)
+
+	accessToken, refreshToken, err := s.authService.GenerateTokens(ctx, user, AuthMethodFederated, client)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return user, accessToken, refreshToken, nil
+}
+
+func (s *FederationService) findEnabledProvider(ctx context.Context, id string) (*models.IdentityProvider, error) {
+	providerID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := s.providerRepo.FindByID(ctx, providerID)
+	if err != nil || !provider.Enabled {
+		return nil, ErrIdentityProviderNotFound
+	}
+	return provider, nil
+}
+
+// resolveUser returns the user linked to account. An unlinked account is
+// linked to a newly provisioned user if the provider verified its email
+// address. If a user already has that address, nobody is signed in: a
+// pending link is recorded instead, for the user to confirm while signed
+// in, and ErrFederatedLinkPending is returned.
+func (s *FederationService) resolveUser(ctx context.Context, account federatedAccount, client ClientInfo) (*models.User, error) {
+	identity, err := s.identityRepo.FindBySubject(ctx, account.ProviderID, account.Subject)
+	if err == nil {
+		if identity.Pending {
+			return nil, ErrFederatedLinkPending
+		}
+		user, err := s.userRepo.FindByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, ErrUserNotFound
+		}
+
+		now := time.Now()
+		identity.LastLoginAt = &now
+		if account.Email != "" {
+			identity.Email = account.Email
+		}
+		if err := s.identityRepo.Update(ctx, identity); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	if account.Email == "" || !account.EmailVerified {
+		return nil, ErrFederatedEmailNotVerified
+	}
+
+	if user, err := s.userRepo.FindByEmail(ctx, account.Email); err == nil {
+		if err := s.requestLink(ctx, user, account, client); err != nil {
+			return nil, err
+		}
+		return nil, ErrFederatedLinkPending
+	}
+
+	user, err := s.provisionUser(ctx, account, client)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	identity = &models.FederatedIdentity{
+		UserID:             user.ID,
+		IdentityProviderID: account.ProviderID,
+		Subject:            account.Subject,
+		Email:              account.Email,
+		LastLoginAt:        &now,
+	}
+	if err := s.identityRepo.Create(ctx, identity); err != nil {
+		return nil, err
+	}
+
+	s.auditService.RecordUserAction(ctx, user, AuditActionFederatedLink, map[string]interface{}{
+		"identity_provider_id": account.ProviderID,
+		"identity_provider":    account.ProviderName,
+		"subject":              account.Subject,
+	}, client)
+
+	return user, nil
+}
+
+// requestLink records a pending link of account to user, the existing
+// user with the same email address.
+func (s *FederationService) requestLink(ctx context.Context, user *models.User, account federatedAccount, client ClientInfo) error {
+	identity := &models.FederatedIdentity{
+		UserID:             user.ID,
+		IdentityProviderID: account.ProviderID,
+		Subject:            account.Subject,
+		Email:              account.Email,
+		Pending:            true,
+	}
+	if err := s.identityRepo.Create(ctx, identity); err != nil {
+		return err
+	}
+
+	s.auditService.RecordUserAction(ctx, user, AuditActionFederatedLinkRequest, map[string]interface{}{
+		"identity_provider_id": account.ProviderID,
+		"identity_provider":    account.ProviderName,
+		"subject":              account.Subject,
+	}, client)
+
+	return nil
+}
+
+// ListLinks returns the links of user to identity provider accounts,
+// including pending ones.
+func (s *FederationService) ListLinks(ctx context.Context, user *models.User) ([]*models.FederatedIdentity, error) {
+	return s.identityRepo.FindByUserID(ctx, user.ID)
+}
+
+// ConfirmLink confirms a pending link of user to an identity provider
+// account, after which the account signs in as user. Users who have not
+// verified their own email address cannot confirm links, as whoever
+// registered the address may not own it.
+func (s *FederationService) ConfirmLink(ctx context.Context, user *models.User, identityID string, client ClientInfo) (*models.FederatedIdentity, error) {
+	identity, err := s.findOwnedIdentity(ctx, user, identityID)
+	if err != nil {
+		return nil, err
+	}
+	if !identity.Pending {
+		return identity, nil
+	}
+	if !user.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	identity.Pending = false
+	if err := s.identityRepo.Update(ctx, identity); err != nil {
+		return nil, err
+	}
+
+	s.auditService.RecordUserAction(ctx, user, AuditActionFederatedLink, map[string]interface{}{
+		"identity_provider_id": identity.IdentityProviderID,
+		"subject":              identity.Subject,
+	}, client)
+
+	return identity, nil
+}
+
+// RemoveLink unlinks user from an identity provider account, declining the
+// link if it is pending.
+func (s *FederationService) RemoveLink(ctx context.Context, user *models.User, identityID string, client ClientInfo) error {
+	identity, err := s.findOwnedIdentity(ctx, user, identityID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.identityRepo.Delete(ctx, identity.ID); err != nil {
+		return err
+	}
+
+	s.auditService.RecordUserAction(ctx, user, AuditActionFederatedUnlink, map[string]interface{}{
+		"identity_provider_id": identity.IdentityProviderID,
+		"subject":              identity.Subject,
+		"pending":              identity.Pending,
+	}, client)
+
+	return nil
+}
+
+func (s *FederationService) findOwnedIdentity(ctx context.Context, user *models.User, identityID string) (*models.FederatedIdentity, error) {
+	id, err := uuid.Parse(identityID)
+	if err != nil {
+		return nil, ErrFederatedIdentityNotFound
+	}
+
+	identity, err := s.identityRepo.FindByID(ctx, id)
+	if err != nil || identity.UserID != user.ID {
+		return nil, ErrFederatedIdentityNotFound
+	}
+	return identity, nil
+}
+
+// provisionUser creates the user an unknown provider account signs in as.
+// The user has no usable password until they reset it.
+func (s *FederationService) provisionUser(ctx context.Context, account federatedAccount, client ClientInfo) (*models.User, error) {
+	tenantID, _ := tenancy.TenantID(ctx)
+	policy, err := s.policyService.GetPolicy(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.CheckEmailDomain(account.Email); err != nil {
+		return nil, err
+	}
+
+	username, err := s.availableUsername(ctx, account.Email, account.Username)
+	if err != nil {
+		return nil, err
+	}
+	password, err := s.unusablePassword()
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Email:         account.Email,
+		Username:      username,
+		Password:      password,
+		FirstName:     truncate(account.FirstName, 50),
+		LastName:      truncate(account.LastName, 50),
+		IsActive:      true,
+		EmailVerified: true,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.auditService.RecordUserAction(ctx, user, AuditActionRegister, map[string]interface{}{
+		"email":    user.Email,
+		"username": user.Username,
+		"method":   AuthMethodFederated,
+	}, client)
+
+	return user, nil
+}
+
+// availableUsername derives a username from the provider's preferred
+// username, or else the local part of email, adding a random suffix if it
+// is taken.
+func (s *FederationService) availableUsername(ctx context.Context, email, preferred string) (string, error) {
+	base := strings.TrimSpace(preferred)
+	if base == "" || strings.Contains(base, "@") {
+		base = email[:strings.LastIndex(email, "@")]
+	}
+	base = truncate(base, 40)
+
+	candidate := base
+	for attempt := 0; attempt < maxUsernameAttempts; attempt++ {
+		if _, err := s.userRepo.FindByUsername(ctx, candidate); err != nil {
+			return candidate, nil
+		}
+		suffix, err := generateRandomBytes(3)
+		if err != nil {
+			return "", err
+		}
+		candidate = base + "-" + hex.EncodeToString(suffix)
+	}
+	return "", ErrNoAvailableUsername
+}
+
+func (s *FederationService) unusablePassword() (string, error) {
+	raw, err := generateRandomBytes(opaqueTokenSize)
+	if err != nil {
+		return "", err
+	}
+	return s.authService.hashPassword(base64.RawURLEncoding.EncodeToString(raw))
+}
+
+// roleSync returns the roles mapped from the groups of account the user
+// is to be granted, and the mapped roles to revoke because the user is no
+// longer a member of their groups. Roles not mapped by the provider are
+// left alone. It returns nil if the provider maps no roles.
+func (account federatedAccount) roleSync() *RoleSync {
+	if len(account.RoleMappings) == 0 {
+		return nil
+	}
+
+	sync := &RoleSync{
+		ProviderID: account.ProviderID,
+		Managed:    make([]uuid.UUID, 0, len(account.RoleMappings)),
+	}
+	for _, mapping := range account.RoleMappings {
+		sync.Managed = append(sync.Managed, mapping.RoleID)
+		if hasScope(account.Groups, mapping.Group) {
+			sync.Granted = append(sync.Granted, mapping.RoleID)
+		}
+	}
+	return sync
+}
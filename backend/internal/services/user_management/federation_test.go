@@ -0,0 +1,520 @@
+package user_management
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+// mockIdentityProvider is an OpenID Provider served from a local test
+// server. It issues RS256 ID tokens with the claims a test registers for
+// an authorization code.
+type mockIdentityProvider struct {
+	server       *httptest.Server
+	key          *rsa.PrivateKey
+	clientID     string
+	clientSecret string
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	challenge string
+	claims    map[string]interface{}
+}
+
+func newMockIdentityProvider(t *testing.T) *mockIdentityProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate provider key: %v", err)
+	}
+
+	idp := &mockIdentityProvider{
+		key:          key,
+		clientID:     "adminsuite",
+		clientSecret: "provider-secret",
+		codes:        make(map[string]mockAuthorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, ProviderEndpoints{
+			Issuer:                idp.server.URL,
+			AuthorizationEndpoint: idp.server.URL + "/authorize",
+			TokenEndpoint:         idp.server.URL + "/token",
+			JWKSURI:               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"keys": []JWK{{
+			KeyType:   "RSA",
+			N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			KeyID:     "mock",
+			Use:       "sig",
+			Algorithm: "RS256",
+		}}})
+	})
+	mux.HandleFunc("/token", idp.token)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+// authorize plays the user signing in at the provider: it registers a code
+// for the request in authorizationURL that redeems for an ID token with
+// claims, and returns the code and state the provider would redirect with.
+// The request's nonce is added to claims unless they carry one.
+func (idp *mockIdentityProvider) authorize(t *testing.T, authorizationURL string, claims map[string]interface{}) (string, string) {
+	t.Helper()
+
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatalf("invalid authorization URL: %v", err)
+	}
+	query := parsed.Query()
+	if query.Get("client_id") != idp.clientID || query.Get("code_challenge_method") != CodeChallengeMethodS256 {
+		t.Fatalf("unexpected authorization request: %s", authorizationURL)
+	}
+	if _, ok := claims["nonce"]; !ok {
+		claims["nonce"] = query.Get("nonce")
+	}
+
+	code := uuid.NewString()
+	idp.mu.Lock()
+	idp.codes[code] = mockAuthorization{challenge: query.Get("code_challenge"), claims: claims}
+	idp.mu.Unlock()
+
+	return code, query.Get("state")
+}
+
+func (idp *mockIdentityProvider) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != idp.clientID || clientSecret != idp.clientSecret {
+		http.Error(w, "invalid_client", http.StatusUnauthorized)
+		return
+	}
+
+	idp.mu.Lock()
+	authorization, ok := idp.codes[r.PostFormValue("code")]
+	delete(idp.codes, r.PostFormValue("code"))
+	idp.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(verifier[:]) != authorization.challenge {
+		http.Error(w, "invalid_grant", http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, map[string]string{"id_token": idp.sign(authorization.claims)})
+}
+
+// sign issues an ID token with claims, filling in the standard claims they
+// leave out.
+func (idp *mockIdentityProvider) sign(claims map[string]interface{}) string {
+	now := time.Now()
+	token := map[string]interface{}{
+		"iss": idp.server.URL,
+		"aud": idp.clientID,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Minute).Unix(),
+	}
+	for name, value := range claims {
+		token[name] = value
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": "mock"})
+	payload, _ := json.Marshal(token)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, digest[:])
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+type federationTest struct {
+	*serviceTest
+	idp          *mockIdentityProvider
+	provider     *models.IdentityProvider
+	federation   *FederationService
+	providers    *IdentityProviderService
+	identityRepo user_management.FederatedIdentityRepository
+}
+
+func newFederationTest(t *testing.T) *federationTest {
+	t.Helper()
+
+	st := newServiceTest(t)
+	providerRepo := user_management.NewIdentityProviderRepository(st.db)
+	identityRepo := user_management.NewFederatedIdentityRepository(st.db)
+
+	idp := newMockIdentityProvider(t)
+	providers := NewIdentityProviderService(providerRepo, identityRepo, st.roleRepo, st.authz, st.auditService)
+	provider, err := providers.CreateProvider(st.ctx, &models.User{}, IdentityProviderInput{
+		Name:         stringPtr("Mock"),
+		Issuer:       &idp.server.URL,
+		ClientID:     &idp.clientID,
+		ClientSecret: &idp.clientSecret,
+		GroupsClaim:  stringPtr("groups"),
+	}, ClientInfo{})
+	if err != nil {
+		t.Fatalf("failed to create identity provider: %v", err)
+	}
+
+	return &federationTest{
+		serviceTest: st,
+		idp:         idp,
+		provider:    provider,
+		federation: NewFederationService(providerRepo, identityRepo, user_management.NewFederatedLoginStateRepository(st.db), st.userRepo,
+			st.authService, st.authz, st.policyService, NewIdentityProviderClient(idp.server.Client()), st.tokenHasher, st.auditService, "https://app.acme.test"),
+		providers:    providers,
+		identityRepo: identityRepo,
+	}
+}
+
+// signIn runs a federated sign-in in which the provider reports claims.
+func (ft *federationTest) signIn(t *testing.T, claims map[string]interface{}) (*models.User, error) {
+	t.Helper()
+
+	authorizationURL, err := ft.federation.StartLogin(ft.ctx, ft.provider.ID.String())
+	if err != nil {
+		t.Fatalf("failed to start login: %v", err)
+	}
+	code, state := ft.idp.authorize(t, authorizationURL, claims)
+
+	user, accessToken, refreshToken, err := ft.federation.CompleteLogin(ft.ctx, code, state, ClientInfo{})
+	if err == nil && (accessToken == "" || refreshToken == "") {
+		t.Fatalf("sign-in issued no tokens")
+	}
+	return user, err
+}
+
+func (ft *federationTest) roleNames(t *testing.T, userID uuid.UUID) map[string]bool {
+	t.Helper()
+
+	user, err := ft.userRepo.FindByID(ft.ctx, userID)
+	if err != nil {
+		t.Fatalf("failed to load user: %v", err)
+	}
+	names := make(map[string]bool, len(user.Roles))
+	for _, role := range user.Roles {
+		names[role.Name] = true
+	}
+	return names
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func TestFederatedLoginProvisionsUser(t *testing.T) {
+	ft := newFederationTest(t)
+
+	claims := map[string]interface{}{
+		"sub":                "alice-1",
+		"email":              "alice@acme.test",
+		"email_verified":     true,
+		"preferred_username": "alice",
+		"given_name":         "Alice",
+		"family_name":        "Liddell",
+	}
+	user, err := ft.signIn(t, claims)
+	if err != nil {
+		t.Fatalf("sign-in failed: %v", err)
+	}
+	if user.Email != "alice@acme.test" || user.Username != "alice" || user.FirstName != "Alice" || !user.EmailVerified {
+		t.Fatalf("unexpected provisioned user: %+v", user)
+	}
+
+	identity, err := ft.identityRepo.FindBySubject(ft.ctx, ft.provider.ID, "alice-1")
+	if err != nil || identity.UserID != user.ID {
+		t.Fatalf("provider account was not linked to the user: %v", err)
+	}
+
+	delete(claims, "nonce")
+	again, err := ft.signIn(t, claims)
+	if err != nil {
+		t.Fatalf("second sign-in failed: %v", err)
+	}
+	if again.ID != user.ID {
+		t.Fatalf("second sign-in resolved user %s, want %s", again.ID, user.ID)
+	}
+}
+
+func TestFederatedLoginLinksExistingUserOnlyOnceConfirmed(t *testing.T) {
+	ft := newFederationTest(t)
+
+	existing := &models.User{Email: "bob@acme.test", Username: "bob", Password: "hash", IsActive: true, EmailVerified: true, IsSuperAdmin: true}
+	if err := ft.userRepo.Create(ft.ctx, existing); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	claims := map[string]interface{}{"sub": "bob-1", "email": "bob@acme.test", "email_verified": true}
+	for attempt := 0; attempt < 2; attempt++ {
+		if _, err := ft.signIn(t, claims); err != ErrFederatedLinkPending {
+			t.Fatalf("sign-in as an existing user returned %v, want ErrFederatedLinkPending", err)
+		}
+		delete(claims, "nonce")
+	}
+
+	links, err := ft.federation.ListLinks(ft.ctx, existing)
+	if err != nil || len(links) != 1 || !links[0].Pending || links[0].Subject != "bob-1" {
+		t.Fatalf("links = %+v (err %v), want one pending link", links, err)
+	}
+
+	other := ft.createUser(t, "mallory@acme.test", "Secret-pass-1")
+	if _, err := ft.federation.ConfirmLink(ft.ctx, other, links[0].ID.String(), ClientInfo{}); err != ErrFederatedIdentityNotFound {
+		t.Fatalf("confirming another user's link returned %v, want ErrFederatedIdentityNotFound", err)
+	}
+
+	if _, err := ft.federation.ConfirmLink(ft.ctx, existing, links[0].ID.String(), ClientInfo{}); err != nil {
+		t.Fatalf("confirming the link failed: %v", err)
+	}
+	user, err := ft.signIn(t, claims)
+	if err != nil {
+		t.Fatalf("sign-in after confirming failed: %v", err)
+	}
+	if user.ID != existing.ID || user.Password != "hash" {
+		t.Fatalf("sign-in resolved %+v, want the unchanged existing user", user)
+	}
+}
+
+func TestFederatedLoginDoesNotTakeOverUnverifiedAccount(t *testing.T) {
+	ft := newFederationTest(t)
+
+	existing := &models.User{Email: "carol@acme.test", Username: "carol", Password: "hash", IsActive: true}
+	if err := ft.userRepo.Create(ft.ctx, existing); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	if _, err := ft.signIn(t, map[string]interface{}{"sub": "carol-1", "email": "carol@acme.test", "email_verified": true}); err != ErrFederatedLinkPending {
+		t.Fatalf("sign-in as an unverified user returned %v, want ErrFederatedLinkPending", err)
+	}
+	if user, err := ft.userRepo.FindByID(ft.ctx, existing.ID); err != nil || user.Password != "hash" || user.EmailVerified {
+		t.Fatalf("unverified account was changed by the sign-in: %+v, %v", user, err)
+	}
+
+	identity, err := ft.identityRepo.FindBySubject(ft.ctx, ft.provider.ID, "carol-1")
+	if err != nil {
+		t.Fatalf("no pending link was recorded: %v", err)
+	}
+	if _, err := ft.federation.ConfirmLink(ft.ctx, existing, identity.ID.String(), ClientInfo{}); err != ErrEmailNotVerified {
+		t.Fatalf("confirming a link to an unverified account returned %v, want ErrEmailNotVerified", err)
+	}
+
+	if err := ft.federation.RemoveLink(ft.ctx, existing, identity.ID.String(), ClientInfo{}); err != nil {
+		t.Fatalf("declining the link failed: %v", err)
+	}
+	if _, err := ft.identityRepo.FindBySubject(ft.ctx, ft.provider.ID, "carol-1"); err == nil {
+		t.Fatalf("declined link was kept")
+	}
+}
+
+func TestFederatedLoginRequiresVerifiedEmail(t *testing.T) {
+	ft := newFederationTest(t)
+
+	existing := &models.User{Email: "dave@acme.test", Username: "dave", Password: "hash", IsActive: true, EmailVerified: true}
+	if err := ft.userRepo.Create(ft.ctx, existing); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	for _, claims := range []map[string]interface{}{
+		{"sub": "dave-1", "email": "dave@acme.test", "email_verified": false},
+		{"sub": "dave-1", "email": "dave@acme.test"},
+		{"sub": "erin-1", "email": "erin@acme.test", "email_verified": "false"},
+	} {
+		if _, err := ft.signIn(t, claims); err != ErrFederatedEmailNotVerified {
+			t.Fatalf("sign-in with %v returned %v, want ErrFederatedEmailNotVerified", claims, err)
+		}
+	}
+
+	if _, err := ft.identityRepo.FindBySubject(ft.ctx, ft.provider.ID, "dave-1"); err == nil {
+		t.Fatalf("account with an unverified email was linked")
+	}
+	if _, err := ft.userRepo.FindByEmail(ft.ctx, "erin@acme.test"); err == nil {
+		t.Fatalf("user with an unverified email was provisioned")
+	}
+}
+
+func TestFederatedLoginMapsGroupsToRoles(t *testing.T) {
+	ft := newFederationTest(t)
+
+	admins := ft.createRole(t, "admin")
+	developers := ft.createRole(t, "developer")
+	support := ft.createRole(t, "support")
+
+	if _, err := ft.providers.UpdateProvider(ft.ctx, &models.User{}, ft.provider.ID.String(), IdentityProviderInput{
+		RoleMappings: []GroupRoleMapping{
+			{Group: "admins", RoleID: admins.ID},
+			{Group: "engineering", RoleID: developers.ID},
+		},
+	}, ClientInfo{}); err != nil {
+		t.Fatalf("failed to set role mappings: %v", err)
+	}
+
+	claims := map[string]interface{}{"sub": "frank-1", "email": "frank@acme.test", "email_verified": true, "groups": []string{"admins", "other"}}
+	user, err := ft.signIn(t, claims)
+	if err != nil {
+		t.Fatalf("sign-in failed: %v", err)
+	}
+	if roles := ft.roleNames(t, user.ID); !roles["admin"] || roles["developer"] {
+		t.Fatalf("roles after first sign-in = %v, want admin only", roles)
+	}
+
+	// Roles granted by other means are not managed by the provider.
+	if err := ft.userRepo.AddRole(ft.ctx, user, support); err != nil {
+		t.Fatalf("failed to add role: %v", err)
+	}
+
+	user, err = ft.signIn(t, map[string]interface{}{"sub": "frank-1", "groups": "engineering"})
+	if err != nil {
+		t.Fatalf("second sign-in failed: %v", err)
+	}
+	if roles := ft.roleNames(t, user.ID); roles["admin"] || !roles["developer"] || !roles["support"] {
+		t.Fatalf("roles after second sign-in = %v, want developer and support", roles)
+	}
+}
+
+func TestFederatedLoginSyncsRolesOfMFAUsersOnlyOnceVerified(t *testing.T) {
+	ft := newFederationTest(t)
+
+	admins := ft.createRole(t, "admin")
+	if _, err := ft.providers.UpdateProvider(ft.ctx, &models.User{}, ft.provider.ID.String(), IdentityProviderInput{
+		RoleMappings: []GroupRoleMapping{{Group: "admins", RoleID: admins.ID}},
+	}, ClientInfo{}); err != nil {
+		t.Fatalf("failed to set role mappings: %v", err)
+	}
+
+	user, err := ft.signIn(t, map[string]interface{}{"sub": "gina-1", "email": "gina@acme.test", "email_verified": true})
+	if err != nil {
+		t.Fatalf("sign-in failed: %v", err)
+	}
+	user.MFAEnabled = true
+	user.MFAMethod = models.MFAMethodEmail
+	user.MFAEmailCode = "123456"
+	user.MFAEmailCodeExpiry = time.Now().Add(time.Minute)
+	if err := ft.userRepo.Update(ft.ctx, user); err != nil {
+		t.Fatalf("failed to enable MFA: %v", err)
+	}
+
+	authorizationURL, err := ft.federation.StartLogin(ft.ctx, ft.provider.ID.String())
+	if err != nil {
+		t.Fatalf("failed to start login: %v", err)
+	}
+	code, state := ft.idp.authorize(t, authorizationURL, map[string]interface{}{"sub": "gina-1", "groups": []string{"admins"}})
+	_, tempToken, _, err := ft.federation.CompleteLogin(ft.ctx, code, state, ClientInfo{})
+	if err != ErrMFARequired || tempToken == "" {
+		t.Fatalf("sign-in of an MFA user returned %v, want ErrMFARequired with a temporary token", err)
+	}
+	if roles := ft.roleNames(t, user.ID); roles["admin"] {
+		t.Fatalf("mapped role was granted before the second factor was verified")
+	}
+
+	pending, roleSync, err := ft.authService.GetUserByTempToken(ft.ctx, tempToken)
+	if err != nil || roleSync == nil {
+		t.Fatalf("temporary token carried no role sync (err %v)", err)
+	}
+	if valid, _ := ft.authService.VerifyMFALogin(ft.ctx, pending, "654321", roleSync, ClientInfo{}); valid {
+		t.Fatalf("wrong MFA code was accepted")
+	}
+	if roles := ft.roleNames(t, user.ID); roles["admin"] {
+		t.Fatalf("mapped role was granted after a failed second factor")
+	}
+
+	if valid, err := ft.authService.VerifyMFALogin(ft.ctx, pending, "123456", roleSync, ClientInfo{}); err != nil || !valid {
+		t.Fatalf("MFA verification failed: %v", err)
+	}
+	if roles := ft.roleNames(t, user.ID); !roles["admin"] {
+		t.Fatalf("mapped role was not granted once the second factor was verified")
+	}
+}
+
+func TestIdentityProviderRejectsUnknownMappedRole(t *testing.T) {
+	ft := newFederationTest(t)
+
+	_, err := ft.providers.UpdateProvider(ft.ctx, &models.User{}, ft.provider.ID.String(), IdentityProviderInput{
+		RoleMappings: []GroupRoleMapping{{Group: "admins", RoleID: uuid.New()}},
+	}, ClientInfo{})
+	if err != ErrInvalidRoleMapping {
+		t.Fatalf("mapping to an unknown role returned %v, want ErrInvalidRoleMapping", err)
+	}
+}
+
+func TestIdentityProviderRejectsMappingToRoleBeyondActor(t *testing.T) {
+	ft := newFederationTest(t)
+
+	auditors := ft.createRole(t, "auditor", "audit:read")
+
+	input := IdentityProviderInput{RoleMappings: []GroupRoleMapping{{Group: "auditors", RoleID: auditors.ID}}}
+	if _, err := ft.providers.UpdateProvider(ft.ctx, &models.User{}, ft.provider.ID.String(), input, ClientInfo{}); err != ErrPermissionNotHeld {
+		t.Fatalf("mapping by an actor without audit:read returned %v, want ErrPermissionNotHeld", err)
+	}
+	if _, err := ft.providers.UpdateProvider(ft.ctx, &models.User{IsSuperAdmin: true}, ft.provider.ID.String(), input, ClientInfo{}); err != nil {
+		t.Fatalf("mapping by a super admin failed: %v", err)
+	}
+}
+
+func TestFederatedLoginRejectsReplayedState(t *testing.T) {
+	ft := newFederationTest(t)
+
+	authorizationURL, err := ft.federation.StartLogin(ft.ctx, ft.provider.ID.String())
+	if err != nil {
+		t.Fatalf("failed to start login: %v", err)
+	}
+	claims := map[string]interface{}{"sub": "grace-1", "email": "grace@acme.test", "email_verified": true}
+	code, state := ft.idp.authorize(t, authorizationURL, claims)
+	if _, _, _, err := ft.federation.CompleteLogin(ft.ctx, code, state, ClientInfo{}); err != nil {
+		t.Fatalf("sign-in failed: %v", err)
+	}
+
+	// A second code issued for the same request cannot reuse the state.
+	replayed, _ := ft.idp.authorize(t, authorizationURL, claims)
+	if _, _, _, err := ft.federation.CompleteLogin(ft.ctx, replayed, state, ClientInfo{}); err != ErrInvalidFederatedState {
+		t.Fatalf("replayed state returned %v, want ErrInvalidFederatedState", err)
+	}
+	if _, _, _, err := ft.federation.CompleteLogin(ft.ctx, replayed, "forged", ClientInfo{}); err != ErrInvalidFederatedState {
+		t.Fatalf("unknown state returned %v, want ErrInvalidFederatedState", err)
+	}
+}
+
+func TestFederatedLoginRejectsInvalidIDTokens(t *testing.T) {
+	ft := newFederationTest(t)
+
+	for name, claims := range map[string]map[string]interface{}{
+		"nonce":    {"sub": "heidi-1", "email": "heidi@acme.test", "email_verified": true, "nonce": "replayed"},
+		"audience": {"sub": "heidi-1", "email": "heidi@acme.test", "email_verified": true, "aud": "another-client"},
+		"issuer":   {"sub": "heidi-1", "email": "heidi@acme.test", "email_verified": true, "iss": "https://evil.test"},
+		"expired":  {"sub": "heidi-1", "email": "heidi@acme.test", "email_verified": true, "exp": time.Now().Add(-time.Hour).Unix()},
+	} {
+		if _, err := ft.signIn(t, claims); err != ErrInvalidIDToken {
+			t.Fatalf("ID token with a bad %s returned %v, want ErrInvalidIDToken", name, err)
+		}
+	}
+
+	if _, err := ft.userRepo.FindByEmail(ft.ctx, "heidi@acme.test"); err == nil {
+		t.Fatalf("user was provisioned from an invalid ID token")
+	}
+}
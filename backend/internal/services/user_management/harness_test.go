@@ -0,0 +1,163 @@
+package user_management
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/config"
+	"github.com/josy-coder/adminsuite/internal/database/databasetest"
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+	"github.com/josy-coder/adminsuite/internal/tenancy"
+)
+
+// serviceTest wires the services of the package to a private database
+// holding a single tenant, whose context ctx carries. Emails are sent to an
+// SMTP server that refuses connections, so every send fails.
+type serviceTest struct {
+	ctx    context.Context
+	db     *gorm.DB
+	tenant *models.Tenant
+
+	userRepo         user_management.UserRepository
+	roleRepo         user_management.RoleRepository
+	permissionRepo   user_management.PermissionRepository
+	tokenRepo        user_management.TokenRepository
+	tenantRepo       user_management.TenantRepository
+	sessionRepo      user_management.SessionRepository
+	loginAttemptRepo user_management.LoginAttemptRepository
+
+	auditService    *AuditService
+	policyService   *AuthPolicyService
+	revocations     *TokenRevocationService
+	securityStamps  *SecurityStampService
+	tokenHasher     *TokenHasher
+	tokenSigner     *TokenSigner
+	sessionService  *SessionService
+	authz           *AuthorizationService
+	loginProtection *LoginProtectionService
+	verification    *EmailVerificationService
+	authService     *AuthenticationService
+}
+
+func newServiceTest(t *testing.T) *serviceTest {
+	t.Helper()
+
+	db := databasetest.Open(t,
+		&models.Tenant{}, &models.User{}, &models.Role{}, &models.Permission{}, &models.Token{},
+		&models.Session{}, &models.PasswordReset{}, &models.LoginAttempt{}, &models.AuditLog{},
+		&models.Device{}, &models.OAuthClient{}, &models.OAuthScope{}, &models.OAuthConsent{},
+		&models.OAuthAuthorizationCode{}, &models.IdentityProvider{}, &models.FederatedIdentity{},
+		&models.FederatedLoginState{}, &models.SAMLProvider{}, &models.SAMLRequest{},
+	)
+
+	tenant := &models.Tenant{Name: "Acme", Domain: "acme.test"}
+	if err := db.Create(tenant).Error; err != nil {
+		t.Fatalf("failed to create tenant: %v", err)
+	}
+	ctx := tenancy.WithTenant(context.Background(), tenant.ID)
+
+	st := &serviceTest{
+		ctx:              ctx,
+		db:               db,
+		tenant:           tenant,
+		userRepo:         user_management.NewUserRepository(db),
+		roleRepo:         user_management.NewRoleRepository(db),
+		permissionRepo:   user_management.NewPermissionRepository(db),
+		tokenRepo:        user_management.NewTokenRepository(db),
+		tenantRepo:       user_management.NewTenantRepository(db),
+		sessionRepo:      user_management.NewSessionRepository(db),
+		loginAttemptRepo: user_management.NewLoginAttemptRepository(db),
+	}
+
+	signingKey, err := GenerateSigningKey()
+	if err != nil {
+		t.Fatalf("failed to generate signing key: %v", err)
+	}
+	keyRing, err := NewKeyRing(ctx, NewStaticKeyStore(signingKey), nil)
+	if err != nil {
+		t.Fatalf("failed to create key ring: %v", err)
+	}
+	cfg := &config.Config{SMTPHost: "127.0.0.1", SMTPPort: 1, SMTPFrom: "noreply@acme.test"}
+
+	st.auditService = NewAuditService(user_management.NewAuditLogRepository(db))
+	st.policyService = NewAuthPolicyService(st.tenantRepo, st.auditService)
+	st.revocations = NewTokenRevocationService(NewMemoryRevocationStore())
+	st.securityStamps = NewSecurityStampService(st.userRepo, st.revocations)
+	st.tokenHasher = NewTokenHasher([]byte("service-test"))
+	st.tokenSigner = NewTokenSigner(keyRing)
+	st.sessionService = NewSessionService(st.sessionRepo, st.tokenRepo, st.userRepo, st.tokenHasher, st.revocations, st.auditService)
+	st.authz = NewAuthorizationService(st.userRepo, st.roleRepo, st.permissionRepo, user_management.NewOAuthScopeRepository(db), st.securityStamps, st.auditService)
+	st.loginProtection = NewLoginProtectionService(st.userRepo, st.loginAttemptRepo)
+
+	emailService := NewEmailService(cfg)
+	brandingService := NewBrandingService(st.tenantRepo, st.auditService)
+	st.verification = NewEmailVerificationService(st.userRepo, emailService, brandingService, st.tokenSigner, "https://app.acme.test")
+	mfaService := NewMFAService(st.userRepo, cfg, emailService, st.policyService, brandingService, st.securityStamps)
+	deviceService := NewDeviceService(user_management.NewDeviceRepository(db), st.sessionService, st.policyService, st.auditService)
+	st.authService = NewAuthenticationService(st.userRepo, st.tokenRepo, user_management.NewPasswordResetRepository(db), st.tokenSigner, st.tokenHasher,
+		mfaService, emailService, brandingService, st.verification, st.policyService, st.loginProtection, st.auditService, deviceService,
+		st.authz, st.securityStamps, st.sessionService, st.revocations, "https://app.acme.test")
+
+	return st
+}
+
+// createUser registers an active, verified user with password.
+func (st *serviceTest) createUser(t *testing.T, email, password string) *models.User {
+	t.Helper()
+
+	hash, err := st.authService.hashPassword(password)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	user := &models.User{Email: email, Username: email, Password: hash, IsActive: true, EmailVerified: true}
+	if err := st.userRepo.Create(st.ctx, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	return user
+}
+
+func (st *serviceTest) createRole(t *testing.T, name string, permissions ...string) *models.Role {
+	t.Helper()
+
+	role := &models.Role{Name: name}
+	if err := st.roleRepo.Create(st.ctx, role); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	for _, name := range permissions {
+		if err := st.roleRepo.AddPermission(st.ctx, role, st.permission(t, name)); err != nil {
+			t.Fatalf("failed to add permission %s: %v", name, err)
+		}
+	}
+	return role
+}
+
+// permission returns the permission called name, creating it if needed.
+func (st *serviceTest) permission(t *testing.T, name string) *models.Permission {
+	t.Helper()
+
+	if permission, err := st.permissionRepo.FindByName(st.ctx, name); err == nil {
+		return permission
+	}
+	permission := &models.Permission{Name: name}
+	if err := st.permissionRepo.Create(st.ctx, permission); err != nil {
+		t.Fatalf("failed to create permission %s: %v", name, err)
+	}
+	return permission
+}
+
+// assignRole gives user role and returns the reloaded user.
+func (st *serviceTest) assignRole(t *testing.T, user *models.User, role *models.Role) *models.User {
+	t.Helper()
+
+	if err := st.userRepo.AddRole(st.ctx, user, role); err != nil {
+		t.Fatalf("failed to assign role: %v", err)
+	}
+	user, err := st.userRepo.FindByID(st.ctx, user.ID)
+	if err != nil {
+		t.Fatalf("failed to reload user: %v", err)
+	}
+	return user
+}
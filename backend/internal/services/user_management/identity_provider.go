@@ -0,0 +1,272 @@
+package user_management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+var (
+	ErrIdentityProviderNotFound = errors.New("identity provider not found")
+	ErrInvalidIssuer            = errors.New("issuer must be an https URL without query or fragment; http is only allowed for loopback addresses")
+	ErrInvalidRoleMapping       = errors.New("role mappings must name a group and a role of the tenant")
+)
+
+// defaultIdentityProviderScopes are requested from providers configured
+// without scopes. openid is always requested.
+var defaultIdentityProviderScopes = []string{ScopeOpenID, ScopeEmail, ScopeProfile}
+
+// GroupRoleMapping grants the role with ID RoleID to users the identity
+// provider reports as members of Group.
+type GroupRoleMapping struct {
+	Group  string    `json:"group"`
+	RoleID uuid.UUID `json:"role_id"`
+}
+
+// IdentityProviderInput holds the editable identity provider fields. Nil
+// fields are left unchanged on update. An empty ClientSecret keeps the
+// current secret.
+type IdentityProviderInput struct {
+	Name         *string
+	Issuer       *string
+	ClientID     *string
+	ClientSecret *string
+	Scopes       []string
+	GroupsClaim  *string
+	RoleMappings []GroupRoleMapping
+	Enabled      *bool
+}
+
+// IdentityProviderService administers the external OpenID Connect
+// providers members of the tenant carried by the context can sign in with.
+type IdentityProviderService struct {
+	providerRepo         user_management.IdentityProviderRepository
+	identityRepo         user_management.FederatedIdentityRepository
+	roleRepo             user_management.RoleRepository
+	authorizationService *AuthorizationService
+	auditService         *AuditService
+}
+
+func NewIdentityProviderService(
+	providerRepo user_management.IdentityProviderRepository,
+	identityRepo user_management.FederatedIdentityRepository,
+	roleRepo user_management.RoleRepository,
+	authorizationService *AuthorizationService,
+	auditService *AuditService,
+) *IdentityProviderService {
+	return &IdentityProviderService{
+		providerRepo:         providerRepo,
+		identityRepo:         identityRepo,
+		roleRepo:             roleRepo,
+		authorizationService: authorizationService,
+		auditService:         auditService,
+	}
+}
+
+func (s *IdentityProviderService) ListProviders(ctx context.Context) ([]*models.IdentityProvider, error) {
+	return s.providerRepo.FindAll(ctx)
+}
+
+// ListEnabledProviders returns the providers offered on the login page.
+func (s *IdentityProviderService) ListEnabledProviders(ctx context.Context) ([]*models.IdentityProvider, error) {
+	providers, err := s.providerRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make([]*models.IdentityProvider, 0, len(providers))
+	for _, provider := range providers {
+		if provider.Enabled {
+			enabled = append(enabled, provider)
+		}
+	}
+	return enabled, nil
+}
+
+func (s *IdentityProviderService) GetProvider(ctx context.Context, id string) (*models.IdentityProvider, error) {
+	providerID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := s.providerRepo.FindByID(ctx, providerID)
+	if err != nil {
+		return nil, ErrIdentityProviderNotFound
+	}
+	return provider, nil
+}
+
+func (s *IdentityProviderService) CreateProvider(ctx context.Context, actor *models.User, input IdentityProviderInput, client ClientInfo) (*models.IdentityProvider, error) {
+	provider := &models.IdentityProvider{
+		Scopes:       strings.Join(defaultIdentityProviderScopes, " "),
+		RoleMappings: "[]",
+		Enabled:      true,
+	}
+	if err := s.applyProviderInput(ctx, actor, provider, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.providerRepo.Create(ctx, provider); err != nil {
+		return nil, err
+	}
+
+	s.recordProviderChange(ctx, actor, AuditActionIdentityProviderCreate, provider, client)
+
+	return provider, nil
+}
+
+func (s *IdentityProviderService) UpdateProvider(ctx context.Context, actor *models.User, id string, input IdentityProviderInput, client ClientInfo) (*models.IdentityProvider, error) {
+	provider, err := s.GetProvider(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyProviderInput(ctx, actor, provider, input); err != nil {
+		return nil, err
+	}
+	if err := s.providerRepo.Update(ctx, provider); err != nil {
+		return nil, err
+	}
+
+	s.recordProviderChange(ctx, actor, AuditActionIdentityProviderUpdate, provider, client)
+
+	return provider, nil
+}
+
+// DeleteProvider removes a provider together with the links of users to
+// their accounts at it. The users themselves are kept.
+func (s *IdentityProviderService) DeleteProvider(ctx context.Context, actor *models.User, id string, client ClientInfo) error {
+	provider, err := s.GetProvider(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.identityRepo.DeleteByProviderID(ctx, provider.ID); err != nil {
+		return err
+	}
+	if err := s.providerRepo.Delete(ctx, provider.ID); err != nil {
+		return err
+	}
+
+	s.recordProviderChange(ctx, actor, AuditActionIdentityProviderDelete, provider, client)
+
+	return nil
+}
+
+func (s *IdentityProviderService) applyProviderInput(ctx context.Context, actor *models.User, provider *models.IdentityProvider, input IdentityProviderInput) error {
+	if input.Name != nil {
+		provider.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Issuer != nil {
+		issuer := strings.TrimSpace(*input.Issuer)
+		if !validIssuer(issuer) {
+			return ErrInvalidIssuer
+		}
+		provider.Issuer = issuer
+	}
+	if input.ClientID != nil {
+		provider.ClientID = strings.TrimSpace(*input.ClientID)
+	}
+	if input.ClientSecret != nil && *input.ClientSecret != "" {
+		provider.ClientSecret = *input.ClientSecret
+	}
+	if input.Scopes != nil {
+		provider.Scopes = strings.Join(parseScopes(strings.Join(append(input.Scopes, ScopeOpenID), " ")), " ")
+	}
+	if input.GroupsClaim != nil {
+		provider.GroupsClaim = strings.TrimSpace(*input.GroupsClaim)
+	}
+	if input.RoleMappings != nil {
+		encoded, err := encodeRoleMappings(ctx, s.roleRepo, input.RoleMappings)
+		if err != nil {
+			return err
+		}
+		if err := checkRoleMappingsGrantable(ctx, s.authorizationService, actor, input.RoleMappings); err != nil {
+			return err
+		}
+		provider.RoleMappings = encoded
+	}
+	if input.Enabled != nil {
+		provider.Enabled = *input.Enabled
+	}
+	return nil
+}
+
+func (s *IdentityProviderService) recordProviderChange(ctx context.Context, actor *models.User, action string, provider *models.IdentityProvider, client ClientInfo) {
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    actor.ID,
+		Action:     action,
+		Resource:   AuditResourceIdentityProvider,
+		ResourceID: provider.ID.String(),
+		Details: map[string]interface{}{
+			"name":   provider.Name,
+			"issuer": provider.Issuer,
+		},
+		Client: client,
+	})
+}
+
+// encodeRoleMappings checks that mappings name a group and a role of the
+// tenant and encodes them for storing with a provider.
+func encodeRoleMappings(ctx context.Context, roleRepo user_management.RoleRepository, mappings []GroupRoleMapping) (string, error) {
+	for i, mapping := range mappings {
+		mappings[i].Group = strings.TrimSpace(mapping.Group)
+		if mappings[i].Group == "" {
+			return "", ErrInvalidRoleMapping
+		}
+		if _, err := roleRepo.FindByID(ctx, mapping.RoleID); err != nil {
+			return "", ErrInvalidRoleMapping
+		}
+	}
+	encoded, err := json.Marshal(mappings)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// checkRoleMappingsGrantable makes sure actor may grant every mapped role,
+// as members of the mapped groups receive it when they sign in.
+func checkRoleMappingsGrantable(ctx context.Context, authorizationService *AuthorizationService, actor *models.User, mappings []GroupRoleMapping) error {
+	for _, mapping := range mappings {
+		if _, err := authorizationService.GrantableRole(ctx, actor, mapping.RoleID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeRoleMappings decodes the role mappings of a provider.
+func decodeRoleMappings(encoded string) ([]GroupRoleMapping, error) {
+	var mappings []GroupRoleMapping
+	if encoded == "" {
+		return mappings, nil
+	}
+	if err := json.Unmarshal([]byte(encoded), &mappings); err != nil {
+		return nil, err
+	}
+	return mappings, nil
+}
+
+// validIssuer reports whether issuer may identify an OpenID Provider: an
+// https URL without query or fragment, or http for loopback addresses.
+func validIssuer(issuer string) bool {
+	parsed, err := url.Parse(issuer)
+	if err != nil || parsed.Host == "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return false
+	}
+	switch parsed.Scheme {
+	case "https":
+		return true
+	case "http":
+		return validRedirectURI(issuer)
+	}
+	return false
+}
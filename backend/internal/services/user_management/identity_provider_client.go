@@ -0,0 +1,376 @@
+package user_management
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// identityProviderCacheTTL is how long discovery documents and key sets
+	// of identity providers are reused before they are fetched again.
+	identityProviderCacheTTL = time.Hour
+	// idTokenLeeway absorbs clock skew between AdminSuite and a provider.
+	idTokenLeeway = time.Minute
+	// maxIdentityProviderResponse bounds the responses read from a provider.
+	maxIdentityProviderResponse = 1 << 20
+)
+
+var (
+	ErrIdentityProviderUnavailable = errors.New("identity provider could not be reached or returned an invalid response")
+	ErrInvalidIDToken              = errors.New("identity provider returned an invalid ID token")
+)
+
+// ProviderEndpoints are the endpoints of an OpenID Provider a relying party
+// uses, as published in its discovery document.
+type ProviderEndpoints struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type cachedEndpoints struct {
+	endpoints *ProviderEndpoints
+	fetchedAt time.Time
+}
+
+type cachedKeySet struct {
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// IdentityProviderClient talks to external OpenID Providers as a relying
+// party: it discovers their endpoints, redeems authorization codes and
+// verifies the ID tokens they issue. Discovery documents and key sets are
+// cached; a key set is fetched again when a token names an unknown key, so
+// provider key rotations are picked up.
+type IdentityProviderClient struct {
+	httpClient *http.Client
+	mu         sync.Mutex
+	endpoints  map[string]cachedEndpoints
+	keySets    map[string]cachedKeySet
+}
+
+func NewIdentityProviderClient(httpClient *http.Client) *IdentityProviderClient {
+	return &IdentityProviderClient{
+		httpClient: httpClient,
+		endpoints:  make(map[string]cachedEndpoints),
+		keySets:    make(map[string]cachedKeySet),
+	}
+}
+
+// Discover returns the endpoints of the provider identified by issuer. The
+// discovery document must name the same issuer.
+func (c *IdentityProviderClient) Discover(ctx context.Context, issuer string) (*ProviderEndpoints, error) {
+	c.mu.Lock()
+	cached, ok := c.endpoints[issuer]
+	c.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < identityProviderCacheTTL {
+		return cached.endpoints, nil
+	}
+
+	var endpoints ProviderEndpoints
+	if err := c.getJSON(ctx, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &endpoints); err != nil {
+		return nil, err
+	}
+	if endpoints.Issuer != issuer || endpoints.AuthorizationEndpoint == "" || endpoints.TokenEndpoint == "" || endpoints.JWKSURI == "" {
+		return nil, ErrIdentityProviderUnavailable
+	}
+
+	c.mu.Lock()
+	c.endpoints[issuer] = cachedEndpoints{endpoints: &endpoints, fetchedAt: time.Now()}
+	c.mu.Unlock()
+	return &endpoints, nil
+}
+
+// Exchange redeems an authorization code at the token endpoint,
+// authenticating with the client secret, and returns the ID token.
+func (c *IdentityProviderClient) Exchange(ctx context.Context, endpoints *ProviderEndpoints, clientID, clientSecret, code, redirectURI, codeVerifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {GrantTypeAuthorizationCode},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoints.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := c.do(req, &tokens); err != nil {
+		return "", err
+	}
+	if tokens.IDToken == "" {
+		return "", ErrInvalidIDToken
+	}
+	return tokens.IDToken, nil
+}
+
+// VerifyIDToken checks the signature of an ID token against the provider's
+// key set and validates its issuer, audience, lifetime and nonce. It
+// returns the token's claims, with numbers left as json.Number.
+func (c *IdentityProviderClient) VerifyIDToken(ctx context.Context, endpoints *ProviderEndpoints, clientID, idToken, nonce string) (map[string]interface{}, error) {
+	header, input, payload, signature, err := splitJWT(idToken)
+	if err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	key, err := c.lookupKey(ctx, endpoints.JWKSURI, header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyJWSSignature(header.Algorithm, key, input, signature); err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	var claims map[string]interface{}
+	if err := decodeJWTClaims(payload, &claims); err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	if claimString(claims, "iss") != endpoints.Issuer {
+		return nil, ErrInvalidIDToken
+	}
+	audiences := claimStrings(claims, "aud")
+	if !hasScope(audiences, clientID) {
+		return nil, ErrInvalidIDToken
+	}
+	if len(audiences) > 1 && claimString(claims, "azp") != clientID {
+		return nil, ErrInvalidIDToken
+	}
+	expiresAt, ok := claimTime(claims, "exp")
+	if !ok || time.Now().After(expiresAt.Add(idTokenLeeway)) {
+		return nil, ErrInvalidIDToken
+	}
+	if issuedAt, ok := claimTime(claims, "iat"); ok && issuedAt.After(time.Now().Add(idTokenLeeway)) {
+		return nil, ErrInvalidIDToken
+	}
+	if claimString(claims, "nonce") != nonce || claimString(claims, "sub") == "" {
+		return nil, ErrInvalidIDToken
+	}
+
+	return claims, nil
+}
+
+// lookupKey returns the key with ID keyID from the key set at jwksURI. A
+// token without a kid is accepted if the set holds a single key.
+func (c *IdentityProviderClient) lookupKey(ctx context.Context, jwksURI, keyID string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	cached, ok := c.keySets[jwksURI]
+	c.mu.Unlock()
+
+	if !ok || time.Since(cached.fetchedAt) >= identityProviderCacheTTL || findProviderKey(cached.keys, keyID) == nil {
+		keys, err := c.fetchKeySet(ctx, jwksURI)
+		if err != nil {
+			return nil, err
+		}
+		cached = cachedKeySet{keys: keys, fetchedAt: time.Now()}
+		c.mu.Lock()
+		c.keySets[jwksURI] = cached
+		c.mu.Unlock()
+	}
+
+	key := findProviderKey(cached.keys, keyID)
+	if key == nil {
+		return nil, ErrInvalidIDToken
+	}
+	return key, nil
+}
+
+func (c *IdentityProviderClient) fetchKeySet(ctx context.Context, jwksURI string) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []JWK `json:"keys"`
+	}
+	if err := c.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// Keys of types AdminSuite cannot verify with are skipped rather
+		// than failing the whole set.
+		if key, err := jwk.PublicKey(); err == nil {
+			keys[jwk.KeyID] = key
+		}
+	}
+	return keys, nil
+}
+
+func (c *IdentityProviderClient) getJSON(ctx context.Context, target string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return ErrIdentityProviderUnavailable
+	}
+	req.Header.Set("Accept", "application/json")
+	return c.do(req, v)
+}
+
+func (c *IdentityProviderClient) do(req *http.Request, v interface{}) error {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return ErrIdentityProviderUnavailable
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ErrIdentityProviderUnavailable
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxIdentityProviderResponse)).Decode(v); err != nil {
+		return ErrIdentityProviderUnavailable
+	}
+	return nil
+}
+
+func findProviderKey(keys map[string]crypto.PublicKey, keyID string) crypto.PublicKey {
+	if keyID == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key
+		}
+	}
+	return keys[keyID]
+}
+
+// PublicKey decodes the JSON Web Key. RSA keys, P-256 EC keys and Ed25519
+// OKP keys are supported.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch k.KeyType {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Curve != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Curve)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("invalid EC point")
+		}
+		return key, nil
+	case "OKP":
+		x, err := decode(k.X)
+		if err != nil || k.Curve != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported OKP key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", k.KeyType)
+}
+
+// verifyJWSSignature checks signature over input with key under alg. The
+// algorithm must match the type of the key, so a token cannot pick a
+// weaker algorithm than its key was published for.
+func verifyJWSSignature(alg string, key crypto.PublicKey, input, signature []byte) error {
+	digest := sha256.Sum256(input)
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if alg != "RS256" || key.N.BitLen() < 2048 {
+			return ErrInvalidJWT
+		}
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+	case *ecdsa.PublicKey:
+		if alg != "ES256" || len(signature) != 64 {
+			return ErrInvalidJWT
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(key, digest[:], r, s) {
+			return ErrInvalidJWT
+		}
+		return nil
+	case ed25519.PublicKey:
+		if alg != jwtAlgEdDSA || !ed25519.Verify(key, input, signature) {
+			return ErrInvalidJWT
+		}
+		return nil
+	}
+	return ErrInvalidJWT
+}
+
+// claimString returns the string claim name, or "" if it is missing or not
+// a string.
+func claimString(claims map[string]interface{}, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// claimStrings returns claim name as a list of strings. A single string is
+// a list of one, as with the aud claim and groups claims of some providers.
+func claimStrings(claims map[string]interface{}, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// claimBool returns the boolean claim name. Some providers send booleans
+// as strings.
+func claimBool(claims map[string]interface{}, name string) bool {
+	switch value := claims[name].(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	}
+	return false
+}
+
+func claimTime(claims map[string]interface{}, name string) (time.Time, bool) {
+	number, ok := claims[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0), true
+}
@@ -0,0 +1,139 @@
+package user_management
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// jwtAlgEdDSA is the JWS algorithm of Ed25519 signatures (RFC 8037).
+const jwtAlgEdDSA = "EdDSA"
+
+var ErrInvalidJWT = errors.New("invalid or malformed JWT")
+
+// jwtHeader is the JOSE header of a compact JWS.
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// JWK is a public key in JSON Web Key form. Ed25519 keys are OKP keys with
+// X set; other key types fill the members of their kind.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+}
+
+// JWK returns the key as a JSON Web Key for verifying EdDSA signatures.
+func (k PublicKey) JWK() JWK {
+	return JWK{
+		KeyType:   "OKP",
+		Curve:     "Ed25519",
+		X:         base64.RawURLEncoding.EncodeToString(k.Key),
+		KeyID:     k.ID,
+		Use:       "sig",
+		Algorithm: jwtAlgEdDSA,
+	}
+}
+
+// SignJWT signs claims as an EdDSA JWT with the active key of the ring, for
+// consumers such as OpenID Connect relying parties that do not speak PASETO.
+// The key is named in the kid header. A JWT's signing input is ASCII while
+// a v2.public PASETO's starts with a binary length prefix, so the two
+// formats cannot be confused for one another under the same key.
+func (s *TokenSigner) SignJWT(claims interface{}) (string, error) {
+	key := s.ring.Active()
+	return encodeJWT(jwtHeader{Algorithm: jwtAlgEdDSA, Type: "JWT", KeyID: key.ID}, claims, func(input []byte) ([]byte, error) {
+		return ed25519.Sign(key.PrivateKey, input), nil
+	})
+}
+
+// VerifyJWT checks the signature of a JWT signed by SignJWT and decodes its
+// claims into claims. Claims are not validated.
+func (s *TokenSigner) VerifyJWT(token string, claims interface{}) error {
+	header, input, payload, signature, err := splitJWT(token)
+	if err != nil {
+		return err
+	}
+	if header.Algorithm != jwtAlgEdDSA {
+		return ErrInvalidJWT
+	}
+
+	key, ok := s.ring.Lookup(header.KeyID)
+	if !ok {
+		return ErrUnknownSigningKey
+	}
+	if !ed25519.Verify(key.PublicKey().Key, input, signature) {
+		return ErrInvalidJWT
+	}
+
+	return decodeJWTClaims(payload, claims)
+}
+
+func encodeJWT(header jwtHeader, claims interface{}, sign func([]byte) ([]byte, error)) (string, error) {
+	encodedHeader, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	encodedClaims, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	input := base64.RawURLEncoding.EncodeToString(encodedHeader) + "." + base64.RawURLEncoding.EncodeToString(encodedClaims)
+	signature, err := sign([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// splitJWT decodes the parts of a compact JWS and returns its header, the
+// signing input, the payload and the signature.
+func splitJWT(token string) (jwtHeader, []byte, []byte, []byte, error) {
+	var header jwtHeader
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return header, nil, nil, nil, ErrInvalidJWT
+	}
+
+	encodedHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return header, nil, nil, nil, ErrInvalidJWT
+	}
+	if err := json.Unmarshal(encodedHeader, &header); err != nil {
+		return header, nil, nil, nil, ErrInvalidJWT
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return header, nil, nil, nil, ErrInvalidJWT
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return header, nil, nil, nil, ErrInvalidJWT
+	}
+
+	return header, []byte(parts[0] + "." + parts[1]), payload, signature, nil
+}
+
+// decodeJWTClaims decodes payload into claims, keeping numbers intact so
+// claims decoded into maps do not lose precision.
+func decodeJWTClaims(payload []byte, claims interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(claims); err != nil {
+		return ErrInvalidJWT
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+package user_management
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+type SigningKeyState string
+
+const (
+	// SigningKeyStateActive keys sign new tokens and verify tokens.
+	SigningKeyStateActive SigningKeyState = "active"
+	// SigningKeyStateVerifyOnly keys were rotated out. They verify the
+	// tokens they signed until their grace period ends.
+	SigningKeyStateVerifyOnly SigningKeyState = "verify_only"
+	// SigningKeyStateRetired keys no longer verify anything.
+	SigningKeyStateRetired SigningKeyState = "retired"
+)
+
+const (
+	// MinSigningKeyGracePeriod is the longest lifetime of any token
+	// AdminSuite signs, so a rotated key verifies every token it signed
+	// until that token has expired.
+	MinSigningKeyGracePeriod = emailVerificationTTL
+
+	// keyRingReloadInterval limits how often an unknown kid makes the ring
+	// reload its keys, in case another instance rotated them.
+	keyRingReloadInterval = 10 * time.Second
+)
+
+var (
+	ErrKeyStoreReadOnly       = errors.New("the signing key store is read-only")
+	ErrSigningKeyNotFound     = errors.New("signing key not found")
+	ErrRetireActiveSigningKey = errors.New("the active signing key cannot be retired, rotate it first")
+	ErrNoActiveSigningKey     = errors.New("the key ring has no active signing key")
+)
+
+// SigningKeyStore persists the keys of a key ring.
+type SigningKeyStore interface {
+	// Load returns every key of the ring, oldest first.
+	Load(ctx context.Context) ([]*SigningKey, error)
+	// Save adds added, if given, and saves the state of the changed keys
+	// in one step.
+	Save(ctx context.Context, added *SigningKey, changed []*SigningKey) error
+}
+
+// StaticKeyStore is a read-only store of a fixed set of keys, such as the
+// single key given in the configuration. Its keys cannot be rotated.
+type StaticKeyStore struct {
+	keys []*SigningKey
+}
+
+func NewStaticKeyStore(keys ...*SigningKey) *StaticKeyStore {
+	return &StaticKeyStore{keys: keys}
+}
+
+func (s *StaticKeyStore) Load(ctx context.Context) ([]*SigningKey, error) {
+	return s.keys, nil
+}
+
+func (s *StaticKeyStore) Save(ctx context.Context, added *SigningKey, changed []*SigningKey) error {
+	return ErrKeyStoreReadOnly
+}
+
+// KeyRing holds the keys tokens are signed and verified with. New tokens are
+// signed with the newest active key and verified against any key that is not
+// retired. Rotating the ring makes a new key active and demotes the previous
+// one to verify-only for a grace period, so sessions survive rotations.
+//
+// Several instances may share a store. Each reloads the ring periodically
+// and whenever it meets a kid it does not know, so keys rotated by one
+// instance are picked up by the others.
+type KeyRing struct {
+	store SigningKeyStore
+
+	mu       sync.RWMutex
+	keys     []*SigningKey
+	loadedAt time.Time
+}
+
+// NewKeyRing loads the ring from store. A store without an active key is
+// given bootstrap as its first key, or a generated one if bootstrap is nil,
+// so existing deployments keep verifying the tokens of their configured key.
+func NewKeyRing(ctx context.Context, store SigningKeyStore, bootstrap *SigningKey) (*KeyRing, error) {
+	ring := &KeyRing{store: store}
+	if err := ring.Reload(ctx); err != nil {
+		return nil, err
+	}
+	if ring.Active() != nil {
+		return ring, nil
+	}
+
+	if bootstrap == nil {
+		var err error
+		if bootstrap, err = GenerateSigningKey(); err != nil {
+			return nil, err
+		}
+	}
+	if err := store.Save(ctx, bootstrap, nil); err != nil {
+		return nil, fmt.Errorf("failed to store the first signing key: %w", err)
+	}
+	if err := ring.Reload(ctx); err != nil {
+		return nil, err
+	}
+	if ring.Active() == nil {
+		return nil, ErrNoActiveSigningKey
+	}
+	return ring, nil
+}
+
+// Reload replaces the keys of the ring with those in its store.
+func (r *KeyRing) Reload(ctx context.Context) error {
+	keys, err := r.store.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = keys
+	r.loadedAt = time.Now()
+	return nil
+}
+
+// Active returns the key new tokens are signed with: the most recently
+// created active key. Instances that bootstrapped the ring concurrently may
+// leave more than one active key; the others keep verifying.
+func (r *KeyRing) Active() *SigningKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return activeKey(r.keys)
+}
+
+// Lookup returns the key with ID keyID if it currently verifies tokens.
+func (r *KeyRing) Lookup(keyID string) (*SigningKey, bool) {
+	r.mu.RLock()
+	key := findKey(r.keys, keyID)
+	stale := time.Since(r.loadedAt) >= keyRingReloadInterval
+	r.mu.RUnlock()
+
+	if key == nil && stale {
+		if err := r.Reload(context.Background()); err != nil {
+			log.Printf("Failed to reload signing keys: %v", err)
+		}
+		r.mu.RLock()
+		key = findKey(r.keys, keyID)
+		r.mu.RUnlock()
+	}
+
+	if key == nil || !key.Verifies(time.Now()) {
+		return nil, false
+	}
+	return key, true
+}
+
+// Keys returns every key of the ring, oldest first.
+func (r *KeyRing) Keys() []*SigningKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]*SigningKey(nil), r.keys...)
+}
+
+// VerifyingKeys returns the keys that currently verify tokens.
+func (r *KeyRing) VerifyingKeys() []*SigningKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	keys := make([]*SigningKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		if key.Verifies(now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Rotate makes a new key active. The keys active until now verify the
+// tokens they signed for gracePeriod, and verify-only keys whose grace
+// period has ended are retired.
+func (r *KeyRing) Rotate(ctx context.Context, gracePeriod time.Duration) (*SigningKey, error) {
+	if gracePeriod < MinSigningKeyGracePeriod {
+		return nil, fmt.Errorf("the signing key grace period must be at least %s", MinSigningKeyGracePeriod)
+	}
+	if err := r.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	next, err := GenerateSigningKey()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	retireAt := now.Add(gracePeriod)
+	var changed []*SigningKey
+	for _, key := range r.Keys() {
+		switch {
+		case key.State == SigningKeyStateActive:
+			demoted := *key
+			demoted.State = SigningKeyStateVerifyOnly
+			demoted.RetireAt = &retireAt
+			changed = append(changed, &demoted)
+		case key.State == SigningKeyStateVerifyOnly && !key.Verifies(now):
+			retired := *key
+			retired.State = SigningKeyStateRetired
+			changed = append(changed, &retired)
+		}
+	}
+
+	if err := r.store.Save(ctx, next, changed); err != nil {
+		return nil, err
+	}
+	if err := r.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// RotateIfDue rotates the ring if its active key is older than interval and
+// returns the new key, or nil if no rotation was due.
+func (r *KeyRing) RotateIfDue(ctx context.Context, interval, gracePeriod time.Duration) (*SigningKey, error) {
+	if err := r.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	active := r.Active()
+	if active != nil && time.Since(active.CreatedAt) < interval {
+		return nil, nil
+	}
+	return r.Rotate(ctx, gracePeriod)
+}
+
+// Retire stops the verify-only key with ID keyID from verifying tokens
+// before its grace period ends, e.g. because it was compromised.
+func (r *KeyRing) Retire(ctx context.Context, keyID string) (*SigningKey, error) {
+	if err := r.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	key := findKey(r.keys, keyID)
+	r.mu.RUnlock()
+	if key == nil {
+		return nil, ErrSigningKeyNotFound
+	}
+	if key.State == SigningKeyStateActive {
+		return nil, ErrRetireActiveSigningKey
+	}
+
+	retired := *key
+	retired.State = SigningKeyStateRetired
+	if err := r.store.Save(ctx, nil, []*SigningKey{&retired}); err != nil {
+		return nil, err
+	}
+	if err := r.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return &retired, nil
+}
+
+func activeKey(keys []*SigningKey) *SigningKey {
+	var active *SigningKey
+	for _, key := range keys {
+		if key.State == SigningKeyStateActive && (active == nil || !key.CreatedAt.Before(active.CreatedAt)) {
+			active = key
+		}
+	}
+	return active
+}
+
+func findKey(keys []*SigningKey, keyID string) *SigningKey {
+	for _, key := range keys {
+		if key.ID == keyID {
+			return key
+		}
+	}
+	return nil
+}
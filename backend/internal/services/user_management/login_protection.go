@@ -0,0 +1,171 @@
+package user_management
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+var (
+	ErrAccountLocked   = errors.New("account temporarily locked")
+	ErrTooManyAttempts = errors.New("too many failed login attempts")
+)
+
+const (
+	maxUserAgentLength = 255
+	maxLockoutExponent = 16
+)
+
+// ClientInfo describes the caller of an authentication request.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+type LoginProtectionService struct {
+	userRepo         user_management.UserRepository
+	loginAttemptRepo user_management.LoginAttemptRepository
+}
+
+func NewLoginProtectionService(
+	userRepo user_management.UserRepository,
+	loginAttemptRepo user_management.LoginAttemptRepository,
+) *LoginProtectionService {
+	return &LoginProtectionService{
+		userRepo:         userRepo,
+		loginAttemptRepo: loginAttemptRepo,
+	}
+}
+
+// CheckIP refuses clients that produced too many failures within the
+// policy window in the tenant carried by ctx, regardless of which accounts
+// they targeted. Failures in other tenants, which have policies of their
+// own, do not count.
+func (s *LoginProtectionService) CheckIP(ctx context.Context, client ClientInfo, policy *AuthPolicy) error {
+	if client.IP == "" || policy.Lockout.IPMaxFailedAttempts <= 0 {
+		return nil
+	}
+
+	since := time.Now().Add(-time.Duration(policy.Lockout.IPWindowSeconds) * time.Second)
+	failures, err := s.loginAttemptRepo.CountFailuresByIP(ctx, client.IP, since)
+	if err != nil {
+		return err
+	}
+	if failures >= int64(policy.Lockout.IPMaxFailedAttempts) {
+		return ErrTooManyAttempts
+	}
+
+	return nil
+}
+
+func (s *LoginProtectionService) CheckUser(user *models.User) error {
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		return ErrAccountLocked
+	}
+	return nil
+}
+
+// RecordFailure stores a failed attempt and, for known accounts, extends the
+// lock with exponential backoff once the policy threshold is reached. The
+// counter is incremented in the database, so parallel attempts against one
+// account cannot overwrite each other's failures.
+func (s *LoginProtectionService) RecordFailure(ctx context.Context, user *models.User, email string, method models.LoginAttemptMethod, client ClientInfo, policy *AuthPolicy) error {
+	if err := s.record(ctx, user, email, method, client, false); err != nil {
+		return err
+	}
+
+	if user == nil {
+		return nil
+	}
+
+	failures, err := s.userRepo.IncrementFailedLoginCount(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	user.FailedLoginCount = failures
+
+	if policy.Lockout.MaxFailedAttempts <= 0 || failures < policy.Lockout.MaxFailedAttempts {
+		return nil
+	}
+
+	until := time.Now().Add(lockoutDuration(failures, policy.Lockout))
+	if err := s.userRepo.UpdateLockedUntil(ctx, user.ID, until); err != nil {
+		return err
+	}
+	user.LockedUntil = &until
+
+	return nil
+}
+
+// RecordSuccess stores a successful attempt. When the login is complete the
+// account's failure counter and lock are cleared.
+func (s *LoginProtectionService) RecordSuccess(ctx context.Context, user *models.User, method models.LoginAttemptMethod, client ClientInfo, complete bool) error {
+	if err := s.record(ctx, user, user.Email, method, client, true); err != nil {
+		return err
+	}
+
+	if !complete {
+		return nil
+	}
+
+	now := time.Now()
+	user.FailedLoginCount = 0
+	user.LockedUntil = nil
+	user.LastLoginAt = &now
+
+	return s.userRepo.Update(ctx, user)
+}
+
+func (s *LoginProtectionService) UnlockUser(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	user.FailedLoginCount = 0
+	user.LockedUntil = nil
+
+	return s.userRepo.Update(ctx, user)
+}
+
+func (s *LoginProtectionService) record(ctx context.Context, user *models.User, email string, method models.LoginAttemptMethod, client ClientInfo, success bool) error {
+	attempt := &models.LoginAttempt{
+		Email:     email,
+		IP:        client.IP,
+		UserAgent: truncate(client.UserAgent, maxUserAgentLength),
+		Method:    method,
+		Success:   success,
+	}
+	if user != nil {
+		attempt.UserID = user.ID
+	}
+
+	return s.loginAttemptRepo.Create(ctx, attempt)
+}
+
+// lockoutDuration doubles the base lock for every failure past the threshold
+// and stops doubling once the maximum is reached, so the shift cannot
+// overflow.
+func lockoutDuration(failures int, policy LockoutPolicy) time.Duration {
+	duration := time.Duration(policy.BaseLockoutSeconds) * time.Second
+	maxDuration := time.Duration(policy.MaxLockoutSeconds) * time.Second
+
+	exponent := failures - policy.MaxFailedAttempts
+	if exponent > maxLockoutExponent {
+		exponent = maxLockoutExponent
+	}
+	for ; exponent > 0 && duration < maxDuration; exponent-- {
+		duration <<= 1
+	}
+	if duration > maxDuration {
+		duration = maxDuration
+	}
+
+	return duration
+}
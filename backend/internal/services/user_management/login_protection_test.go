@@ -0,0 +1,68 @@
+package user_management
+
+import (
+	"testing"
+	"time"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/tenancy"
+)
+
+func TestIPLockoutIsScopedToTenant(t *testing.T) {
+	st := newServiceTest(t)
+
+	other := &models.Tenant{Name: "Globex", Domain: "globex.test"}
+	if err := st.db.Create(other).Error; err != nil {
+		t.Fatalf("failed to create tenant: %v", err)
+	}
+	otherCtx := tenancy.WithTenant(st.ctx, other.ID)
+
+	policy := &AuthPolicy{Lockout: LockoutPolicy{IPMaxFailedAttempts: 3, IPWindowSeconds: 900}}
+	client := ClientInfo{IP: "203.0.113.7"}
+	for attempt := 0; attempt < 3; attempt++ {
+		if err := st.loginProtection.RecordFailure(st.ctx, nil, "nobody@acme.test", models.LoginAttemptMethodPassword, client, policy); err != nil {
+			t.Fatalf("failed to record failure: %v", err)
+		}
+	}
+
+	if err := st.loginProtection.CheckIP(st.ctx, client, policy); err != ErrTooManyAttempts {
+		t.Fatalf("CheckIP in the tenant of the failures returned %v, want ErrTooManyAttempts", err)
+	}
+	if err := st.loginProtection.CheckIP(otherCtx, client, policy); err != nil {
+		t.Fatalf("failures in another tenant locked the client out: %v", err)
+	}
+}
+
+func TestLoginToUnknownAccountIsRecordedInTenant(t *testing.T) {
+	st := newServiceTest(t)
+
+	if _, _, _, err := st.authService.AuthenticateUser(st.ctx, "nobody@acme.test", "Secret-pass-1", "", ClientInfo{IP: "203.0.113.7"}); err == nil {
+		t.Fatalf("login to an unknown account succeeded")
+	}
+
+	var attempt models.LoginAttempt
+	if err := st.db.WithContext(st.ctx).First(&attempt, "email = ?", "nobody@acme.test").Error; err != nil {
+		t.Fatalf("failed login was not recorded: %v", err)
+	}
+	if attempt.TenantID != st.tenant.ID || attempt.Success {
+		t.Fatalf("recorded attempt = %+v, want a failure in tenant %s", attempt, st.tenant.ID)
+	}
+}
+
+func TestLoginToUnknownAccountTakesAsLongAsWrongPassword(t *testing.T) {
+	st := newServiceTest(t)
+	st.createUser(t, "quinn@acme.test", "Secret-pass-1")
+
+	elapsed := func(email string) time.Duration {
+		start := time.Now()
+		if _, _, _, err := st.authService.AuthenticateUser(st.ctx, email, "Wrong-pass-1", "", ClientInfo{}); err == nil {
+			t.Fatalf("login to %s with a wrong password succeeded", email)
+		}
+		return time.Since(start)
+	}
+
+	known, unknown := elapsed("quinn@acme.test"), elapsed("nobody@acme.test")
+	if unknown < known/2 {
+		t.Fatalf("login to an unknown account took %v, a wrong password %v", unknown, known)
+	}
+}
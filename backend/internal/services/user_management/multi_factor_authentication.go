@@ -1,10 +1,11 @@
 package user_management
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base32"
+	"errors"
 	"fmt"
-	"net/smtp"
 	"time"
 
 	"github.com/pquerna/otp"
@@ -18,26 +19,63 @@ import (
 	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
 )
 
+var (
+	ErrMFAMethodNotAllowed = errors.New("MFA method not allowed by the tenant's auth policy")
+	ErrMFARequiredByPolicy = errors.New("MFA is required by the tenant's auth policy")
+)
+
 type MFAService struct {
-	userRepo user_management.UserRepository
-	config   *config.Config
-	twilio   *twilio.RestClient
+	userRepo        user_management.UserRepository
+	config          *config.Config
+	twilio          *twilio.RestClient
+	emailService    *EmailService
+	policyService   *AuthPolicyService
+	brandingService *BrandingService
+	securityStamps  *SecurityStampService
 }
 
-func NewMFAService(userRepo user_management.UserRepository, config *config.Config) *MFAService {
+func NewMFAService(userRepo user_management.UserRepository, config *config.Config, emailService *EmailService, policyService *AuthPolicyService, brandingService *BrandingService, securityStamps *SecurityStampService) *MFAService {
 	twilioClient := twilio.NewRestClientWithParams(twilio.ClientParams{
 		Username: config.TwilioAccountSID,
 		Password: config.TwilioAuthToken,
 	})
 
 	return &MFAService{
-		userRepo: userRepo,
-		config:   config,
-		twilio:   twilioClient,
+		userRepo:        userRepo,
+		config:          config,
+		twilio:          twilioClient,
+		emailService:    emailService,
+		policyService:   policyService,
+		brandingService: brandingService,
+		securityStamps:  securityStamps,
 	}
 }
 
-func (s *MFAService) GenerateTOTPSecret(user *models.User) (string, error) {
+// DisableMFA removes the user's second factor unless the tenant's auth
+// policy makes MFA mandatory.
+func (s *MFAService) DisableMFA(ctx context.Context, user *models.User) error {
+	policy, err := s.policyService.GetPolicy(ctx, user.TenantID)
+	if err != nil {
+		return err
+	}
+	if policy.MFA.Required {
+		return ErrMFARequiredByPolicy
+	}
+
+	wasEnabled := user.MFAEnabled
+	user.MFAEnabled = false
+	user.MFASecret = ""
+	user.MFAMethod = ""
+	user.MFABackupCodes = nil
+
+	return s.saveMFAStatus(ctx, user, wasEnabled)
+}
+
+func (s *MFAService) GenerateTOTPSecret(ctx context.Context, user *models.User) (string, error) {
+	if err := s.checkMethodAllowed(ctx, user, models.MFAMethodTOTP); err != nil {
+		return "", err
+	}
+
 	secret := make([]byte, 32)
 	_, err := rand.Read(secret)
 	if err != nil {
@@ -46,10 +84,11 @@ func (s *MFAService) GenerateTOTPSecret(user *models.User) (string, error) {
 
 	secretBase32 := base32.StdEncoding.EncodeToString(secret)
 	user.MFASecret = secretBase32
+	wasEnabled := user.MFAEnabled
 	user.MFAEnabled = false
 	user.MFAMethod = models.MFAMethodTOTP
 
-	err = s.userRepo.Update(user)
+	err = s.saveMFAStatus(ctx, user, wasEnabled)
 	if err != nil {
 		return "", err
 	}
@@ -57,13 +96,20 @@ func (s *MFAService) GenerateTOTPSecret(user *models.User) (string, error) {
 	return secretBase32, nil
 }
 
-func (s *MFAService) GenerateTOTPQRCode(user *models.User) (string, error) {
+// GenerateTOTPQRCode returns the otpauth URL for the user's TOTP secret,
+// issued under the tenant's branding.
+func (s *MFAService) GenerateTOTPQRCode(ctx context.Context, user *models.User) (string, error) {
 	if user.MFASecret == "" {
 		return "", fmt.Errorf("MFA secret not set for user")
 	}
 
+	branding, err := s.brandingService.GetBranding(ctx, user.TenantID)
+	if err != nil {
+		return "", err
+	}
+
 	key, err := totp.Generate(totp.GenerateOpts{
-		Issuer:      "AdminSuite",
+		Issuer:      branding.Issuer(),
 		AccountName: user.Email,
 		Secret:      []byte(user.MFASecret),
 	})
@@ -74,15 +120,16 @@ func (s *MFAService) GenerateTOTPQRCode(user *models.User) (string, error) {
 	return key.URL(), nil
 }
 
-func (s *MFAService) VerifyTOTP(user *models.User, token string) (bool, error) {
+func (s *MFAService) VerifyTOTP(ctx context.Context, user *models.User, token string) (bool, error) {
 	if user.MFASecret == "" {
 		return false, fmt.Errorf("MFA not set up for user")
 	}
 
 	valid := totp.Validate(token, user.MFASecret)
 	if valid {
+		wasEnabled := user.MFAEnabled
 		user.MFAEnabled = true
-		err := s.userRepo.Update(user)
+		err := s.saveMFAStatus(ctx, user, wasEnabled)
 		if err != nil {
 			return true, fmt.Errorf("failed to update user MFA status: %v", err)
 		}
@@ -91,7 +138,7 @@ func (s *MFAService) VerifyTOTP(user *models.User, token string) (bool, error) {
 	return valid, nil
 }
 
-func (s *MFAService) GenerateBackupCodes(user *models.User) ([]string, error) {
+func (s *MFAService) GenerateBackupCodes(ctx context.Context, user *models.User) ([]string, error) {
 	codes := make([]string, 10)
 	for i := 0; i < 10; i++ {
 		code, err := generateRandomCode(8)
@@ -102,7 +149,7 @@ func (s *MFAService) GenerateBackupCodes(user *models.User) ([]string, error) {
 	}
 
 	user.MFABackupCodes = codes
-	err := s.userRepo.Update(user)
+	err := s.userRepo.Update(ctx, user)
 	if err != nil {
 		return nil, err
 	}
@@ -110,12 +157,12 @@ func (s *MFAService) GenerateBackupCodes(user *models.User) ([]string, error) {
 	return codes, nil
 }
 
-func (s *MFAService) VerifyBackupCode(user *models.User, code string) (bool, error) {
+func (s *MFAService) VerifyBackupCode(ctx context.Context, user *models.User, code string) (bool, error) {
 	for i, storedCode := range user.MFABackupCodes {
 		if storedCode == code {
 			// Remove the used backup code
 			user.MFABackupCodes = append(user.MFABackupCodes[:i], user.MFABackupCodes[i+1:]...)
-			err := s.userRepo.Update(user)
+			err := s.userRepo.Update(ctx, user)
 			if err != nil {
 				return true, fmt.Errorf("failed to update user backup codes: %v", err)
 			}
@@ -125,21 +172,30 @@ func (s *MFAService) VerifyBackupCode(user *models.User, code string) (bool, err
 	return false, nil
 }
 
-func (s *MFAService) GenerateSMSCode(user *models.User) (string, error) {
+func (s *MFAService) GenerateSMSCode(ctx context.Context, user *models.User) (string, error) {
+	if err := s.checkMethodAllowed(ctx, user, models.MFAMethodSMS); err != nil {
+		return "", err
+	}
+
 	code, err := generateRandomCode(6)
 	if err != nil {
 		return "", err
 	}
 
+	branding, err := s.brandingService.GetBranding(ctx, user.TenantID)
+	if err != nil {
+		return "", err
+	}
+
 	user.MFASMSCode = code
 	user.MFASMSCodeExpiry = time.Now().Add(5 * time.Minute)
 	user.MFAMethod = models.MFAMethodSMS
-	err = s.userRepo.Update(user)
+	err = s.userRepo.Update(ctx, user)
 	if err != nil {
 		return "", err
 	}
 
-	err = s.sendSMS(user.PhoneNumber, fmt.Sprintf("Your AdminSuite verification code is: %s", code))
+	err = s.sendSMS(user.PhoneNumber, fmt.Sprintf("Your %s verification code is: %s", branding.ProductName, code))
 	if err != nil {
 		return "", err
 	}
@@ -147,16 +203,17 @@ func (s *MFAService) GenerateSMSCode(user *models.User) (string, error) {
 	return code, nil
 }
 
-func (s *MFAService) VerifySMSCode(user *models.User, code string) (bool, error) {
+func (s *MFAService) VerifySMSCode(ctx context.Context, user *models.User, code string) (bool, error) {
 	if user.MFASMSCode == "" || time.Now().After(user.MFASMSCodeExpiry) {
 		return false, fmt.Errorf("SMS code expired or not set")
 	}
 
 	if user.MFASMSCode == code {
+		wasEnabled := user.MFAEnabled
 		user.MFAEnabled = true
 		user.MFASMSCode = ""
 		user.MFASMSCodeExpiry = time.Time{}
-		err := s.userRepo.Update(user)
+		err := s.saveMFAStatus(ctx, user, wasEnabled)
 		if err != nil {
 			return true, fmt.Errorf("failed to update user MFA status: %v", err)
 		}
@@ -166,21 +223,30 @@ func (s *MFAService) VerifySMSCode(user *models.User, code string) (bool, error)
 	return false, nil
 }
 
-func (s *MFAService) GenerateEmailCode(user *models.User) (string, error) {
+func (s *MFAService) GenerateEmailCode(ctx context.Context, user *models.User) (string, error) {
+	if err := s.checkMethodAllowed(ctx, user, models.MFAMethodEmail); err != nil {
+		return "", err
+	}
+
 	code, err := generateRandomCode(6)
 	if err != nil {
 		return "", err
 	}
 
+	branding, err := s.brandingService.GetBranding(ctx, user.TenantID)
+	if err != nil {
+		return "", err
+	}
+
 	user.MFAEmailCode = code
 	user.MFAEmailCodeExpiry = time.Now().Add(15 * time.Minute)
 	user.MFAMethod = models.MFAMethodEmail
-	err = s.userRepo.Update(user)
+	err = s.userRepo.Update(ctx, user)
 	if err != nil {
 		return "", err
 	}
 
-	err = s.sendEmail(user.Email, "AdminSuite MFA Code", fmt.Sprintf("Your verification code is: %s", code))
+	err = s.emailService.SendBrandedEmail(branding, user.Email, fmt.Sprintf("%s MFA Code", branding.ProductName), fmt.Sprintf("Your verification code is: %s", code))
 	if err != nil {
 		return "", err
 	}
@@ -188,16 +254,17 @@ func (s *MFAService) GenerateEmailCode(user *models.User) (string, error) {
 	return code, nil
 }
 
-func (s *MFAService) VerifyEmailCode(user *models.User, code string) (bool, error) {
+func (s *MFAService) VerifyEmailCode(ctx context.Context, user *models.User, code string) (bool, error) {
 	if user.MFAEmailCode == "" || time.Now().After(user.MFAEmailCodeExpiry) {
 		return false, fmt.Errorf("email code expired or not set")
 	}
 
 	if user.MFAEmailCode == code {
+		wasEnabled := user.MFAEnabled
 		user.MFAEnabled = true
 		user.MFAEmailCode = ""
 		user.MFAEmailCodeExpiry = time.Time{}
-		err := s.userRepo.Update(user)
+		err := s.saveMFAStatus(ctx, user, wasEnabled)
 		if err != nil {
 			return true, fmt.Errorf("failed to update user MFA status: %v", err)
 		}
@@ -207,7 +274,11 @@ func (s *MFAService) VerifyEmailCode(user *models.User, code string) (bool, erro
 	return false, nil
 }
 
-func (s *MFAService) GenerateHOTP(user *models.User) (string, error) {
+func (s *MFAService) GenerateHOTP(ctx context.Context, user *models.User) (string, error) {
+	if err := s.checkMethodAllowed(ctx, user, models.MFAMethodHOTP); err != nil {
+		return "", err
+	}
+
 	secret := make([]byte, 32)
 	_, err := rand.Read(secret)
 	if err != nil {
@@ -216,11 +287,12 @@ func (s *MFAService) GenerateHOTP(user *models.User) (string, error) {
 
 	secretBase32 := base32.StdEncoding.EncodeToString(secret)
 	user.MFASecret = secretBase32
+	wasEnabled := user.MFAEnabled
 	user.MFAEnabled = false
 	user.MFAMethod = models.MFAMethodHOTP
 	user.MFAHOTPCounter = 0
 
-	err = s.userRepo.Update(user)
+	err = s.saveMFAStatus(ctx, user, wasEnabled)
 	if err != nil {
 		return "", err
 	}
@@ -228,7 +300,7 @@ func (s *MFAService) GenerateHOTP(user *models.User) (string, error) {
 	return secretBase32, nil
 }
 
-func (s *MFAService) VerifyHOTP(user *models.User, token string) (bool, error) {
+func (s *MFAService) VerifyHOTP(ctx context.Context, user *models.User, token string) (bool, error) {
 	if user.MFASecret == "" {
 		return false, fmt.Errorf("HOTP not set up for user")
 	}
@@ -247,9 +319,10 @@ func (s *MFAService) VerifyHOTP(user *models.User, token string) (bool, error) {
 	}
 
 	if valid {
+		wasEnabled := user.MFAEnabled
 		user.MFAEnabled = true
 		user.MFAHOTPCounter++
-		err := s.userRepo.Update(user)
+		err := s.saveMFAStatus(ctx, user, wasEnabled)
 		if err != nil {
 			return true, fmt.Errorf("failed to update user MFA status: %v", err)
 		}
@@ -258,6 +331,30 @@ func (s *MFAService) VerifyHOTP(user *models.User, token string) (bool, error) {
 	return valid, nil
 }
 
+// saveMFAStatus stores user and, if MFAEnabled changed from wasEnabled,
+// rotates the user's security stamp so access tokens stop vouching for the
+// old MFA status.
+func (s *MFAService) saveMFAStatus(ctx context.Context, user *models.User, wasEnabled bool) error {
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+	if user.MFAEnabled == wasEnabled {
+		return nil
+	}
+	return s.securityStamps.Rotate(ctx, user)
+}
+
+func (s *MFAService) checkMethodAllowed(ctx context.Context, user *models.User, method models.MFAMethod) error {
+	policy, err := s.policyService.GetPolicy(ctx, user.TenantID)
+	if err != nil {
+		return err
+	}
+	if !policy.AllowsMFAMethod(method) {
+		return ErrMFAMethodNotAllowed
+	}
+	return nil
+}
+
 func (s *MFAService) sendSMS(phoneNumber, message string) error {
 	params := &twilioApi.CreateMessageParams{}
 	params.SetTo(phoneNumber)
@@ -268,24 +365,6 @@ func (s *MFAService) sendSMS(phoneNumber, message string) error {
 	return err
 }
 
-func (s *MFAService) sendEmail(to, subject, body string) error {
-	auth := smtp.PlainAuth("", s.config.SMTPUsername, s.config.SMTPPassword, s.config.SMTPHost)
-
-	msg := []byte(fmt.Sprintf("To: %s\r\n"+
-		"From: %s\r\n"+
-		"Subject: %s\r\n"+
-		"\r\n"+
-		"%s\r\n", to, s.config.SMTPFrom, subject, body))
-
-	err := smtp.SendMail(fmt.Sprintf("%s:%d", s.config.SMTPHost, s.config.SMTPPort),
-		auth,
-		s.config.SMTPFrom,
-		[]string{to},
-		msg)
-
-	return err
-}
-
 func generateRandomCode(length int) (string, error) {
 	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	code := make([]byte, length)
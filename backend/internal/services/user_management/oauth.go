@@ -0,0 +1,627 @@
+package user_management
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+const (
+	// ScopeOfflineAccess is the scope a client requests to be issued a
+	// refresh token. It grants no permissions of its own.
+	ScopeOfflineAccess = "offline_access"
+
+	ResponseTypeCode           = "code"
+	CodeChallengeMethodS256    = "S256"
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+
+	// oauthCodeTTL is how long an authorization code can be exchanged.
+	oauthCodeTTL = time.Minute
+)
+
+// Error codes of RFC 6749 returned by the authorization and token endpoints.
+const (
+	OAuthErrorInvalidRequest          = "invalid_request"
+	OAuthErrorInvalidClient           = "invalid_client"
+	OAuthErrorInvalidGrant            = "invalid_grant"
+	OAuthErrorUnauthorizedClient      = "unauthorized_client"
+	OAuthErrorUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthErrorUnsupportedResponseType = "unsupported_response_type"
+	OAuthErrorInvalidScope            = "invalid_scope"
+	OAuthErrorAccessDenied            = "access_denied"
+)
+
+var (
+	ErrOAuthClientNotFound = errors.New("OAuth client not found")
+	ErrInvalidRedirectURI  = errors.New("redirect_uri is not registered for the client")
+	ErrInvalidScope        = errors.New("scope differs from the scope granted")
+)
+
+// reservedScopes are the scopes AdminSuite defines itself, with the
+// description shown on the consent screen. Tenants cannot register scopes
+// of the same name.
+var reservedScopes = map[string]string{
+	ScopeOfflineAccess: "Stay signed in to the application while you are away",
+	ScopeOpenID:        "Sign you in with your AdminSuite account",
+	ScopeProfile:       "See your name, username and profile picture",
+	ScopeEmail:         "See your email address",
+	ScopePhone:         "See your phone number",
+}
+
+// codeVerifierPattern is the PKCE code verifier syntax of RFC 7636.
+var codeVerifierPattern = regexp.MustCompile(`^[A-Za-z0-9._~-]{43,128}$`)
+
+// OAuthError is an error response of RFC 6749. Code is one of the
+// OAuthError* codes and Description a human-readable explanation.
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func newOAuthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// AuthorizationRequest holds the parameters of an authorization request.
+type AuthorizationRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	// Nonce is the OpenID Connect nonce to echo in the ID token.
+	Nonce string
+}
+
+// ErrorRedirect returns the URI the user agent is sent back to when the
+// request fails with err after the client and redirect URI were verified.
+func (r AuthorizationRequest) ErrorRedirect(err *OAuthError) string {
+	params := url.Values{"error": {err.Code}}
+	if err.Description != "" {
+		params.Set("error_description", err.Description)
+	}
+	return r.redirect(params)
+}
+
+func (r AuthorizationRequest) redirect(params url.Values) string {
+	if r.State != "" {
+		params.Set("state", r.State)
+	}
+
+	target, err := url.Parse(r.RedirectURI)
+	if err != nil {
+		return r.RedirectURI
+	}
+	query := target.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	target.RawQuery = query.Encode()
+	return target.String()
+}
+
+// ScopeDescription describes a requested scope on the consent screen.
+type ScopeDescription struct {
+	Name        string
+	Description string
+}
+
+// AuthorizationPrompt is what the user is asked to approve: Client wants
+// Scopes. ConsentRequired is false for trusted clients and for clients the
+// user already allowed every requested scope.
+type AuthorizationPrompt struct {
+	Client          *models.OAuthClient
+	Scopes          []ScopeDescription
+	ConsentRequired bool
+}
+
+// TokenRequest holds the parameters of a token request. The client
+// authenticates with ClientID and, unless it is public, ClientSecret.
+type TokenRequest struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+}
+
+// OAuthService is the OAuth 2.1 authorization server. Registered clients
+// sign users in with the authorization code grant, which requires PKCE with
+// S256, and keep them signed in with rotating refresh tokens. Tokens are
+// AdminSuite access tokens limited to the permissions of the granted scopes,
+// along with an OpenID Connect ID token if openid was granted.
+type OAuthService struct {
+	oauthClientRepo user_management.OAuthClientRepository
+	oauthScopeRepo  user_management.OAuthScopeRepository
+	consentRepo     user_management.OAuthConsentRepository
+	codeRepo        user_management.OAuthAuthorizationCodeRepository
+	userRepo        user_management.UserRepository
+	authService     *AuthenticationService
+	sessionService  *SessionService
+	oidcService     *OIDCService
+	tokenHasher     *TokenHasher
+	auditService    *AuditService
+}
+
+func NewOAuthService(
+	oauthClientRepo user_management.OAuthClientRepository,
+	oauthScopeRepo user_management.OAuthScopeRepository,
+	consentRepo user_management.OAuthConsentRepository,
+	codeRepo user_management.OAuthAuthorizationCodeRepository,
+	userRepo user_management.UserRepository,
+	authService *AuthenticationService,
+	sessionService *SessionService,
+	oidcService *OIDCService,
+	tokenHasher *TokenHasher,
+	auditService *AuditService,
+) *OAuthService {
+	return &OAuthService{
+		oauthClientRepo: oauthClientRepo,
+		oauthScopeRepo:  oauthScopeRepo,
+		consentRepo:     consentRepo,
+		codeRepo:        codeRepo,
+		userRepo:        userRepo,
+		authService:     authService,
+		sessionService:  sessionService,
+		oidcService:     oidcService,
+		tokenHasher:     tokenHasher,
+		auditService:    auditService,
+	}
+}
+
+// PrepareAuthorization validates req for user and returns what user is asked
+// to approve. An unknown client or unregistered redirect URI is reported as
+// ErrOAuthClientNotFound or ErrInvalidRedirectURI and must not be redirected
+// to; any other problem is an *OAuthError for the client.
+func (s *OAuthService) PrepareAuthorization(ctx context.Context, user *models.User, req AuthorizationRequest) (*AuthorizationPrompt, error) {
+	oauthClient, scopes, err := s.validateAuthorization(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	consentRequired, err := s.consentRequired(ctx, user, oauthClient, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthorizationPrompt{
+		Client:          oauthClient,
+		Scopes:          scopes,
+		ConsentRequired: consentRequired,
+	}, nil
+}
+
+// Authorize completes req for user, signed in with the access token that
+// has claims, and returns the URI to send the user agent back to. If user
+// approved the request it carries a new authorization code; otherwise an
+// access_denied error. Errors are reported as by PrepareAuthorization.
+func (s *OAuthService) Authorize(ctx context.Context, user *models.User, claims *AccessClaims, req AuthorizationRequest, approved bool, client ClientInfo) (string, error) {
+	oauthClient, scopes, err := s.validateAuthorization(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	names := scopeNames(scopes)
+
+	if !approved {
+		s.recordGrant(ctx, user, AuditActionOAuthConsentDenied, oauthClient, map[string]interface{}{"scopes": names}, client)
+		return req.ErrorRedirect(newOAuthError(OAuthErrorAccessDenied, "The user denied the request")), nil
+	}
+
+	consentRequired, err := s.consentRequired(ctx, user, oauthClient, scopes)
+	if err != nil {
+		return "", err
+	}
+	if consentRequired {
+		if err := s.saveConsent(ctx, user, oauthClient, names); err != nil {
+			return "", err
+		}
+	}
+
+	rawCode, codeHash, err := s.tokenHasher.NewToken()
+	if err != nil {
+		return "", err
+	}
+	code := &models.OAuthAuthorizationCode{
+		TenantID:      oauthClient.TenantID,
+		OAuthClientID: oauthClient.ID,
+		UserID:        user.ID,
+		CodeHash:      codeHash,
+		RedirectURI:   req.RedirectURI,
+		Scopes:        strings.Join(names, " "),
+		CodeChallenge: req.CodeChallenge,
+		AuthMethod:    claims.AuthMethod,
+		Nonce:         req.Nonce,
+		ExpiresAt:     time.Now().Add(oauthCodeTTL),
+	}
+	if claims.SessionID != uuid.Nil {
+		code.ParentSessionID = &claims.SessionID
+	}
+	if err := s.codeRepo.Create(ctx, code); err != nil {
+		return "", err
+	}
+
+	s.recordGrant(ctx, user, AuditActionOAuthAuthorize, oauthClient, map[string]interface{}{"scopes": names}, client)
+
+	return req.redirect(url.Values{"code": {rawCode}}), nil
+}
+
+// Exchange serves a token request. Failures the client can act on are
+// returned as *OAuthError.
+func (s *OAuthService) Exchange(ctx context.Context, req TokenRequest, client ClientInfo) (*ClientTokens, error) {
+	oauthClient, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case GrantTypeAuthorizationCode:
+		return s.exchangeCode(ctx, oauthClient, req, client)
+	case GrantTypeRefreshToken:
+		return s.exchangeRefreshToken(ctx, oauthClient, req, client)
+	case "":
+		return nil, newOAuthError(OAuthErrorInvalidRequest, "grant_type is required")
+	default:
+		return nil, newOAuthError(OAuthErrorUnsupportedGrantType, "Only the authorization_code and refresh_token grants are supported")
+	}
+}
+
+func (s *OAuthService) exchangeCode(ctx context.Context, oauthClient *models.OAuthClient, req TokenRequest, client ClientInfo) (*ClientTokens, error) {
+	if req.Code == "" || req.RedirectURI == "" || req.CodeVerifier == "" {
+		return nil, newOAuthError(OAuthErrorInvalidRequest, "code, redirect_uri and code_verifier are required")
+	}
+
+	invalidCode := newOAuthError(OAuthErrorInvalidGrant, "The authorization code is invalid, expired or was already used")
+	code, err := s.codeRepo.FindByCodeHash(ctx, s.tokenHasher.Hash(req.Code))
+	if err != nil || code.OAuthClientID != oauthClient.ID {
+		return nil, invalidCode
+	}
+	if code.UsedAt != nil {
+		return nil, s.codeReused(ctx, code, oauthClient, client)
+	}
+	if code.ExpiresAt.Before(time.Now()) {
+		return nil, invalidCode
+	}
+
+	// The code is spent before the verifier is checked, so a stolen code
+	// cannot be used to guess the verifier.
+	marked, err := s.codeRepo.MarkUsed(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		return nil, s.codeReused(ctx, code, oauthClient, client)
+	}
+
+	if req.RedirectURI != code.RedirectURI {
+		return nil, newOAuthError(OAuthErrorInvalidGrant, "redirect_uri does not match the authorization request")
+	}
+	if !verifyCodeChallenge(req.CodeVerifier, code.CodeChallenge) {
+		return nil, newOAuthError(OAuthErrorInvalidGrant, "code_verifier does not match the code challenge")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, code.UserID)
+	if err != nil || !user.IsActive {
+		return nil, invalidCode
+	}
+
+	tokens, err := s.authService.GenerateClientTokens(ctx, user, oauthClient, code.ParentSessionID, strings.Fields(code.Scopes), code.AuthMethod, client)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.issueIDToken(ctx, user, oauthClient, tokens, code.Nonce); err != nil {
+		return nil, err
+	}
+	if err := s.codeRepo.SetSessionID(ctx, code.ID, tokens.Session.ID); err != nil {
+		return nil, err
+	}
+
+	s.recordGrant(ctx, user, AuditActionOAuthToken, oauthClient, map[string]interface{}{
+		"grant_type": GrantTypeAuthorizationCode,
+		"scopes":     tokens.Scopes,
+		"session_id": tokens.Session.ID,
+	}, client)
+
+	return tokens, nil
+}
+
+func (s *OAuthService) exchangeRefreshToken(ctx context.Context, oauthClient *models.OAuthClient, req TokenRequest, client ClientInfo) (*ClientTokens, error) {
+	if req.RefreshToken == "" {
+		return nil, newOAuthError(OAuthErrorInvalidRequest, "refresh_token is required")
+	}
+
+	var scopes []string
+	if req.Scope != "" {
+		scopes = parseScopes(req.Scope)
+	}
+
+	tokens, err := s.authService.RefreshClientTokens(ctx, req.RefreshToken, oauthClient, scopes, client)
+	if err != nil {
+		if err == ErrInvalidScope {
+			return nil, newOAuthError(OAuthErrorInvalidScope, "scope must be omitted or equal the scope granted")
+		}
+		return nil, newOAuthError(OAuthErrorInvalidGrant, "The refresh token is invalid, expired or was revoked")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, tokens.Session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.issueIDToken(ctx, user, oauthClient, tokens, ""); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// issueIDToken adds an ID token to tokens if they were granted openid.
+func (s *OAuthService) issueIDToken(ctx context.Context, user *models.User, oauthClient *models.OAuthClient, tokens *ClientTokens, nonce string) error {
+	if !hasScope(tokens.Scopes, ScopeOpenID) {
+		return nil
+	}
+
+	idToken, err := s.oidcService.IDToken(ctx, user, oauthClient, tokens.Session, nonce, tokens.ExpiresIn)
+	if err != nil {
+		return err
+	}
+	tokens.IDToken = idToken
+	return nil
+}
+
+// codeReused handles an authorization code presented again. The code may
+// have been intercepted, so the session it started is ended.
+func (s *OAuthService) codeReused(ctx context.Context, code *models.OAuthAuthorizationCode, oauthClient *models.OAuthClient, client ClientInfo) error {
+	if code.SessionID != nil {
+		if err := s.sessionService.EndSession(ctx, *code.SessionID); err != nil {
+			return err
+		}
+	}
+
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    code.UserID,
+		Action:     AuditActionOAuthCodeReuse,
+		Resource:   AuditResourceOAuthClient,
+		ResourceID: oauthClient.ID.String(),
+		Details:    map[string]interface{}{"session_id": code.SessionID},
+		Client:     client,
+	})
+
+	return newOAuthError(OAuthErrorInvalidGrant, "The authorization code is invalid, expired or was already used")
+}
+
+// authenticateClient returns the client with ID clientID. Confidential
+// clients must present their secret; public clients only identify
+// themselves and rely on PKCE instead.
+func (s *OAuthService) authenticateClient(ctx context.Context, clientID, secret string) (*models.OAuthClient, error) {
+	invalidClient := newOAuthError(OAuthErrorInvalidClient, "Client authentication failed")
+	if clientID == "" {
+		return nil, invalidClient
+	}
+
+	oauthClient, err := s.oauthClientRepo.FindByClientID(ctx, clientID)
+	if err != nil {
+		return nil, invalidClient
+	}
+	if oauthClient.Public {
+		return oauthClient, nil
+	}
+	if secret == "" || subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(oauthClient.SecretHash)) != 1 {
+		return nil, invalidClient
+	}
+	return oauthClient, nil
+}
+
+// validateAuthorization checks req and returns its client and the requested
+// scopes, sorted by name.
+func (s *OAuthService) validateAuthorization(ctx context.Context, req AuthorizationRequest) (*models.OAuthClient, []ScopeDescription, error) {
+	if req.ClientID == "" {
+		return nil, nil, ErrOAuthClientNotFound
+	}
+	oauthClient, err := s.oauthClientRepo.FindByClientID(ctx, req.ClientID)
+	if err != nil {
+		return nil, nil, ErrOAuthClientNotFound
+	}
+
+	redirectURIs, err := decodeStringList(oauthClient.RedirectURIs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !hasScope(redirectURIs, req.RedirectURI) {
+		return nil, nil, ErrInvalidRedirectURI
+	}
+
+	if req.ResponseType != ResponseTypeCode {
+		return nil, nil, newOAuthError(OAuthErrorUnsupportedResponseType, "Only the code response type is supported")
+	}
+	if req.CodeChallenge == "" {
+		return nil, nil, newOAuthError(OAuthErrorInvalidRequest, "code_challenge is required")
+	}
+	if req.CodeChallengeMethod != CodeChallengeMethodS256 {
+		return nil, nil, newOAuthError(OAuthErrorInvalidRequest, "code_challenge_method must be S256")
+	}
+	if len(req.CodeChallenge) != base64.RawURLEncoding.EncodedLen(sha256.Size) {
+		return nil, nil, newOAuthError(OAuthErrorInvalidRequest, "code_challenge must be a base64url-encoded SHA-256 hash")
+	}
+
+	scopes, err := s.resolveScopes(ctx, oauthClient, parseScopes(req.Scope))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return oauthClient, scopes, nil
+}
+
+// resolveScopes describes the requested scopes, which must all be allowed
+// for oauthClient and be either reserved or registered with the tenant.
+func (s *OAuthService) resolveScopes(ctx context.Context, oauthClient *models.OAuthClient, requested []string) ([]ScopeDescription, error) {
+	if len(requested) == 0 {
+		return nil, newOAuthError(OAuthErrorInvalidScope, "scope is required")
+	}
+
+	allowed, err := decodeStringList(oauthClient.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptions := make([]ScopeDescription, 0, len(requested))
+	var custom []string
+	for _, name := range requested {
+		if !hasScope(allowed, name) {
+			return nil, newOAuthError(OAuthErrorInvalidScope, fmt.Sprintf("The client may not request the %s scope", name))
+		}
+		if description, ok := reservedScopes[name]; ok {
+			descriptions = append(descriptions, ScopeDescription{Name: name, Description: description})
+			continue
+		}
+		custom = append(custom, name)
+	}
+
+	registered, err := s.oauthScopeRepo.FindByNames(ctx, custom)
+	if err != nil {
+		return nil, err
+	}
+	if len(registered) != len(custom) {
+		return nil, newOAuthError(OAuthErrorInvalidScope, "The request names an unknown scope")
+	}
+	for _, scope := range registered {
+		descriptions = append(descriptions, ScopeDescription{Name: scope.Name, Description: scope.Description})
+	}
+
+	sort.Slice(descriptions, func(i, j int) bool { return descriptions[i].Name < descriptions[j].Name })
+	return descriptions, nil
+}
+
+func (s *OAuthService) consentRequired(ctx context.Context, user *models.User, oauthClient *models.OAuthClient, scopes []ScopeDescription) (bool, error) {
+	if oauthClient.Trusted {
+		return false, nil
+	}
+
+	consent, err := s.consentRepo.Find(ctx, user.ID, oauthClient.ID)
+	if err != nil {
+		return true, nil
+	}
+	granted := strings.Fields(consent.Scopes)
+	for _, scope := range scopes {
+		if !hasScope(granted, scope.Name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// saveConsent adds scopes to those user allowed oauthClient before.
+func (s *OAuthService) saveConsent(ctx context.Context, user *models.User, oauthClient *models.OAuthClient, scopes []string) error {
+	granted := scopes
+	if consent, err := s.consentRepo.Find(ctx, user.ID, oauthClient.ID); err == nil {
+		granted = parseScopes(consent.Scopes + " " + strings.Join(scopes, " "))
+	}
+
+	return s.consentRepo.Save(ctx, &models.OAuthConsent{
+		TenantID:      oauthClient.TenantID,
+		UserID:        user.ID,
+		OAuthClientID: oauthClient.ID,
+		Scopes:        strings.Join(granted, " "),
+	})
+}
+
+func (s *OAuthService) recordGrant(ctx context.Context, user *models.User, action string, oauthClient *models.OAuthClient, details map[string]interface{}, client ClientInfo) {
+	details["client_id"] = oauthClient.ClientID
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    user.ID,
+		Action:     action,
+		Resource:   AuditResourceOAuthClient,
+		ResourceID: oauthClient.ID.String(),
+		Details:    details,
+		Client:     client,
+	})
+}
+
+// verifyCodeChallenge reports whether verifier is the PKCE code verifier of
+// the S256 challenge.
+func verifyCodeChallenge(verifier, challenge string) bool {
+	if !codeVerifierPattern.MatchString(verifier) {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+}
+
+// parseScopes splits a space-separated scope parameter into its distinct
+// scopes, sorted.
+func parseScopes(scope string) []string {
+	seen := make(map[string]bool)
+	scopes := []string{}
+	for _, name := range strings.Fields(scope) {
+		if !seen[name] {
+			seen[name] = true
+			scopes = append(scopes, name)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+func scopeNames(scopes []ScopeDescription) []string {
+	names := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		names = append(names, scope.Name)
+	}
+	return names
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, candidate := range scopes {
+		if candidate == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func sameScopes(a, b []string) bool {
+	a, b = parseScopes(strings.Join(a, " ")), parseScopes(strings.Join(b, " "))
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeStringList decodes a JSON array of strings stored in a jsonb column.
+func decodeStringList(encoded string) ([]string, error) {
+	var list []string
+	if encoded == "" {
+		return list, nil
+	}
+	if err := json.Unmarshal([]byte(encoded), &list); err != nil {
+		return nil, fmt.Errorf("invalid JSON list: %v", err)
+	}
+	return list, nil
+}
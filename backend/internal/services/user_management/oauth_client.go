@@ -0,0 +1,391 @@
+package user_management
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+var (
+	ErrOAuthScopeNotFound      = errors.New("OAuth scope not found")
+	ErrOAuthScopeNameTaken     = errors.New("OAuth scope name is already in use")
+	ErrReservedOAuthScope      = errors.New("OAuth scope name is reserved")
+	ErrInvalidOAuthScopeName   = errors.New("OAuth scope names may only contain letters, digits and the characters _ . : -")
+	ErrInvalidRedirectURIs     = errors.New("redirect URIs must be absolute URIs without a fragment; http is only allowed for loopback addresses")
+	ErrPublicOAuthClientSecret = errors.New("public clients have no secret")
+)
+
+var oauthScopeNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.:-]+$`)
+
+const (
+	oauthClientIDSize     = 16
+	oauthClientSecretSize = 32
+)
+
+// OAuthClientInput holds the editable client fields. Nil fields are left
+// unchanged on update. Public can only be chosen when the client is created.
+type OAuthClientInput struct {
+	Name                   *string
+	RedirectURIs           []string
+	PostLogoutRedirectURIs []string
+	Scopes                 []string
+	Public                 *bool
+	Trusted                *bool
+}
+
+// OAuthScopeInput holds the editable scope fields. Nil fields are left
+// unchanged on update.
+type OAuthScopeInput struct {
+	Name        *string
+	Description *string
+	Permissions []string
+}
+
+// OAuthClientService administers the OAuth clients and scopes of the tenant
+// carried by the context.
+type OAuthClientService struct {
+	oauthClientRepo user_management.OAuthClientRepository
+	oauthScopeRepo  user_management.OAuthScopeRepository
+	consentRepo     user_management.OAuthConsentRepository
+	sessionService  *SessionService
+	auditService    *AuditService
+}
+
+func NewOAuthClientService(
+	oauthClientRepo user_management.OAuthClientRepository,
+	oauthScopeRepo user_management.OAuthScopeRepository,
+	consentRepo user_management.OAuthConsentRepository,
+	sessionService *SessionService,
+	auditService *AuditService,
+) *OAuthClientService {
+	return &OAuthClientService{
+		oauthClientRepo: oauthClientRepo,
+		oauthScopeRepo:  oauthScopeRepo,
+		consentRepo:     consentRepo,
+		sessionService:  sessionService,
+		auditService:    auditService,
+	}
+}
+
+func (s *OAuthClientService) ListClients(ctx context.Context) ([]*models.OAuthClient, error) {
+	return s.oauthClientRepo.FindAll(ctx)
+}
+
+func (s *OAuthClientService) GetClient(ctx context.Context, id string) (*models.OAuthClient, error) {
+	clientID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	oauthClient, err := s.oauthClientRepo.FindByID(ctx, clientID)
+	if err != nil {
+		return nil, ErrOAuthClientNotFound
+	}
+	return oauthClient, nil
+}
+
+// CreateClient registers a client. Confidential clients are issued a
+// secret, which is returned once and cannot be retrieved again.
+func (s *OAuthClientService) CreateClient(ctx context.Context, actor *models.User, input OAuthClientInput, client ClientInfo) (string, *models.OAuthClient, error) {
+	rawID, err := generateRandomBytes(oauthClientIDSize)
+	if err != nil {
+		return "", nil, err
+	}
+
+	oauthClient := &models.OAuthClient{
+		ClientID:               hex.EncodeToString(rawID),
+		RedirectURIs:           "[]",
+		PostLogoutRedirectURIs: "[]",
+		Scopes:                 "[]",
+		Public:                 input.Public != nil && *input.Public,
+	}
+	if err := s.applyClientInput(oauthClient, input); err != nil {
+		return "", nil, err
+	}
+
+	var secret string
+	if !oauthClient.Public {
+		if secret, err = s.newClientSecret(oauthClient); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if err := s.oauthClientRepo.Create(ctx, oauthClient); err != nil {
+		return "", nil, err
+	}
+
+	s.recordClientChange(ctx, actor, AuditActionOAuthClientCreate, oauthClient, client)
+
+	return secret, oauthClient, nil
+}
+
+func (s *OAuthClientService) UpdateClient(ctx context.Context, actor *models.User, id string, input OAuthClientInput, client ClientInfo) (*models.OAuthClient, error) {
+	oauthClient, err := s.GetClient(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyClientInput(oauthClient, input); err != nil {
+		return nil, err
+	}
+	if err := s.oauthClientRepo.Update(ctx, oauthClient); err != nil {
+		return nil, err
+	}
+
+	s.recordClientChange(ctx, actor, AuditActionOAuthClientUpdate, oauthClient, client)
+
+	return oauthClient, nil
+}
+
+// DeleteClient removes a client together with the consents given to it and
+// ends every session it was granted.
+func (s *OAuthClientService) DeleteClient(ctx context.Context, actor *models.User, id string, client ClientInfo) error {
+	oauthClient, err := s.GetClient(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.sessionService.EndClientSessions(ctx, oauthClient.ID); err != nil {
+		return err
+	}
+	if err := s.consentRepo.DeleteByClientID(ctx, oauthClient.ID); err != nil {
+		return err
+	}
+	if err := s.oauthClientRepo.Delete(ctx, oauthClient.ID); err != nil {
+		return err
+	}
+
+	s.recordClientChange(ctx, actor, AuditActionOAuthClientDelete, oauthClient, client)
+
+	return nil
+}
+
+// RotateClientSecret issues a confidential client a new secret. The
+// previous secret stops working immediately.
+func (s *OAuthClientService) RotateClientSecret(ctx context.Context, actor *models.User, id string, client ClientInfo) (string, *models.OAuthClient, error) {
+	oauthClient, err := s.GetClient(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	if oauthClient.Public {
+		return "", nil, ErrPublicOAuthClientSecret
+	}
+
+	secret, err := s.newClientSecret(oauthClient)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := s.oauthClientRepo.Update(ctx, oauthClient); err != nil {
+		return "", nil, err
+	}
+
+	s.recordClientChange(ctx, actor, AuditActionOAuthClientRotate, oauthClient, client)
+
+	return secret, oauthClient, nil
+}
+
+func (s *OAuthClientService) ListScopes(ctx context.Context) ([]*models.OAuthScope, error) {
+	return s.oauthScopeRepo.FindAll(ctx)
+}
+
+func (s *OAuthClientService) CreateScope(ctx context.Context, actor *models.User, input OAuthScopeInput, client ClientInfo) (*models.OAuthScope, error) {
+	scope := &models.OAuthScope{Permissions: "[]"}
+	if err := s.applyScopeInput(ctx, scope, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.oauthScopeRepo.Create(ctx, scope); err != nil {
+		return nil, err
+	}
+
+	s.recordScopeChange(ctx, actor, AuditActionOAuthScopeCreate, scope, client)
+
+	return scope, nil
+}
+
+func (s *OAuthClientService) UpdateScope(ctx context.Context, actor *models.User, id string, input OAuthScopeInput, client ClientInfo) (*models.OAuthScope, error) {
+	scope, err := s.getScope(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyScopeInput(ctx, scope, input); err != nil {
+		return nil, err
+	}
+	if err := s.oauthScopeRepo.Update(ctx, scope); err != nil {
+		return nil, err
+	}
+
+	s.recordScopeChange(ctx, actor, AuditActionOAuthScopeUpdate, scope, client)
+
+	return scope, nil
+}
+
+func (s *OAuthClientService) DeleteScope(ctx context.Context, actor *models.User, id string, client ClientInfo) error {
+	scope, err := s.getScope(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.oauthScopeRepo.Delete(ctx, scope.ID); err != nil {
+		return err
+	}
+
+	s.recordScopeChange(ctx, actor, AuditActionOAuthScopeDelete, scope, client)
+
+	return nil
+}
+
+func (s *OAuthClientService) getScope(ctx context.Context, id string) (*models.OAuthScope, error) {
+	scopeID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := s.oauthScopeRepo.FindByID(ctx, scopeID)
+	if err != nil {
+		return nil, ErrOAuthScopeNotFound
+	}
+	return scope, nil
+}
+
+func (s *OAuthClientService) applyClientInput(oauthClient *models.OAuthClient, input OAuthClientInput) error {
+	if input.Name != nil {
+		oauthClient.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.RedirectURIs != nil {
+		encoded, err := encodeRedirectURIs(input.RedirectURIs)
+		if err != nil {
+			return err
+		}
+		oauthClient.RedirectURIs = encoded
+	}
+	if input.PostLogoutRedirectURIs != nil {
+		encoded, err := encodeRedirectURIs(input.PostLogoutRedirectURIs)
+		if err != nil {
+			return err
+		}
+		oauthClient.PostLogoutRedirectURIs = encoded
+	}
+	if input.Scopes != nil {
+		encoded, err := json.Marshal(parseScopes(strings.Join(input.Scopes, " ")))
+		if err != nil {
+			return err
+		}
+		oauthClient.Scopes = string(encoded)
+	}
+	if input.Trusted != nil {
+		oauthClient.Trusted = *input.Trusted
+	}
+	return nil
+}
+
+func (s *OAuthClientService) applyScopeInput(ctx context.Context, scope *models.OAuthScope, input OAuthScopeInput) error {
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if _, ok := reservedScopes[name]; ok {
+			return ErrReservedOAuthScope
+		}
+		if !oauthScopeNamePattern.MatchString(name) {
+			return ErrInvalidOAuthScopeName
+		}
+		if existing, err := s.oauthScopeRepo.FindByName(ctx, name); err == nil && existing.ID != scope.ID {
+			return ErrOAuthScopeNameTaken
+		}
+		scope.Name = name
+	}
+	if input.Description != nil {
+		scope.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.Permissions != nil {
+		permissions := normalizePermissions(input.Permissions)
+		for _, permission := range permissions {
+			if permission != wildcardPermission && !permissionNamePattern.MatchString(permission) {
+				return ErrInvalidPermission
+			}
+		}
+		encoded, err := json.Marshal(permissions)
+		if err != nil {
+			return err
+		}
+		scope.Permissions = string(encoded)
+	}
+	return nil
+}
+
+// newClientSecret sets a new secret on oauthClient and returns it.
+func (s *OAuthClientService) newClientSecret(oauthClient *models.OAuthClient) (string, error) {
+	raw, err := generateRandomBytes(oauthClientSecretSize)
+	if err != nil {
+		return "", err
+	}
+
+	secret := base64.RawURLEncoding.EncodeToString(raw)
+	oauthClient.SecretHash = hashToken(secret)
+	return secret, nil
+}
+
+func (s *OAuthClientService) recordClientChange(ctx context.Context, actor *models.User, action string, oauthClient *models.OAuthClient, client ClientInfo) {
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    actor.ID,
+		Action:     action,
+		Resource:   AuditResourceOAuthClient,
+		ResourceID: oauthClient.ID.String(),
+		Details: map[string]interface{}{
+			"client_id": oauthClient.ClientID,
+			"name":      oauthClient.Name,
+		},
+		Client: client,
+	})
+}
+
+func (s *OAuthClientService) recordScopeChange(ctx context.Context, actor *models.User, action string, scope *models.OAuthScope, client ClientInfo) {
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    actor.ID,
+		Action:     action,
+		Resource:   AuditResourceOAuthScope,
+		ResourceID: scope.ID.String(),
+		Details:    map[string]interface{}{"name": scope.Name},
+		Client:     client,
+	})
+}
+
+// encodeRedirectURIs validates uris and encodes them for a jsonb column.
+func encodeRedirectURIs(uris []string) (string, error) {
+	for _, uri := range uris {
+		if !validRedirectURI(uri) {
+			return "", ErrInvalidRedirectURIs
+		}
+	}
+	encoded, err := json.Marshal(uris)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// validRedirectURI reports whether uri may be registered as a redirect URI:
+// an absolute URI without a fragment that, if it uses http, points to a
+// loopback address. Native apps may use private-use schemes.
+func validRedirectURI(uri string) bool {
+	parsed, err := url.Parse(uri)
+	if err != nil || !parsed.IsAbs() || parsed.Fragment != "" {
+		return false
+	}
+	if parsed.Scheme != "http" {
+		return true
+	}
+	switch parsed.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
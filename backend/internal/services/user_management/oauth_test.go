@@ -0,0 +1,176 @@
+package user_management
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"testing"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+const testRedirectURI = "https://client.acme.test/callback"
+
+type oauthTest struct {
+	*serviceTest
+	oauth  *OAuthService
+	client *models.OAuthClient
+	user   *models.User
+}
+
+// newOAuthTest registers a trusted public client that may request
+// offline_access, so codes are issued without asking for consent.
+func newOAuthTest(t *testing.T) *oauthTest {
+	t.Helper()
+
+	st := newServiceTest(t)
+	clientRepo := user_management.NewOAuthClientRepository(st.db)
+	scopeRepo := user_management.NewOAuthScopeRepository(st.db)
+	consentRepo := user_management.NewOAuthConsentRepository(st.db)
+	clients := NewOAuthClientService(clientRepo, scopeRepo, consentRepo, st.sessionService, st.auditService)
+
+	public, trusted := true, true
+	_, client, err := clients.CreateClient(st.ctx, &models.User{}, OAuthClientInput{
+		Name:         stringPtr("Dashboard"),
+		RedirectURIs: []string{testRedirectURI},
+		Scopes:       []string{ScopeOfflineAccess},
+		Public:       &public,
+		Trusted:      &trusted,
+	}, ClientInfo{})
+	if err != nil {
+		t.Fatalf("failed to create OAuth client: %v", err)
+	}
+
+	return &oauthTest{
+		serviceTest: st,
+		oauth: NewOAuthService(clientRepo, scopeRepo, consentRepo, user_management.NewOAuthAuthorizationCodeRepository(st.db), st.userRepo,
+			st.authService, st.sessionService, nil, st.tokenHasher, st.auditService),
+		client: client,
+		user:   st.createUser(t, "nia@acme.test", "Secret-pass-1"),
+	}
+}
+
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (ot *oauthTest) authorizationRequest(verifier string) AuthorizationRequest {
+	return AuthorizationRequest{
+		ResponseType:        ResponseTypeCode,
+		ClientID:            ot.client.ClientID,
+		RedirectURI:         testRedirectURI,
+		Scope:               ScopeOfflineAccess,
+		State:               "xyz",
+		CodeChallenge:       codeChallenge(verifier),
+		CodeChallengeMethod: CodeChallengeMethodS256,
+	}
+}
+
+// authorize approves a request with a challenge for verifier and returns
+// the authorization code.
+func (ot *oauthTest) authorize(t *testing.T, verifier string) string {
+	t.Helper()
+
+	redirect, err := ot.oauth.Authorize(ot.ctx, ot.user, &AccessClaims{AuthMethod: AuthMethodPassword}, ot.authorizationRequest(verifier), true, ClientInfo{})
+	if err != nil {
+		t.Fatalf("authorization failed: %v", err)
+	}
+	parsed, err := url.Parse(redirect)
+	if err != nil || parsed.Query().Get("code") == "" || parsed.Query().Get("state") != "xyz" {
+		t.Fatalf("redirect %q carries no code and state", redirect)
+	}
+	return parsed.Query().Get("code")
+}
+
+func (ot *oauthTest) exchange(code, verifier string) (*ClientTokens, error) {
+	return ot.oauth.Exchange(ot.ctx, TokenRequest{
+		GrantType:    GrantTypeAuthorizationCode,
+		ClientID:     ot.client.ClientID,
+		Code:         code,
+		RedirectURI:  testRedirectURI,
+		CodeVerifier: verifier,
+	}, ClientInfo{})
+}
+
+func oauthErrorCode(err error) string {
+	if oauthErr, ok := err.(*OAuthError); ok {
+		return oauthErr.Code
+	}
+	return ""
+}
+
+func TestOAuthAuthorizationRequiresS256Challenge(t *testing.T) {
+	ot := newOAuthTest(t)
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	for name, modify := range map[string]func(*AuthorizationRequest){
+		"missing challenge": func(req *AuthorizationRequest) { req.CodeChallenge = "" },
+		"plain method":      func(req *AuthorizationRequest) { req.CodeChallengeMethod = "plain"; req.CodeChallenge = verifier },
+		"short challenge":   func(req *AuthorizationRequest) { req.CodeChallenge = "abc" },
+	} {
+		req := ot.authorizationRequest(verifier)
+		modify(&req)
+		if _, err := ot.oauth.PrepareAuthorization(ot.ctx, ot.user, req); oauthErrorCode(err) != OAuthErrorInvalidRequest {
+			t.Errorf("%s: got %v, want invalid_request", name, err)
+		}
+	}
+
+	req := ot.authorizationRequest(verifier)
+	req.RedirectURI = "https://attacker.test/callback"
+	if _, err := ot.oauth.PrepareAuthorization(ot.ctx, ot.user, req); err != ErrInvalidRedirectURI {
+		t.Fatalf("unregistered redirect URI returned %v, want ErrInvalidRedirectURI", err)
+	}
+}
+
+func TestOAuthCodeExchangeRequiresMatchingVerifier(t *testing.T) {
+	ot := newOAuthTest(t)
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	code := ot.authorize(t, verifier)
+	if _, err := ot.exchange(code, "wrong-verifier-wrong-verifier-wrong-verifier"); oauthErrorCode(err) != OAuthErrorInvalidGrant {
+		t.Fatalf("exchange with a wrong verifier returned %v, want invalid_grant", err)
+	}
+	if _, err := ot.exchange(code, verifier); oauthErrorCode(err) != OAuthErrorInvalidGrant {
+		t.Fatalf("code was still redeemable after a failed exchange: %v", err)
+	}
+
+	code = ot.authorize(t, verifier)
+	tokens, err := ot.exchange(code, verifier)
+	if err != nil {
+		t.Fatalf("exchange failed: %v", err)
+	}
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		t.Fatalf("exchange issued %+v, want an access and refresh token", tokens)
+	}
+	claims, err := ot.authService.ValidateToken(ot.ctx, tokens.AccessToken)
+	if err != nil || claims.ClientID != ot.client.ClientID || claims.UserID != ot.user.ID {
+		t.Fatalf("access token claims = %+v (err %v), want the client and user of the grant", claims, err)
+	}
+}
+
+func TestOAuthCodeReuseEndsSession(t *testing.T) {
+	ot := newOAuthTest(t)
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	code := ot.authorize(t, verifier)
+	tokens, err := ot.exchange(code, verifier)
+	if err != nil {
+		t.Fatalf("exchange failed: %v", err)
+	}
+
+	if _, err := ot.exchange(code, verifier); oauthErrorCode(err) != OAuthErrorInvalidGrant {
+		t.Fatalf("reused code returned %v, want invalid_grant", err)
+	}
+	if _, err := ot.authService.ValidateToken(ot.ctx, tokens.AccessToken); err != ErrInvalidAccessToken {
+		t.Fatalf("access token of the reused code returned %v, want ErrInvalidAccessToken", err)
+	}
+	if _, err := ot.oauth.Exchange(ot.ctx, TokenRequest{
+		GrantType:    GrantTypeRefreshToken,
+		ClientID:     ot.client.ClientID,
+		RefreshToken: tokens.RefreshToken,
+	}, ClientInfo{}); oauthErrorCode(err) != OAuthErrorInvalidGrant {
+		t.Fatalf("refresh token of the reused code returned %v, want invalid_grant", err)
+	}
+}
@@ -0,0 +1,314 @@
+package user_management
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+// Scopes of OpenID Connect. openid asks for an ID token; the others select
+// the user claims it and the userinfo endpoint carry. None of them grants
+// permissions.
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+	ScopePhone   = "phone"
+)
+
+var (
+	ErrInvalidIDTokenHint           = errors.New("id_token_hint is invalid or was not issued to the client")
+	ErrInvalidPostLogoutRedirectURI = errors.New("post_logout_redirect_uri is not registered for the client")
+	ErrOpenIDScopeRequired          = errors.New("the access token was not granted the openid scope")
+)
+
+// ProviderMetadata is the OpenID Provider metadata published for discovery.
+type ProviderMetadata struct {
+	Issuer                string
+	AuthorizationEndpoint string
+	TokenEndpoint         string
+	UserinfoEndpoint      string
+	JWKSURI               string
+	EndSessionEndpoint    string
+	ScopesSupported       []string
+	ClaimsSupported       []string
+}
+
+// EndSessionRequest holds the parameters of an RP-initiated logout request.
+type EndSessionRequest struct {
+	IDTokenHint           string
+	ClientID              string
+	PostLogoutRedirectURI string
+	State                 string
+}
+
+// idTokenHint holds the ID token claims a logout request is checked against.
+type idTokenHint struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	Audience  string `json:"aud"`
+	SessionID string `json:"sid"`
+}
+
+// OIDCService makes the OAuth authorization server an OpenID Connect
+// provider. Each tenant is a provider of its own, whose issuer is the
+// configured issuer URL for the default tenant and the tenant's domain for
+// the others. ID tokens are EdDSA JWTs signed with the token signing keys.
+type OIDCService struct {
+	tenantRepo      user_management.TenantRepository
+	userRepo        user_management.UserRepository
+	oauthClientRepo user_management.OAuthClientRepository
+	sessionService  *SessionService
+	tokenSigner     *TokenSigner
+	auditService    *AuditService
+	issuerURL       string
+	frontendURL     string
+	defaultDomain   string
+}
+
+func NewOIDCService(
+	tenantRepo user_management.TenantRepository,
+	userRepo user_management.UserRepository,
+	oauthClientRepo user_management.OAuthClientRepository,
+	sessionService *SessionService,
+	tokenSigner *TokenSigner,
+	auditService *AuditService,
+	issuerURL string,
+	frontendURL string,
+	defaultDomain string,
+) *OIDCService {
+	return &OIDCService{
+		tenantRepo:      tenantRepo,
+		userRepo:        userRepo,
+		oauthClientRepo: oauthClientRepo,
+		sessionService:  sessionService,
+		tokenSigner:     tokenSigner,
+		auditService:    auditService,
+		issuerURL:       strings.TrimSuffix(issuerURL, "/"),
+		frontendURL:     strings.TrimSuffix(frontendURL, "/"),
+		defaultDomain:   normalizeDomain(defaultDomain),
+	}
+}
+
+// Issuer returns the issuer identifier of tenant. Tenants other than the
+// default one are served on their own domain, keeping the scheme and port
+// of the configured issuer URL.
+func (s *OIDCService) Issuer(tenant *models.Tenant) string {
+	issuer, err := url.Parse(s.issuerURL)
+	if err != nil || tenant.Domain == "" || tenant.Domain == s.defaultDomain {
+		return s.issuerURL
+	}
+
+	host := tenant.Domain
+	if port := issuer.Port(); port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+	issuer.Host = host
+	return issuer.String()
+}
+
+// Metadata describes the provider of tenant. Users are sent to the
+// frontend to authorize clients, which calls the API on their behalf.
+func (s *OIDCService) Metadata(tenant *models.Tenant) *ProviderMetadata {
+	issuer := s.Issuer(tenant)
+
+	scopes := make([]string, 0, len(reservedScopes))
+	for name := range reservedScopes {
+		scopes = append(scopes, name)
+	}
+
+	return &ProviderMetadata{
+		Issuer:                issuer,
+		AuthorizationEndpoint: s.frontendURL + "/oauth/authorize",
+		TokenEndpoint:         issuer + "/api/v1/oauth/token",
+		UserinfoEndpoint:      issuer + "/api/v1/oidc/userinfo",
+		JWKSURI:               issuer + "/api/v1/oidc/jwks",
+		EndSessionEndpoint:    issuer + "/api/v1/oidc/logout",
+		ScopesSupported:       parseScopes(strings.Join(scopes, " ")),
+		ClaimsSupported: []string{
+			"iss", "sub", "aud", "azp", "exp", "iat", "nonce", "amr", "sid",
+			"name", "given_name", "family_name", "preferred_username", "picture", "updated_at",
+			"email", "email_verified", "phone_number",
+		},
+	}
+}
+
+// KeySet returns the keys that verify ID tokens as JSON Web Keys.
+func (s *OIDCService) KeySet() []JWK {
+	keys := s.tokenSigner.PublicKeys()
+	set := make([]JWK, 0, len(keys))
+	for _, key := range keys {
+		set = append(set, key.JWK())
+	}
+	return set
+}
+
+// IDToken issues the ID token of session, in which user signed in to
+// oauthClient. It expires with the access token issued alongside it after
+// ttl and carries the claims of the granted scopes. nonce is echoed if set.
+func (s *OIDCService) IDToken(ctx context.Context, user *models.User, oauthClient *models.OAuthClient, session *models.Session, nonce string, ttl time.Duration) (string, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, user.TenantID)
+	if err != nil {
+		return "", ErrTenantNotFound
+	}
+
+	now := time.Now()
+	claims := userClaims(user, strings.Fields(session.Scopes))
+	claims["iss"] = s.Issuer(tenant)
+	claims["aud"] = oauthClient.ClientID
+	claims["azp"] = oauthClient.ClientID
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(ttl).Unix()
+	claims["sid"] = session.ID.String()
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	if amr := authMethodReferences(session.AuthMethod); amr != nil {
+		claims["amr"] = amr
+	}
+
+	return s.tokenSigner.SignJWT(claims)
+}
+
+// UserInfo returns the claims about user that the OAuth access token with
+// claims was granted. The token must have been granted the openid scope.
+func (s *OIDCService) UserInfo(user *models.User, claims *AccessClaims) (map[string]interface{}, error) {
+	scopes := strings.Fields(claims.Scope)
+	if claims.ClientID == "" || !hasScope(scopes, ScopeOpenID) {
+		return nil, ErrOpenIDScopeRequired
+	}
+	return userClaims(user, scopes), nil
+}
+
+// EndSession serves an RP-initiated logout request to the provider of
+// tenant and returns the URI to send the user agent on to, if the client
+// asked for one. The session an ID token hint names is ended together with
+// the session the user approved the client from. The client is taken from
+// the hint, or from client_id when there is none.
+func (s *OIDCService) EndSession(ctx context.Context, tenant *models.Tenant, req EndSessionRequest, client ClientInfo) (string, error) {
+	var hint idTokenHint
+	clientID := req.ClientID
+	if req.IDTokenHint != "" {
+		if err := s.tokenSigner.VerifyJWT(req.IDTokenHint, &hint); err != nil || hint.Issuer != s.Issuer(tenant) {
+			return "", ErrInvalidIDTokenHint
+		}
+		if clientID != "" && clientID != hint.Audience {
+			return "", ErrInvalidIDTokenHint
+		}
+		clientID = hint.Audience
+	}
+
+	var oauthClient *models.OAuthClient
+	if clientID != "" {
+		var err error
+		if oauthClient, err = s.oauthClientRepo.FindByClientID(ctx, clientID); err != nil {
+			return "", ErrOAuthClientNotFound
+		}
+	}
+
+	var redirectTo string
+	if req.PostLogoutRedirectURI != "" {
+		if oauthClient == nil {
+			return "", ErrOAuthClientNotFound
+		}
+		registered, err := decodeStringList(oauthClient.PostLogoutRedirectURIs)
+		if err != nil {
+			return "", err
+		}
+		if !hasScope(registered, req.PostLogoutRedirectURI) {
+			return "", ErrInvalidPostLogoutRedirectURI
+		}
+		redirectTo = AuthorizationRequest{RedirectURI: req.PostLogoutRedirectURI, State: req.State}.redirect(url.Values{})
+	}
+
+	if hint.SessionID != "" && oauthClient != nil {
+		if err := s.endSession(ctx, hint, oauthClient, client); err != nil {
+			return "", err
+		}
+	}
+
+	return redirectTo, nil
+}
+
+// endSession ends the session hint names. Sessions that already ended are
+// not an error: the user is signed out either way.
+func (s *OIDCService) endSession(ctx context.Context, hint idTokenHint, oauthClient *models.OAuthClient, client ClientInfo) error {
+	userID, err := uuid.Parse(hint.Subject)
+	if err != nil {
+		return ErrInvalidIDTokenHint
+	}
+	sessionID, err := uuid.Parse(hint.SessionID)
+	if err != nil {
+		return ErrInvalidIDTokenHint
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil
+	}
+
+	if err := s.sessionService.EndClientSession(ctx, user, oauthClient, sessionID); err != nil {
+		if err == ErrSessionNotFound {
+			return nil
+		}
+		return err
+	}
+
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    user.ID,
+		Action:     AuditActionOIDCLogout,
+		Resource:   AuditResourceSession,
+		ResourceID: sessionID.String(),
+		Details:    map[string]interface{}{"client_id": oauthClient.ClientID},
+		Client:     client,
+	})
+
+	return nil
+}
+
+// userClaims returns the standard claims about user that scopes grant,
+// leaving out those without a value.
+func userClaims(user *models.User, scopes []string) map[string]interface{} {
+	claims := map[string]interface{}{"sub": user.ID.String()}
+	set := func(name, value string) {
+		if value != "" {
+			claims[name] = value
+		}
+	}
+
+	if hasScope(scopes, ScopeProfile) {
+		set("name", strings.TrimSpace(user.FirstName+" "+user.LastName))
+		set("given_name", user.FirstName)
+		set("family_name", user.LastName)
+		set("preferred_username", user.Username)
+		set("picture", user.ProfilePicture)
+		claims["updated_at"] = user.UpdatedAt.Unix()
+	}
+	if hasScope(scopes, ScopeEmail) {
+		set("email", user.Email)
+		claims["email_verified"] = user.EmailVerified
+	}
+	if hasScope(scopes, ScopePhone) {
+		set("phone_number", user.PhoneNumber)
+	}
+	return claims
+}
+
+// authMethodReferences returns the RFC 8176 amr values of an authentication
+// method. Sign-ins from a trusted device skipped the second factor.
+func authMethodReferences(method string) []string {
+	switch method {
+	case AuthMethodPassword, AuthMethodTrustedDevice:
+		return []string{"pwd"}
+	case AuthMethodMFA:
+		return []string{"pwd", "mfa"}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package user_management
+
+import (
+	"sort"
+	"strings"
+)
+
+// wildcardPermission matches every permission.
+const wildcardPermission = "*"
+
+// PermissionSet is the set of permissions a caller holds. Permissions are
+// named resource:action, and either part of a held permission may be the
+// wildcard "*", so users:* covers users:read and *:read covers audit:read.
+// A set is computed once per request and answers every permission check of
+// that request.
+type PermissionSet struct {
+	names    map[string]bool
+	patterns []string
+}
+
+// NewPermissionSet returns a set holding exactly the given permissions, such
+// as the scopes of an API key.
+func NewPermissionSet(names []string) PermissionSet {
+	set := PermissionSet{names: make(map[string]bool, len(names))}
+	for _, name := range names {
+		set.add(name)
+	}
+	return set
+}
+
+func (p *PermissionSet) add(name string) {
+	if p.names[name] {
+		return
+	}
+	p.names[name] = true
+	if strings.Contains(name, wildcardPermission) {
+		p.patterns = append(p.patterns, name)
+	}
+}
+
+// Has reports whether the set covers permission, either by holding it or
+// through a wildcard.
+func (p PermissionSet) Has(permission string) bool {
+	if p.names[permission] {
+		return true
+	}
+	for _, pattern := range p.patterns {
+		if matchPermission(pattern, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p PermissionSet) HasAny(permissions ...string) bool {
+	for _, permission := range permissions {
+		if p.Has(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p PermissionSet) HasAll(permissions ...string) bool {
+	for _, permission := range permissions {
+		if !p.Has(permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersect returns the permissions covered by both sets, such as what a
+// user may do through an OAuth client limited to some scopes. A permission
+// is kept if it is held by one set and covered by the other, so the result
+// never covers more than either set, though it may miss combinations of two
+// wildcards such as users:* and *:read.
+func (p PermissionSet) Intersect(other PermissionSet) PermissionSet {
+	set := PermissionSet{names: make(map[string]bool)}
+	for name := range p.names {
+		if other.Has(name) {
+			set.add(name)
+		}
+	}
+	for name := range other.names {
+		if p.Has(name) {
+			set.add(name)
+		}
+	}
+	return set
+}
+
+// Digest returns the smallest sorted list of permissions that covers the
+// same set, dropping names a held wildcard already covers. It is what access
+// tokens carry, so Admins get ["*"] rather than every permission.
+func (p PermissionSet) Digest() []string {
+	digest := make([]string, 0, len(p.names))
+	for name := range p.names {
+		covered := false
+		for _, pattern := range p.patterns {
+			if pattern != name && matchPermission(pattern, name) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			digest = append(digest, name)
+		}
+	}
+	sort.Strings(digest)
+	return digest
+}
+
+// matchPermission reports whether the held permission pattern covers the
+// requested permission.
+func matchPermission(pattern, permission string) bool {
+	if pattern == wildcardPermission || pattern == permission {
+		return true
+	}
+
+	patternResource, patternAction, ok := strings.Cut(pattern, ":")
+	if !ok {
+		return false
+	}
+	resource, action, ok := strings.Cut(permission, ":")
+	if !ok {
+		return false
+	}
+
+	return (patternResource == wildcardPermission || patternResource == resource) &&
+		(patternAction == wildcardPermission || patternAction == action)
+}
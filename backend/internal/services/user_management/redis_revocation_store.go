@@ -0,0 +1,56 @@
+package user_management
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisRevocationKeyPrefix = "adminsuite:revoked:"
+
+// RedisRevocationStore is a RevocationStore shared by every instance that
+// points at the same Redis-compatible server. Entries carry the server-side
+// expiry of the tokens they cover, so the server drops them by itself.
+type RedisRevocationStore struct {
+	client redis.Cmdable
+}
+
+func NewRedisRevocationStore(client redis.Cmdable) *RedisRevocationStore {
+	return &RedisRevocationStore{client: client}
+}
+
+func (s *RedisRevocationStore) Put(ctx context.Context, key string, revokedAt, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, redisRevocationKeyPrefix+key, revokedAt.UnixNano(), ttl).Err()
+}
+
+func (s *RedisRevocationStore) Get(ctx context.Context, keys []string) ([]time.Time, error) {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = redisRevocationKeyPrefix + key
+	}
+
+	values, err := s.client.MGet(ctx, prefixed...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	revokedAt := make([]time.Time, len(keys))
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+		nanos, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		revokedAt[i] = time.Unix(0, nanos)
+	}
+	return revokedAt, nil
+}
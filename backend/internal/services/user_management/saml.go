@@ -0,0 +1,481 @@
+package user_management
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+// Namespaces and identifiers of SAML 2.0.
+const (
+	samlAssertionNamespace  = "urn:oasis:names:tc:SAML:2.0:assertion"
+	samlProtocolNamespace   = "urn:oasis:names:tc:SAML:2.0:protocol"
+	samlMetadataNamespace   = "urn:oasis:names:tc:SAML:2.0:metadata"
+	samlBindingHTTPRedirect = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	samlBindingHTTPPost     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	samlStatusSuccess       = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	samlConfirmationBearer  = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	samlNameIDFormatAny     = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+)
+
+const (
+	samlRequestTTL = 10 * time.Minute
+	// samlClockSkew is the difference between our clock and the identity
+	// provider's tolerated when checking assertion validity periods.
+	samlClockSkew = 3 * time.Minute
+	// samlRequestIDSize is the random bytes in the ID of an authentication
+	// request.
+	samlRequestIDSize = 20
+)
+
+var (
+	ErrInvalidSAMLResponse  = errors.New("SAML response is invalid, expired or was not requested")
+	ErrInvalidSAMLSignature = errors.New("SAML assertion is not signed by the identity provider")
+	ErrSAMLAuthnFailed      = errors.New("identity provider did not authenticate the user")
+)
+
+// SAMLServiceProvider describes the tenant as service provider of a SAML
+// provider: the entity ID it is known by at the identity provider, where
+// the identity provider posts its responses, and where the service
+// provider metadata is served.
+type SAMLServiceProvider struct {
+	EntityID    string
+	ACSURL      string
+	MetadataURL string
+}
+
+// SAMLService signs users in through the SAML identity providers of their
+// tenant with service provider initiated single sign-on: authentication
+// requests are sent with the HTTP-Redirect binding and responses received
+// with the HTTP-POST binding. Assertions must be signed, either directly
+// or through the response; signed users are then resolved, provisioned and
+// given their mapped roles like those of OpenID Connect providers.
+type SAMLService struct {
+	providerRepo      user_management.SAMLProviderRepository
+	requestRepo       user_management.SAMLRequestRepository
+	tenantRepo        user_management.TenantRepository
+	federationService *FederationService
+	oidcService       *OIDCService
+}
+
+func NewSAMLService(
+	providerRepo user_management.SAMLProviderRepository,
+	requestRepo user_management.SAMLRequestRepository,
+	tenantRepo user_management.TenantRepository,
+	federationService *FederationService,
+	oidcService *OIDCService,
+) *SAMLService {
+	return &SAMLService{
+		providerRepo:      providerRepo,
+		requestRepo:       requestRepo,
+		tenantRepo:        tenantRepo,
+		federationService: federationService,
+		oidcService:       oidcService,
+	}
+}
+
+// ServiceProvider returns the service provider details of provider. They
+// live under the tenant's issuer, so each tenant is a service provider of
+// its own; the entity ID defaults to the metadata URL.
+func (s *SAMLService) ServiceProvider(ctx context.Context, provider *models.SAMLProvider) (*SAMLServiceProvider, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, provider.TenantID)
+	if err != nil {
+		return nil, ErrTenantNotFound
+	}
+
+	base := strings.TrimSuffix(s.oidcService.Issuer(tenant), "/") + "/api/v1/auth/saml/" + provider.ID.String()
+	sp := &SAMLServiceProvider{
+		EntityID:    provider.SPEntityID,
+		ACSURL:      base + "/acs",
+		MetadataURL: base + "/metadata",
+	}
+	if sp.EntityID == "" {
+		sp.EntityID = sp.MetadataURL
+	}
+	return sp, nil
+}
+
+// Metadata returns the service provider metadata to register with the
+// identity provider with ID providerID.
+func (s *SAMLService) Metadata(ctx context.Context, providerID string) ([]byte, error) {
+	provider, err := s.findEnabledProvider(ctx, providerID)
+	if err != nil {
+		return nil, err
+	}
+	sp, err := s.ServiceProvider(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := xml.MarshalIndent(spEntityDescriptor{
+		Namespace: samlMetadataNamespace,
+		EntityID:  sp.EntityID,
+		SPSSODescriptor: spSSODescriptor{
+			AuthnRequestsSigned:        false,
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: samlProtocolNamespace,
+			NameIDFormat:               samlNameIDFormatAny,
+			AssertionConsumerService: indexedEndpoint{
+				Binding:   samlBindingHTTPPost,
+				Location:  sp.ACSURL,
+				Index:     0,
+				IsDefault: true,
+			},
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), metadata...), nil
+}
+
+// StartLogin begins a sign-in through the provider with ID providerID and
+// returns the identity provider URL to send the user agent to.
+func (s *SAMLService) StartLogin(ctx context.Context, providerID string) (string, error) {
+	provider, err := s.findEnabledProvider(ctx, providerID)
+	if err != nil {
+		return "", err
+	}
+	sp, err := s.ServiceProvider(ctx, provider)
+	if err != nil {
+		return "", err
+	}
+	ssoURL, err := url.Parse(provider.SSOURL)
+	if err != nil {
+		return "", ErrInvalidSSOURL
+	}
+
+	id, err := generateRandomBytes(samlRequestIDSize)
+	if err != nil {
+		return "", err
+	}
+	request := &models.SAMLRequest{
+		SAMLProviderID: provider.ID,
+		// IDs must not start with a digit.
+		RequestID: "_" + hex.EncodeToString(id),
+		ExpiresAt: time.Now().Add(samlRequestTTL),
+	}
+	if err := s.requestRepo.Create(ctx, request); err != nil {
+		return "", err
+	}
+	// Sign-ins that were started but never completed are cleared out here,
+	// as no other path would remove them.
+	if err := s.requestRepo.DeleteExpired(ctx); err != nil {
+		log.Printf("Failed to delete expired SAML requests: %v", err)
+	}
+
+	encoded, err := xml.Marshal(authnRequest{
+		ProtocolNamespace:           samlProtocolNamespace,
+		AssertionNamespace:          samlAssertionNamespace,
+		ID:                          request.RequestID,
+		Version:                     "2.0",
+		IssueInstant:                time.Now().UTC().Format(time.RFC3339),
+		Destination:                 provider.SSOURL,
+		ProtocolBinding:             samlBindingHTTPPost,
+		AssertionConsumerServiceURL: sp.ACSURL,
+		Issuer:                      sp.EntityID,
+		NameIDPolicy:                nameIDPolicy{AllowCreate: true},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// The HTTP-Redirect binding carries the request DEFLATE compressed.
+	var deflated bytes.Buffer
+	writer, err := flate.NewWriter(&deflated, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := writer.Write(encoded); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	query := ssoURL.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(deflated.Bytes()))
+	ssoURL.RawQuery = query.Encode()
+
+	return ssoURL.String(), nil
+}
+
+// CompleteLogin signs in the user the base64 encoded SAML response of the
+// provider with ID providerID asserts. The response must answer a request
+// of StartLogin, which it consumes. Like password logins, it returns
+// ErrMFARequired with the user for MFA-enabled accounts; otherwise it
+// returns the user with a new token pair.
+//
+// Email addresses asserted by the provider are taken as verified: the
+// tenant's administrators chose to trust the provider by configuring it.
+func (s *SAMLService) CompleteLogin(ctx context.Context, providerID, samlResponse string, client ClientInfo) (*models.User, string, string, error) {
+	provider, err := s.findEnabledProvider(ctx, providerID)
+	if err != nil {
+		return nil, "", "", err
+	}
+	sp, err := s.ServiceProvider(ctx, provider)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	assertion, err := s.verifyResponse(ctx, provider, sp, samlResponse)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	attributeMappings, err := decodeAttributeMappings(provider.AttributeMappings)
+	if err != nil {
+		return nil, "", "", err
+	}
+	roleMappings, err := decodeRoleMappings(provider.RoleMappings)
+	if err != nil {
+		return nil, "", "", err
+	}
+	attributeMappings = attributeMappings.withDefaults()
+
+	nameID := assertion.child(samlAssertionNamespace, "Subject").child(samlAssertionNamespace, "NameID").text()
+	attributes := assertionAttributes(assertion)
+	first := func(name string) string {
+		if values := attributes[name]; len(values) > 0 {
+			return strings.TrimSpace(values[0])
+		}
+		return ""
+	}
+
+	account := federatedAccount{
+		ProviderID:    provider.ID,
+		ProviderName:  provider.Name,
+		Subject:       nameID,
+		Email:         first(attributeMappings.Email),
+		EmailVerified: provider.TrustEmail,
+		Username:      first(attributeMappings.Username),
+		FirstName:     first(attributeMappings.FirstName),
+		LastName:      first(attributeMappings.LastName),
+	}
+	if account.Email == "" && strings.Contains(nameID, "@") {
+		account.Email = nameID
+	}
+	if provider.GroupsAttribute != "" {
+		account.Groups = attributes[provider.GroupsAttribute]
+		account.RoleMappings = roleMappings
+	}
+
+	return s.federationService.signIn(ctx, account, client)
+}
+
+// verifyResponse checks the SAML response and returns its assertion. The
+// assertion must be signed by the provider, be issued by it for sp, be
+// within its validity period, and answer an outstanding request.
+func (s *SAMLService) verifyResponse(ctx context.Context, provider *models.SAMLProvider, sp *SAMLServiceProvider, samlResponse string) (*xmlNode, error) {
+	data, err := decodeXMLBase64(samlResponse)
+	if err != nil {
+		return nil, ErrInvalidSAMLResponse
+	}
+	response, err := parseXML(data)
+	if err != nil || !response.is(samlProtocolNamespace, "Response") || response.attr("Version") != "2.0" {
+		return nil, ErrInvalidSAMLResponse
+	}
+	if destination := response.attr("Destination"); destination != "" && destination != sp.ACSURL {
+		return nil, ErrInvalidSAMLResponse
+	}
+	if issuer := response.child(samlAssertionNamespace, "Issuer"); issuer != nil && issuer.text() != provider.EntityID {
+		return nil, ErrInvalidSAMLResponse
+	}
+
+	status := response.child(samlProtocolNamespace, "Status")
+	if status == nil {
+		return nil, ErrInvalidSAMLResponse
+	}
+	if code := status.child(samlProtocolNamespace, "StatusCode"); code == nil || code.attr("Value") != samlStatusSuccess {
+		return nil, ErrSAMLAuthnFailed
+	}
+
+	// Encrypted assertions are not supported, and a response carrying more
+	// than one assertion leaves it unclear which one to trust.
+	if len(response.childrenNamed(samlAssertionNamespace, "EncryptedAssertion")) != 0 {
+		return nil, ErrInvalidSAMLResponse
+	}
+	assertion := response.child(samlAssertionNamespace, "Assertion")
+	if assertion == nil || assertion.attr("Version") != "2.0" {
+		return nil, ErrInvalidSAMLResponse
+	}
+
+	// The signature is verified on the very nodes read below, so content
+	// outside the signed element cannot be passed off as signed.
+	certificates, err := parseSAMLCertificates(provider.Certificate)
+	if err != nil {
+		return nil, err
+	}
+	signed := assertion
+	if assertion.child(xmlDSigNamespace, "Signature") == nil {
+		signed = response
+	}
+	if err := verifyEnvelopedSignature(signed, certificates); err != nil {
+		return nil, ErrInvalidSAMLSignature
+	}
+
+	if issuer := assertion.child(samlAssertionNamespace, "Issuer"); issuer == nil || issuer.text() != provider.EntityID {
+		return nil, ErrInvalidSAMLResponse
+	}
+
+	now := time.Now()
+	conditions := assertion.child(samlAssertionNamespace, "Conditions")
+	if conditions == nil || !withinValidityPeriod(conditions, now) {
+		return nil, ErrInvalidSAMLResponse
+	}
+	restrictions := conditions.childrenNamed(samlAssertionNamespace, "AudienceRestriction")
+	if len(restrictions) == 0 {
+		return nil, ErrInvalidSAMLResponse
+	}
+	for _, restriction := range restrictions {
+		if !hasAudience(restriction, sp.EntityID) {
+			return nil, ErrInvalidSAMLResponse
+		}
+	}
+
+	if len(assertion.childrenNamed(samlAssertionNamespace, "AuthnStatement")) == 0 {
+		return nil, ErrInvalidSAMLResponse
+	}
+
+	subject := assertion.child(samlAssertionNamespace, "Subject")
+	if subject == nil {
+		return nil, ErrInvalidSAMLResponse
+	}
+	if nameID := subject.child(samlAssertionNamespace, "NameID"); nameID == nil || nameID.text() == "" {
+		return nil, ErrInvalidSAMLResponse
+	}
+	inResponseTo := ""
+	for _, confirmation := range subject.childrenNamed(samlAssertionNamespace, "SubjectConfirmation") {
+		data := confirmation.child(samlAssertionNamespace, "SubjectConfirmationData")
+		if confirmation.attr("Method") != samlConfirmationBearer || data == nil ||
+			data.attr("Recipient") != sp.ACSURL || data.attr("NotOnOrAfter") == "" ||
+			data.attr("InResponseTo") == "" || !withinValidityPeriod(data, now) {
+			continue
+		}
+		inResponseTo = data.attr("InResponseTo")
+		break
+	}
+	if inResponseTo == "" {
+		return nil, ErrInvalidSAMLResponse
+	}
+	if responseTo := response.attr("InResponseTo"); responseTo != "" && responseTo != inResponseTo {
+		return nil, ErrInvalidSAMLResponse
+	}
+
+	// Consuming the request makes each response usable once.
+	request, err := s.requestRepo.Consume(ctx, inResponseTo)
+	if err != nil || request.SAMLProviderID != provider.ID || now.After(request.ExpiresAt) {
+		return nil, ErrInvalidSAMLResponse
+	}
+
+	return assertion, nil
+}
+
+func (s *SAMLService) findEnabledProvider(ctx context.Context, id string) (*models.SAMLProvider, error) {
+	providerID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := s.providerRepo.FindByID(ctx, providerID)
+	if err != nil || !provider.Enabled {
+		return nil, ErrSAMLProviderNotFound
+	}
+	return provider, nil
+}
+
+// withinValidityPeriod reports whether now is within the NotBefore and
+// NotOnOrAfter attributes of element, allowing for samlClockSkew. Missing
+// bounds are not checked; malformed ones fail.
+func withinValidityPeriod(element *xmlNode, now time.Time) bool {
+	if value := element.attr("NotBefore"); value != "" {
+		notBefore, err := time.Parse(time.RFC3339, value)
+		if err != nil || now.Add(samlClockSkew).Before(notBefore) {
+			return false
+		}
+	}
+	if value := element.attr("NotOnOrAfter"); value != "" {
+		notOnOrAfter, err := time.Parse(time.RFC3339, value)
+		if err != nil || !now.Add(-samlClockSkew).Before(notOnOrAfter) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAudience(restriction *xmlNode, audience string) bool {
+	for _, candidate := range restriction.childrenNamed(samlAssertionNamespace, "Audience") {
+		if candidate.text() == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// assertionAttributes returns the values of the attributes of assertion
+// by attribute name.
+func assertionAttributes(assertion *xmlNode) map[string][]string {
+	attributes := map[string][]string{}
+	for _, statement := range assertion.childrenNamed(samlAssertionNamespace, "AttributeStatement") {
+		for _, attribute := range statement.childrenNamed(samlAssertionNamespace, "Attribute") {
+			name := attribute.attr("Name")
+			for _, value := range attribute.childrenNamed(samlAssertionNamespace, "AttributeValue") {
+				attributes[name] = append(attributes[name], value.text())
+			}
+		}
+	}
+	return attributes
+}
+
+// authnRequest is the AuthnRequest sent to identity providers.
+type authnRequest struct {
+	XMLName                     xml.Name     `xml:"samlp:AuthnRequest"`
+	ProtocolNamespace           string       `xml:"xmlns:samlp,attr"`
+	AssertionNamespace          string       `xml:"xmlns:saml,attr"`
+	ID                          string       `xml:"ID,attr"`
+	Version                     string       `xml:"Version,attr"`
+	IssueInstant                string       `xml:"IssueInstant,attr"`
+	Destination                 string       `xml:"Destination,attr"`
+	ProtocolBinding             string       `xml:"ProtocolBinding,attr"`
+	AssertionConsumerServiceURL string       `xml:"AssertionConsumerServiceURL,attr"`
+	Issuer                      string       `xml:"saml:Issuer"`
+	NameIDPolicy                nameIDPolicy `xml:"samlp:NameIDPolicy"`
+}
+
+type nameIDPolicy struct {
+	AllowCreate bool `xml:"AllowCreate,attr"`
+}
+
+// spEntityDescriptor is the metadata of a service provider.
+type spEntityDescriptor struct {
+	XMLName         xml.Name        `xml:"md:EntityDescriptor"`
+	Namespace       string          `xml:"xmlns:md,attr"`
+	EntityID        string          `xml:"entityID,attr"`
+	SPSSODescriptor spSSODescriptor `xml:"md:SPSSODescriptor"`
+}
+
+type spSSODescriptor struct {
+	AuthnRequestsSigned        bool            `xml:"AuthnRequestsSigned,attr"`
+	WantAssertionsSigned       bool            `xml:"WantAssertionsSigned,attr"`
+	ProtocolSupportEnumeration string          `xml:"protocolSupportEnumeration,attr"`
+	NameIDFormat               string          `xml:"md:NameIDFormat"`
+	AssertionConsumerService   indexedEndpoint `xml:"md:AssertionConsumerService"`
+}
+
+type indexedEndpoint struct {
+	Binding   string `xml:"Binding,attr"`
+	Location  string `xml:"Location,attr"`
+	Index     int    `xml:"index,attr"`
+	IsDefault bool   `xml:"isDefault,attr"`
+}
@@ -0,0 +1,492 @@
+package user_management
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+const (
+	testIdPEntityID = "https://idp.acme.test/saml"
+	testIdPSSOURL   = "https://idp.acme.test/saml/sso"
+	xmlSchema       = "http://www.w3.org/2001/XMLSchema"
+	xmlSchemaInst   = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// testIdentityProvider signs SAML assertions with a locally generated key
+// and self-signed certificate.
+type testIdentityProvider struct {
+	key         *rsa.PrivateKey
+	certificate []byte
+}
+
+func newTestIdentityProvider(t *testing.T) *testIdentityProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.acme.test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return &testIdentityProvider{key: key, certificate: certificate}
+}
+
+func (idp *testIdentityProvider) metadata() string {
+	return `<?xml version="1.0"?>
+<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" xmlns:ds="http://www.w3.org/2000/09/xmldsig#" entityID="` + testIdPEntityID + `">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="encryption"><ds:KeyInfo><ds:X509Data><ds:X509Certificate>bm90IGEgY2VydGlmaWNhdGU=</ds:X509Certificate></ds:X509Data></ds:KeyInfo></md:KeyDescriptor>
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo>
+        <ds:X509Data>
+          <ds:X509Certificate>
+            ` + base64.StdEncoding.EncodeToString(idp.certificate) + `
+          </ds:X509Certificate>
+        </ds:X509Data>
+      </ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="https://idp.acme.test/saml/post"/>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="` + testIdPSSOURL + `"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`
+}
+
+// samlAssertion describes the assertion of a test response.
+type samlAssertion struct {
+	RequestID    string
+	Issuer       string
+	Audience     string
+	Recipient    string
+	NotOnOrAfter time.Time
+	NameID       string
+	Attributes   map[string][]string
+	// SigningKey signs the assertion; nil leaves it unsigned.
+	SigningKey *rsa.PrivateKey
+}
+
+// response returns the base64 encoded SAML response carrying assertion.
+// The assertion and its SignedInfo are written in canonical form so the
+// signature does not depend on the canonicalization under test, and are
+// then stripped of the namespace declarations they inherit from the
+// response, as identity providers send them.
+func (a samlAssertion) response() string {
+	now := time.Now().UTC()
+	var attributes strings.Builder
+	for _, name := range []string{"email", "firstName", "lastName", "groups"} {
+		if len(a.Attributes[name]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&attributes, `<saml:Attribute Name="%s">`, name)
+		for _, value := range a.Attributes[name] {
+			fmt.Fprintf(&attributes, `<saml:AttributeValue xmlns:xsi="%s" xsi:type="xs:string">%s</saml:AttributeValue>`, xmlSchemaInst, value)
+		}
+		attributes.WriteString(`</saml:Attribute>`)
+	}
+
+	inherited := fmt.Sprintf(` xmlns:saml="%s" xmlns:xs="%s"`, samlAssertionNamespace, xmlSchema)
+	issuer := fmt.Sprintf(`<saml:Issuer>%s</saml:Issuer>`, a.Issuer)
+	assertion := fmt.Sprintf(`<saml:Assertion%s ID="_assertion" IssueInstant="%s" Version="2.0">%s`+
+		`<saml:Subject><saml:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress">%s</saml:NameID>`+
+		`<saml:SubjectConfirmation Method="%s"><saml:SubjectConfirmationData InResponseTo="%s" NotOnOrAfter="%s" Recipient="%s"></saml:SubjectConfirmationData></saml:SubjectConfirmation></saml:Subject>`+
+		`<saml:Conditions NotBefore="%s" NotOnOrAfter="%s"><saml:AudienceRestriction><saml:Audience>%s</saml:Audience></saml:AudienceRestriction></saml:Conditions>`+
+		`<saml:AuthnStatement AuthnInstant="%s"></saml:AuthnStatement>`+
+		`<saml:AttributeStatement>%s</saml:AttributeStatement></saml:Assertion>`,
+		inherited, now.Format(time.RFC3339), issuer,
+		a.NameID,
+		samlConfirmationBearer, a.RequestID, a.NotOnOrAfter.Format(time.RFC3339), a.Recipient,
+		now.Add(-time.Minute).Format(time.RFC3339), a.NotOnOrAfter.Format(time.RFC3339), a.Audience,
+		now.Format(time.RFC3339),
+		attributes.String())
+
+	signature := ""
+	if a.SigningKey != nil {
+		digest := sha256.Sum256([]byte(assertion))
+		signedInfo := fmt.Sprintf(`<ds:SignedInfo xmlns:ds="%s">`+
+			`<ds:CanonicalizationMethod Algorithm="%s"></ds:CanonicalizationMethod>`+
+			`<ds:SignatureMethod Algorithm="%s"></ds:SignatureMethod>`+
+			`<ds:Reference URI="#_assertion"><ds:Transforms>`+
+			`<ds:Transform Algorithm="%s"></ds:Transform>`+
+			`<ds:Transform Algorithm="%s"><ec:InclusiveNamespaces xmlns:ec="%s" PrefixList="xs"></ec:InclusiveNamespaces></ds:Transform>`+
+			`</ds:Transforms><ds:DigestMethod Algorithm="%s"></ds:DigestMethod><ds:DigestValue>%s</ds:DigestValue></ds:Reference></ds:SignedInfo>`,
+			xmlDSigNamespace, xmlAlgorithmExcC14N, xmlAlgorithmRSASHA256, xmlAlgorithmEnveloped, xmlAlgorithmExcC14N, xmlExcC14NNamespace,
+			xmlAlgorithmSHA256, base64.StdEncoding.EncodeToString(digest[:]))
+		hashed := sha256.Sum256([]byte(signedInfo))
+		value, err := rsa.SignPKCS1v15(rand.Reader, a.SigningKey, crypto.SHA256, hashed[:])
+		if err != nil {
+			panic(err)
+		}
+
+		signedInfo = strings.Replace(signedInfo, fmt.Sprintf(` xmlns:ds="%s"`, xmlDSigNamespace), "", 1)
+		for _, element := range []string{"ds:CanonicalizationMethod", "ds:SignatureMethod", "ds:Transform", "ds:DigestMethod"} {
+			signedInfo = strings.ReplaceAll(signedInfo, `"></`+element+">", `"/>`)
+		}
+		encoded := base64.StdEncoding.EncodeToString(value)
+		signature = fmt.Sprintf("<ds:Signature xmlns:ds=\"%s\">\n  %s\n  <ds:SignatureValue>%s\n%s</ds:SignatureValue>\n</ds:Signature>",
+			xmlDSigNamespace, signedInfo, encoded[:64], encoded[64:])
+	}
+
+	assertion = strings.Replace(assertion, inherited, "", 1)
+	assertion = strings.ReplaceAll(assertion, fmt.Sprintf(` xmlns:xsi="%s"`, xmlSchemaInst), "")
+	assertion = strings.Replace(assertion, issuer, issuer+signature, 1)
+
+	response := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<samlp:Response xmlns:samlp="%s" xmlns:saml="%s" xmlns:xs="%s" xmlns:xsi="%s" Destination="%s" ID="_response" InResponseTo="%s" IssueInstant="%s" Version="2.0">
+  <saml:Issuer>%s</saml:Issuer>
+  <samlp:Status><samlp:StatusCode Value="%s"/></samlp:Status>
+  %s
+</samlp:Response>`,
+		samlProtocolNamespace, samlAssertionNamespace, xmlSchema, xmlSchemaInst, a.Recipient, a.RequestID, now.Format(time.RFC3339),
+		a.Issuer, samlStatusSuccess, assertion)
+	return base64.StdEncoding.EncodeToString([]byte(response))
+}
+
+type samlTest struct {
+	*federationTest
+	idp       *testIdentityProvider
+	provider  *models.SAMLProvider
+	providers *SAMLProviderService
+	saml      *SAMLService
+	sp        *SAMLServiceProvider
+}
+
+func newSAMLTest(t *testing.T) *samlTest {
+	t.Helper()
+
+	ft := newFederationTest(t)
+	providerRepo := user_management.NewSAMLProviderRepository(ft.db)
+	providers := NewSAMLProviderService(providerRepo, ft.identityRepo, ft.roleRepo, ft.authz, ft.auditService)
+	oidcService := NewOIDCService(ft.tenantRepo, ft.userRepo, nil, nil, nil, ft.auditService, "https://api.adminsuite.test", "https://app.acme.test", "adminsuite.test")
+
+	idp := newTestIdentityProvider(t)
+	metadata := idp.metadata()
+	trustEmail := true
+	provider, err := providers.CreateProvider(ft.ctx, &models.User{}, SAMLProviderInput{
+		Name:            stringPtr("Acme SSO"),
+		Metadata:        &metadata,
+		GroupsAttribute: stringPtr("groups"),
+		TrustEmail:      &trustEmail,
+	}, ClientInfo{})
+	if err != nil {
+		t.Fatalf("failed to create SAML provider: %v", err)
+	}
+
+	saml := NewSAMLService(providerRepo, user_management.NewSAMLRequestRepository(ft.db), ft.tenantRepo, ft.federation, oidcService)
+	sp, err := saml.ServiceProvider(ft.ctx, provider)
+	if err != nil {
+		t.Fatalf("failed to describe service provider: %v", err)
+	}
+
+	return &samlTest{
+		federationTest: ft,
+		idp:            idp,
+		provider:       provider,
+		providers:      providers,
+		saml:           saml,
+		sp:             sp,
+	}
+}
+
+// startLogin starts a sign-in and returns the ID of the authentication
+// request sent to the identity provider.
+func (st *samlTest) startLogin(t *testing.T) string {
+	t.Helper()
+
+	redirectURL, err := st.saml.StartLogin(st.ctx, st.provider.ID.String())
+	if err != nil {
+		t.Fatalf("failed to start login: %v", err)
+	}
+	parsed, err := url.Parse(redirectURL)
+	if err != nil || !strings.HasPrefix(redirectURL, testIdPSSOURL+"?") {
+		t.Fatalf("redirect URL %q does not point at the identity provider", redirectURL)
+	}
+	deflated, err := base64.StdEncoding.DecodeString(parsed.Query().Get("SAMLRequest"))
+	if err != nil {
+		t.Fatalf("failed to decode SAMLRequest: %v", err)
+	}
+	encoded, err := io.ReadAll(flate.NewReader(bytes.NewReader(deflated)))
+	if err != nil {
+		t.Fatalf("failed to inflate SAMLRequest: %v", err)
+	}
+	request, err := parseXML(encoded)
+	if err != nil || !request.is(samlProtocolNamespace, "AuthnRequest") {
+		t.Fatalf("SAMLRequest is not an AuthnRequest: %s", encoded)
+	}
+	if request.attr("AssertionConsumerServiceURL") != st.sp.ACSURL || request.child(samlAssertionNamespace, "Issuer").text() != st.sp.EntityID {
+		t.Fatalf("AuthnRequest names the wrong service provider: %s", encoded)
+	}
+	return request.attr("ID")
+}
+
+// assertion returns a valid assertion answering requestID.
+func (st *samlTest) assertion(requestID string) samlAssertion {
+	return samlAssertion{
+		RequestID:    requestID,
+		Issuer:       testIdPEntityID,
+		Audience:     st.sp.EntityID,
+		Recipient:    st.sp.ACSURL,
+		NotOnOrAfter: time.Now().Add(5 * time.Minute),
+		NameID:       "jane@acme.test",
+		Attributes: map[string][]string{
+			"email":     {"jane@acme.test"},
+			"firstName": {"Jane"},
+			"lastName":  {"Doe"},
+			"groups":    {"admins", "staff"},
+		},
+		SigningKey: st.idp.key,
+	}
+}
+
+func (st *samlTest) completeLogin(response string) (*models.User, error) {
+	user, accessToken, refreshToken, err := st.saml.CompleteLogin(st.ctx, st.provider.ID.String(), response, ClientInfo{})
+	if err == nil && (accessToken == "" || refreshToken == "") {
+		return nil, fmt.Errorf("sign-in issued no tokens")
+	}
+	return user, err
+}
+
+func TestSAMLProviderReadsIdentityProviderMetadata(t *testing.T) {
+	st := newSAMLTest(t)
+
+	if st.provider.EntityID != testIdPEntityID || st.provider.SSOURL != testIdPSSOURL {
+		t.Fatalf("provider = %q at %q, want %q at %q", st.provider.EntityID, st.provider.SSOURL, testIdPEntityID, testIdPSSOURL)
+	}
+	certificates, err := parseSAMLCertificates(st.provider.Certificate)
+	if err != nil || len(certificates) != 1 || !bytes.Equal(certificates[0].Raw, st.idp.certificate) {
+		t.Fatalf("provider certificate is not the signing certificate of the metadata: %v", err)
+	}
+
+	invalid := "<md:EntityDescriptor xmlns:md=\"urn:oasis:names:tc:SAML:2.0:metadata\" entityID=\"x\"/>"
+	if _, err := st.providers.CreateProvider(st.ctx, &models.User{}, SAMLProviderInput{Name: stringPtr("Broken"), Metadata: &invalid}, ClientInfo{}); err != ErrInvalidSAMLMetadata {
+		t.Fatalf("metadata without an identity provider returned %v, want ErrInvalidSAMLMetadata", err)
+	}
+
+	key := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(st.idp.key)}))
+	if _, err := st.providers.UpdateProvider(st.ctx, &models.User{}, st.provider.ID.String(), SAMLProviderInput{Certificate: &key}, ClientInfo{}); err != ErrInvalidSAMLCertificate {
+		t.Fatalf("a private key as certificate returned %v, want ErrInvalidSAMLCertificate", err)
+	}
+}
+
+func TestSAMLServiceProviderMetadata(t *testing.T) {
+	st := newSAMLTest(t)
+
+	metadata, err := st.saml.Metadata(st.ctx, st.provider.ID.String())
+	if err != nil {
+		t.Fatalf("failed to build metadata: %v", err)
+	}
+	root, err := parseXML(metadata)
+	if err != nil || !root.is(samlMetadataNamespace, "EntityDescriptor") {
+		t.Fatalf("metadata is not an EntityDescriptor: %s", metadata)
+	}
+	if root.attr("entityID") != st.sp.EntityID || !strings.HasPrefix(st.sp.EntityID, "https://acme.test/api/v1/auth/saml/") {
+		t.Fatalf("metadata entity ID = %q, want the tenant's metadata URL", root.attr("entityID"))
+	}
+	descriptor := root.child(samlMetadataNamespace, "SPSSODescriptor")
+	if descriptor == nil || descriptor.attr("WantAssertionsSigned") != "true" {
+		t.Fatalf("metadata does not require signed assertions: %s", metadata)
+	}
+	acs := descriptor.child(samlMetadataNamespace, "AssertionConsumerService")
+	if acs == nil || acs.attr("Location") != st.sp.ACSURL || acs.attr("Binding") != samlBindingHTTPPost {
+		t.Fatalf("metadata does not name the ACS URL %q: %s", st.sp.ACSURL, metadata)
+	}
+}
+
+func TestSAMLLoginProvisionsUserWithMappedRoles(t *testing.T) {
+	st := newSAMLTest(t)
+
+	admins := st.createRole(t, "admin")
+	developers := st.createRole(t, "developer")
+	if _, err := st.providers.UpdateProvider(st.ctx, &models.User{}, st.provider.ID.String(), SAMLProviderInput{
+		AttributeMappings: &SAMLAttributeMapping{Email: "email"},
+		RoleMappings: []GroupRoleMapping{
+			{Group: "admins", RoleID: admins.ID},
+			{Group: "engineering", RoleID: developers.ID},
+		},
+	}, ClientInfo{}); err != nil {
+		t.Fatalf("failed to set role mappings: %v", err)
+	}
+
+	user, err := st.completeLogin(st.assertion(st.startLogin(t)).response())
+	if err != nil {
+		t.Fatalf("sign-in failed: %v", err)
+	}
+	if user.Email != "jane@acme.test" || user.Username != "jane" || user.FirstName != "Jane" || user.LastName != "Doe" || !user.EmailVerified {
+		t.Fatalf("provisioned user = %+v, want jane@acme.test with the asserted names", user)
+	}
+	if roles := st.roleNames(t, user.ID); !roles["admin"] || roles["developer"] {
+		t.Fatalf("roles = %v, want admin only", roles)
+	}
+	if _, err := st.identityRepo.FindBySubject(st.ctx, st.provider.ID, "jane@acme.test"); err != nil {
+		t.Fatalf("user was not linked to the NameID: %v", err)
+	}
+
+	assertion := st.assertion(st.startLogin(t))
+	assertion.Attributes["groups"] = []string{"engineering"}
+	again, err := st.completeLogin(assertion.response())
+	if err != nil {
+		t.Fatalf("second sign-in failed: %v", err)
+	}
+	if again.ID != user.ID {
+		t.Fatalf("second sign-in resolved user %s, want %s", again.ID, user.ID)
+	}
+	if roles := st.roleNames(t, user.ID); roles["admin"] || !roles["developer"] {
+		t.Fatalf("roles after second sign-in = %v, want developer only", roles)
+	}
+}
+
+func TestSAMLLoginTrustsEmailOnlyIfConfigured(t *testing.T) {
+	st := newSAMLTest(t)
+
+	trustEmail := false
+	if _, err := st.providers.UpdateProvider(st.ctx, &models.User{}, st.provider.ID.String(), SAMLProviderInput{TrustEmail: &trustEmail}, ClientInfo{}); err != nil {
+		t.Fatalf("failed to distrust the provider: %v", err)
+	}
+	if _, err := st.completeLogin(st.assertion(st.startLogin(t)).response()); err != ErrFederatedEmailNotVerified {
+		t.Fatalf("sign-in through an untrusted provider returned %v, want ErrFederatedEmailNotVerified", err)
+	}
+	if _, err := st.userRepo.FindByEmail(st.ctx, "jane@acme.test"); err == nil {
+		t.Fatalf("user was provisioned with an untrusted email address")
+	}
+
+	trustEmail = true
+	if _, err := st.providers.UpdateProvider(st.ctx, &models.User{}, st.provider.ID.String(), SAMLProviderInput{TrustEmail: &trustEmail}, ClientInfo{}); err != nil {
+		t.Fatalf("failed to trust the provider: %v", err)
+	}
+	existing := st.createUser(t, "jane@acme.test", "Secret-pass-1")
+	existing.IsSuperAdmin = true
+	if err := st.userRepo.Update(st.ctx, existing); err != nil {
+		t.Fatalf("failed to update user: %v", err)
+	}
+
+	if _, err := st.completeLogin(st.assertion(st.startLogin(t)).response()); err != ErrFederatedLinkPending {
+		t.Fatalf("sign-in as an existing user returned %v, want ErrFederatedLinkPending", err)
+	}
+	identity, err := st.identityRepo.FindBySubject(st.ctx, st.provider.ID, "jane@acme.test")
+	if err != nil || !identity.Pending || identity.UserID != existing.ID {
+		t.Fatalf("link = %+v (err %v), want a pending link to the existing user", identity, err)
+	}
+}
+
+func TestSAMLLoginRejectsInvalidSignatures(t *testing.T) {
+	st := newSAMLTest(t)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	tests := map[string]func(requestID string) string{
+		"unsigned": func(requestID string) string {
+			assertion := st.assertion(requestID)
+			assertion.SigningKey = nil
+			return assertion.response()
+		},
+		"wrong key": func(requestID string) string {
+			assertion := st.assertion(requestID)
+			assertion.SigningKey = otherKey
+			return assertion.response()
+		},
+		"tampered": func(requestID string) string {
+			return tamper(st.assertion(requestID).response(), func(response string) string {
+				return strings.Replace(response, ">admins<", ">owners<", 1)
+			})
+		},
+		"wrapped": func(requestID string) string {
+			// The signed assertion is moved into the response extensions and
+			// replaced by an unsigned one for another user.
+			return tamper(st.assertion(requestID).response(), func(response string) string {
+				start := strings.Index(response, "<saml:Assertion")
+				end := strings.Index(response, "</saml:Assertion>") + len("</saml:Assertion>")
+				signed := response[start:end]
+				forged := strings.Replace(signed, `ID="_assertion"`, `ID="_forged"`, 1)
+				forged = strings.ReplaceAll(forged, "jane@acme.test", "mallory@acme.test")
+				return response[:start] + "<samlp:Extensions>" + signed + "</samlp:Extensions>" + forged + response[end:]
+			})
+		},
+	}
+	for name, response := range tests {
+		t.Run(name, func(t *testing.T) {
+			user, err := st.completeLogin(response(st.startLogin(t)))
+			if err != ErrInvalidSAMLSignature {
+				t.Fatalf("sign-in returned %v for user %v, want ErrInvalidSAMLSignature", err, user)
+			}
+		})
+	}
+}
+
+func TestSAMLLoginRejectsInvalidAssertions(t *testing.T) {
+	st := newSAMLTest(t)
+
+	tests := map[string]func(assertion *samlAssertion){
+		"wrong audience":  func(assertion *samlAssertion) { assertion.Audience = "https://other.test/saml" },
+		"wrong issuer":    func(assertion *samlAssertion) { assertion.Issuer = "https://other.test/idp" },
+		"wrong recipient": func(assertion *samlAssertion) { assertion.Recipient = "https://other.test/acs" },
+		"expired":         func(assertion *samlAssertion) { assertion.NotOnOrAfter = time.Now().Add(-10 * time.Minute) },
+		"unsolicited":     func(assertion *samlAssertion) { assertion.RequestID = "_unknown" },
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			assertion := st.assertion(st.startLogin(t))
+			mutate(&assertion)
+			if _, err := st.completeLogin(assertion.response()); err != ErrInvalidSAMLResponse {
+				t.Fatalf("sign-in returned %v, want ErrInvalidSAMLResponse", err)
+			}
+		})
+	}
+
+	t.Run("document type", func(t *testing.T) {
+		response := tamper(st.assertion(st.startLogin(t)).response(), func(response string) string {
+			return strings.Replace(response, "<samlp:Response", `<!DOCTYPE r [<!ENTITY x "x">]><samlp:Response`, 1)
+		})
+		if _, err := st.completeLogin(response); err != ErrInvalidSAMLResponse {
+			t.Fatalf("sign-in returned %v, want ErrInvalidSAMLResponse", err)
+		}
+	})
+}
+
+func TestSAMLLoginRejectsReplayedResponse(t *testing.T) {
+	st := newSAMLTest(t)
+
+	response := st.assertion(st.startLogin(t)).response()
+	if _, err := st.completeLogin(response); err != nil {
+		t.Fatalf("sign-in failed: %v", err)
+	}
+	if _, err := st.completeLogin(response); err != ErrInvalidSAMLResponse {
+		t.Fatalf("replayed response returned %v, want ErrInvalidSAMLResponse", err)
+	}
+}
+
+// tamper applies change to the XML of an encoded response.
+func tamper(response string, change func(string) string) string {
+	decoded, err := base64.StdEncoding.DecodeString(response)
+	if err != nil {
+		panic(err)
+	}
+	return base64.StdEncoding.EncodeToString([]byte(change(string(decoded))))
+}
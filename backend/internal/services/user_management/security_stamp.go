@@ -0,0 +1,114 @@
+package user_management
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+// securityStampCacheTTL bounds how long a stamp is trusted without asking the
+// database. Rotations made by this process take effect immediately; those
+// made by other instances take effect within this window.
+const securityStampCacheTTL = 30 * time.Second
+
+type cachedSecurityStamp struct {
+	stamp     string
+	fetchedAt time.Time
+}
+
+// SecurityStampService versions the authorization-relevant state of a user.
+// Access tokens carry the stamp they were issued under, and their claims are
+// only trusted while it is still the user's current stamp. The stamp is
+// rotated whenever the user's roles, password, MFA status or active state
+// change, which also revokes the user's outstanding access tokens, and for
+// the whole tenant when roles or permissions themselves change.
+type SecurityStampService struct {
+	userRepo    user_management.UserRepository
+	revocations *TokenRevocationService
+
+	mu     sync.Mutex
+	stamps map[uuid.UUID]cachedSecurityStamp
+}
+
+func NewSecurityStampService(userRepo user_management.UserRepository, revocations *TokenRevocationService) *SecurityStampService {
+	return &SecurityStampService{
+		userRepo:    userRepo,
+		revocations: revocations,
+		stamps:      make(map[uuid.UUID]cachedSecurityStamp),
+	}
+}
+
+// Current returns the user's current security stamp, from the cache when it
+// was fetched recently.
+func (s *SecurityStampService) Current(ctx context.Context, userID uuid.UUID) (string, error) {
+	s.mu.Lock()
+	cached, ok := s.stamps[userID]
+	s.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < securityStampCacheTTL {
+		return cached.stamp, nil
+	}
+
+	stamp, err := s.userRepo.FindSecurityStamp(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	s.remember(userID, stamp)
+	return stamp, nil
+}
+
+// Rotate gives user a new security stamp and revokes the access tokens
+// issued to user before the change, so that clients have to refresh them.
+func (s *SecurityStampService) Rotate(ctx context.Context, user *models.User) error {
+	stamp, err := newSecurityStamp()
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdateSecurityStamp(ctx, user.ID, stamp); err != nil {
+		return err
+	}
+
+	user.SecurityStamp = stamp
+	s.remember(user.ID, stamp)
+	return s.revocations.RevokeUser(ctx, user.ID)
+}
+
+// RotateTenant gives every user of the tenant a new security stamp. It is
+// used when a change to roles or permissions can affect any of them. Access
+// tokens stay valid, but their claims are re-checked against the database.
+func (s *SecurityStampService) RotateTenant(ctx context.Context, tenantID uuid.UUID) error {
+	stamp, err := newSecurityStamp()
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdateSecurityStampsByTenant(ctx, tenantID, stamp); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.stamps = make(map[uuid.UUID]cachedSecurityStamp)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *SecurityStampService) remember(userID uuid.UUID, stamp string) {
+	s.mu.Lock()
+	s.stamps[userID] = cachedSecurityStamp{stamp: stamp, fetchedAt: time.Now()}
+	s.mu.Unlock()
+}
+
+func newSecurityStamp() (string, error) {
+	b, err := generateRandomBytes(16)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,393 @@
+package user_management
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+var (
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+type SessionService struct {
+	sessionRepo  user_management.SessionRepository
+	tokenRepo    user_management.TokenRepository
+	userRepo     user_management.UserRepository
+	tokenHasher  *TokenHasher
+	revocations  *TokenRevocationService
+	auditService *AuditService
+}
+
+func NewSessionService(
+	sessionRepo user_management.SessionRepository,
+	tokenRepo user_management.TokenRepository,
+	userRepo user_management.UserRepository,
+	tokenHasher *TokenHasher,
+	revocations *TokenRevocationService,
+	auditService *AuditService,
+) *SessionService {
+	return &SessionService{
+		sessionRepo:  sessionRepo,
+		tokenRepo:    tokenRepo,
+		userRepo:     userRepo,
+		tokenHasher:  tokenHasher,
+		revocations:  revocations,
+		auditService: auditService,
+	}
+}
+
+// StartSession records a new sign-in of user from client that lasts until
+// expiresAt unless it is used again. Sign-ins with method AuthMethodMFA
+// mark the session's second factor as verified now.
+func (s *SessionService) StartSession(ctx context.Context, user *models.User, deviceID *uuid.UUID, method string, expiresAt time.Time, client ClientInfo) (*models.Session, error) {
+	session := &models.Session{
+		UserID:     user.ID,
+		DeviceID:   deviceID,
+		AuthMethod: method,
+	}
+	if method == AuthMethodMFA {
+		now := time.Now()
+		session.MFAVerifiedAt = &now
+	}
+	return s.startSession(ctx, session, expiresAt, client)
+}
+
+// StartClientSession records that user signed in to oauthClient, granting
+// it scopes from the session with ID parentSessionID, if known. The session
+// lasts until expiresAt unless it is used again.
+func (s *SessionService) StartClientSession(ctx context.Context, user *models.User, oauthClient *models.OAuthClient, parentSessionID *uuid.UUID, scopes []string, method string, expiresAt time.Time, client ClientInfo) (*models.Session, error) {
+	session := &models.Session{
+		UserID:          user.ID,
+		OAuthClientID:   &oauthClient.ID,
+		ParentSessionID: parentSessionID,
+		Scopes:          strings.Join(scopes, " "),
+		AuthMethod:      method,
+	}
+	if parentSessionID != nil {
+		if parent, err := s.sessionRepo.FindByID(ctx, *parentSessionID); err == nil && parent.UserID == user.ID {
+			session.MFAVerifiedAt = parent.MFAVerifiedAt
+		}
+	}
+	return s.startSession(ctx, session, expiresAt, client)
+}
+
+func (s *SessionService) startSession(ctx context.Context, session *models.Session, expiresAt time.Time, client ClientInfo) (*models.Session, error) {
+	session.IP = client.IP
+	session.UserAgent = truncate(client.UserAgent, maxUserAgentLength)
+	session.LastUsedAt = time.Now()
+	session.ExpiresAt = expiresAt
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// ResumeSession returns the session token was issued in, marked as used by
+// client and extended to expiresAt. oauthClient is the OAuth client
+// presenting the token, or nil for AdminSuite's own clients; the tokens of
+// a client's session only work for that client. Refresh tokens issued
+// before sessions were recorded get a session of their own.
+func (s *SessionService) ResumeSession(ctx context.Context, user *models.User, token *models.Token, oauthClient *models.OAuthClient, expiresAt time.Time, client ClientInfo) (*models.Session, error) {
+	if token.SessionID == nil {
+		if oauthClient != nil {
+			return nil, ErrSessionNotFound
+		}
+		method := token.AuthMethod
+		if method == "" {
+			method = AuthMethodPassword
+		}
+		// Refreshing is no second factor, so the new session is not marked
+		// as verified even if the original sign-in was.
+		return s.startSession(ctx, &models.Session{
+			UserID:     user.ID,
+			DeviceID:   token.DeviceID,
+			AuthMethod: method,
+		}, expiresAt, client)
+	}
+
+	session, err := s.sessionRepo.FindByID(ctx, *token.SessionID)
+	if err != nil || session.UserID != user.ID || session.ExpiresAt.Before(time.Now()) {
+		return nil, ErrSessionNotFound
+	}
+	if !sessionOfClient(session, oauthClient) {
+		return nil, ErrSessionNotFound
+	}
+
+	session.IP = client.IP
+	session.UserAgent = truncate(client.UserAgent, maxUserAgentLength)
+	session.LastUsedAt = time.Now()
+	session.ExpiresAt = expiresAt
+	if err := s.sessionRepo.Update(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// ListSessions returns the active sessions of the user with ID userID,
+// most recently used first.
+func (s *SessionService) ListSessions(ctx context.Context, userID string) ([]*models.Session, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sessionRepo.FindActiveByUserID(ctx, user.ID)
+}
+
+// RevokeSession signs the user with ID userID out of one session.
+func (s *SessionService) RevokeSession(ctx context.Context, actor *models.User, userID, sessionID string, client ClientInfo) error {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return ErrSessionNotFound
+	}
+	session, err := s.sessionRepo.FindByID(ctx, id)
+	if err != nil || session.UserID != user.ID {
+		return ErrSessionNotFound
+	}
+
+	if err := s.endSession(ctx, session.ID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    actor.ID,
+		Action:     AuditActionSessionRevoke,
+		Resource:   AuditResourceSession,
+		ResourceID: session.ID.String(),
+		Details:    map[string]interface{}{"user_id": user.ID},
+		Client:     client,
+	})
+
+	return nil
+}
+
+// RevokeSessions signs the user with ID userID out of every session except
+// keep, if given, and returns how many sessions were ended.
+func (s *SessionService) RevokeSessions(ctx context.Context, actor *models.User, userID string, keep *uuid.UUID, client ClientInfo) (int64, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked, err := s.endSessions(ctx, user.ID, keep)
+	if err != nil {
+		return 0, err
+	}
+
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    actor.ID,
+		Action:     AuditActionSessionRevokeAll,
+		Resource:   AuditResourceUser,
+		ResourceID: user.ID.String(),
+		Details:    map[string]interface{}{"revoked": revoked},
+		Client:     client,
+	})
+
+	return revoked, nil
+}
+
+// Logout ends the session refreshToken belongs to.
+func (s *SessionService) Logout(ctx context.Context, refreshToken string, client ClientInfo) error {
+	token, err := s.tokenRepo.FindByTokenHash(ctx, s.tokenHasher.Hash(refreshToken))
+	if err != nil || token.Type != models.TokenTypeRefresh {
+		return ErrInvalidRefreshToken
+	}
+
+	if token.SessionID != nil {
+		err = s.endSession(ctx, *token.SessionID)
+	} else {
+		err = s.tokenRepo.Delete(ctx, token.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    token.UserID,
+		Action:     AuditActionLogout,
+		Resource:   AuditResourceUser,
+		ResourceID: token.UserID.String(),
+		Client:     client,
+	})
+
+	return nil
+}
+
+// RevokeFamily ends the session of token, a refresh token presented again
+// after it was rotated, and records the reuse as a security event. Either
+// the client or someone else holds a copy of the token, and there is no
+// telling which, so every token of the family stops working.
+func (s *SessionService) RevokeFamily(ctx context.Context, token *models.Token, client ClientInfo) error {
+	var err error
+	resourceID := token.ID.String()
+	if token.SessionID != nil {
+		err = s.endSession(ctx, *token.SessionID)
+		resourceID = token.SessionID.String()
+	} else {
+		err = s.tokenRepo.Delete(ctx, token.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    token.UserID,
+		Action:     AuditActionRefreshTokenReuse,
+		Resource:   AuditResourceSession,
+		ResourceID: resourceID,
+		Details: map[string]interface{}{
+			"token_id":   token.ID,
+			"rotated_at": token.RotatedAt,
+		},
+		Client: client,
+	})
+
+	return nil
+}
+
+// EndAllSessions signs user out everywhere without recording an audit
+// entry, for callers that audit the operation that required it.
+func (s *SessionService) EndAllSessions(ctx context.Context, user *models.User) error {
+	_, err := s.endSessions(ctx, user.ID, nil)
+	return err
+}
+
+// EndSession ends one session without recording an audit entry, for
+// callers that audit the operation that required it.
+func (s *SessionService) EndSession(ctx context.Context, sessionID uuid.UUID) error {
+	return s.endSession(ctx, sessionID)
+}
+
+// EndClientSessions ends every session granted to the OAuth client with ID
+// oauthClientID without recording an audit entry.
+func (s *SessionService) EndClientSessions(ctx context.Context, oauthClientID uuid.UUID) error {
+	sessions, err := s.sessionRepo.FindByOAuthClientID(ctx, oauthClientID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if err := s.endSession(ctx, session.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EndDeviceSessions ends every session started from the device with ID
+// deviceID, and drops refresh tokens bound to it outside a session, without
+// recording an audit entry.
+func (s *SessionService) EndDeviceSessions(ctx context.Context, deviceID uuid.UUID) error {
+	sessions, err := s.sessionRepo.FindByDeviceID(ctx, deviceID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if err := s.endSession(ctx, session.ID); err != nil {
+			return err
+		}
+	}
+	return s.tokenRepo.DeleteByDeviceID(ctx, deviceID)
+}
+
+// EndClientSession ends the session with ID sessionID that user granted
+// oauthClient, together with the session the grant was approved from,
+// without recording an audit entry.
+func (s *SessionService) EndClientSession(ctx context.Context, user *models.User, oauthClient *models.OAuthClient, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil || session.UserID != user.ID || !sessionOfClient(session, oauthClient) {
+		return ErrSessionNotFound
+	}
+
+	if err := s.endSession(ctx, session.ID); err != nil {
+		return err
+	}
+	if session.ParentSessionID != nil {
+		return s.endSession(ctx, *session.ParentSessionID)
+	}
+	return nil
+}
+
+// endSession deletes the session with its refresh tokens and revokes the
+// access tokens issued in it.
+func (s *SessionService) endSession(ctx context.Context, sessionID uuid.UUID) error {
+	if err := s.tokenRepo.DeleteBySessionID(ctx, sessionID); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.Delete(ctx, sessionID); err != nil {
+		return err
+	}
+	return s.revocations.RevokeSession(ctx, sessionID)
+}
+
+func (s *SessionService) endSessions(ctx context.Context, userID uuid.UUID, keep *uuid.UUID) (int64, error) {
+	if keep == nil {
+		if err := s.tokenRepo.DeleteByUserID(ctx, userID, models.TokenTypeRefresh); err != nil {
+			return 0, err
+		}
+		revoked, err := s.sessionRepo.DeleteByUserID(ctx, userID, nil)
+		if err != nil {
+			return 0, err
+		}
+		return revoked, s.revocations.RevokeUser(ctx, userID)
+	}
+
+	sessions, err := s.sessionRepo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.tokenRepo.DeleteByUserIDExceptSession(ctx, userID, *keep); err != nil {
+		return 0, err
+	}
+	revoked, err := s.sessionRepo.DeleteByUserID(ctx, userID, keep)
+	if err != nil {
+		return 0, err
+	}
+	for _, session := range sessions {
+		if session.ID == *keep {
+			continue
+		}
+		if err := s.revocations.RevokeSession(ctx, session.ID); err != nil {
+			return 0, err
+		}
+	}
+	return revoked, nil
+}
+
+// sessionOfClient reports whether session was granted to oauthClient, or to
+// none if oauthClient is nil.
+func sessionOfClient(session *models.Session, oauthClient *models.OAuthClient) bool {
+	if oauthClient == nil {
+		return session.OAuthClientID == nil
+	}
+	return session.OAuthClientID != nil && *session.OAuthClientID == oauthClient.ID
+}
+
+func (s *SessionService) findUser(ctx context.Context, userID string) (*models.User, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	return user, nil
+}
@@ -0,0 +1,230 @@
+package user_management
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+var ErrInvalidSealedKey = errors.New("signing key cannot be unsealed with the key ring secret")
+
+// DatabaseKeyStore keeps the key ring in the signing_keys table. Private
+// keys are sealed with XChaCha20-Poly1305 under the key ring secret, bound
+// to their key ID, so a database dump alone does not reveal them.
+type DatabaseKeyStore struct {
+	repo user_management.SigningKeyRepository
+	aead cipher.AEAD
+}
+
+// NewDatabaseKeyStore returns a store that seals keys under secret, which
+// must be 32 bytes long.
+func NewDatabaseKeyStore(repo user_management.SigningKeyRepository, secret []byte) (*DatabaseKeyStore, error) {
+	aead, err := chacha20poly1305.NewX(secret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key ring secret: %w", err)
+	}
+	return &DatabaseKeyStore{repo: repo, aead: aead}, nil
+}
+
+func (s *DatabaseKeyStore) Load(ctx context.Context) ([]*SigningKey, error) {
+	rows, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*SigningKey, 0, len(rows))
+	for _, row := range rows {
+		seed, err := s.unseal(row.KeyID, row.SealedPrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %s: %w", row.KeyID, err)
+		}
+		keys = append(keys, &SigningKey{
+			ID:         row.KeyID,
+			PrivateKey: ed25519.NewKeyFromSeed(seed),
+			State:      SigningKeyState(row.State),
+			CreatedAt:  row.CreatedAt,
+			RetireAt:   row.RetireAt,
+		})
+	}
+	return keys, nil
+}
+
+func (s *DatabaseKeyStore) Save(ctx context.Context, added *SigningKey, changed []*SigningKey) error {
+	var addedRow *models.SigningKey
+	if added != nil {
+		sealed, err := s.seal(added.ID, added.PrivateKey.Seed())
+		if err != nil {
+			return err
+		}
+		addedRow = &models.SigningKey{
+			BaseModel:        models.BaseModel{CreatedAt: added.CreatedAt},
+			KeyID:            added.ID,
+			SealedPrivateKey: sealed,
+			State:            string(added.State),
+			RetireAt:         added.RetireAt,
+		}
+	}
+
+	changedRows := make([]*models.SigningKey, 0, len(changed))
+	for _, key := range changed {
+		changedRows = append(changedRows, &models.SigningKey{
+			KeyID:    key.ID,
+			State:    string(key.State),
+			RetireAt: key.RetireAt,
+		})
+	}
+
+	return s.repo.Save(ctx, addedRow, changedRows)
+}
+
+func (s *DatabaseKeyStore) seal(keyID string, seed []byte) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(seed)+s.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(s.aead.Seal(nonce, nonce, seed, []byte(keyID))), nil
+}
+
+func (s *DatabaseKeyStore) unseal(keyID, sealed string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(raw) < s.aead.NonceSize() {
+		return nil, ErrInvalidSealedKey
+	}
+
+	nonce, ciphertext := raw[:s.aead.NonceSize()], raw[s.aead.NonceSize():]
+	seed, err := s.aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, ErrInvalidSealedKey
+	}
+	return seed, nil
+}
+
+// FileKeyStore keeps the key ring in a JSON file, for deployments that
+// manage keys outside the database, e.g. as a mounted secret. The file holds
+// hex-encoded Ed25519 seeds and must only be readable by the server.
+type FileKeyStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+type keyRingFile struct {
+	Keys []keyRingFileKey `json:"keys"`
+}
+
+type keyRingFileKey struct {
+	PrivateKey string          `json:"private_key"`
+	State      SigningKeyState `json:"state"`
+	CreatedAt  time.Time       `json:"created_at"`
+	RetireAt   *time.Time      `json:"retire_at,omitempty"`
+}
+
+func NewFileKeyStore(path string) *FileKeyStore {
+	return &FileKeyStore{path: path}
+}
+
+func (s *FileKeyStore) Load(ctx context.Context) ([]*SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+func (s *FileKeyStore) Save(ctx context.Context, added *SigningKey, changed []*SigningKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	for _, update := range changed {
+		if key := findKey(keys, update.ID); key != nil {
+			key.State = update.State
+			key.RetireAt = update.RetireAt
+		}
+	}
+	if added != nil {
+		keys = append(keys, added)
+	}
+
+	file := keyRingFile{Keys: make([]keyRingFileKey, 0, len(keys))}
+	for _, key := range keys {
+		file.Keys = append(file.Keys, keyRingFileKey{
+			PrivateKey: hex.EncodeToString(key.PrivateKey.Seed()),
+			State:      key.State,
+			CreatedAt:  key.CreatedAt,
+			RetireAt:   key.RetireAt,
+		})
+	}
+
+	encoded, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// The ring is written to a temporary file and renamed over the old one,
+	// so readers never see a partially written ring.
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(encoded); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *FileKeyStore) load() ([]*SigningKey, error) {
+	encoded, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file keyRingFile
+	if err := json.Unmarshal(encoded, &file); err != nil {
+		return nil, fmt.Errorf("invalid key ring file %s: %w", s.path, err)
+	}
+
+	keys := make([]*SigningKey, 0, len(file.Keys))
+	for i, entry := range file.Keys {
+		seed, err := hex.DecodeString(entry.PrivateKey)
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid key ring file %s: key %d is not a hex-encoded Ed25519 seed", s.path, i)
+		}
+
+		key := NewSigningKey(ed25519.NewKeyFromSeed(seed))
+		key.State = entry.State
+		key.CreatedAt = entry.CreatedAt
+		key.RetireAt = entry.RetireAt
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
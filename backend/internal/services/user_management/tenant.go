@@ -0,0 +1,200 @@
+package user_management
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+)
+
+var (
+	ErrTenantNotFound     = errors.New("tenant not found")
+	ErrTenantInactive     = errors.New("tenant is inactive")
+	ErrTenantDomainTaken  = errors.New("tenant domain is already in use")
+	ErrCannotDeleteTenant = errors.New("cannot delete the tenant you belong to")
+)
+
+// TenantInput holds the editable tenant fields. Nil fields are left
+// unchanged on update.
+type TenantInput struct {
+	Name     *string
+	Domain   *string
+	IsActive *bool
+}
+
+type TenantService struct {
+	tenantRepo    user_management.TenantRepository
+	auditService  *AuditService
+	defaultDomain string
+}
+
+func NewTenantService(tenantRepo user_management.TenantRepository, auditService *AuditService, defaultDomain string) *TenantService {
+	return &TenantService{
+		tenantRepo:    tenantRepo,
+		auditService:  auditService,
+		defaultDomain: normalizeDomain(defaultDomain),
+	}
+}
+
+// ResolveByHost returns the tenant whose domain matches host. Hosts that do
+// not belong to any tenant fall back to the configured default domain.
+func (s *TenantService) ResolveByHost(ctx context.Context, host string) (*models.Tenant, error) {
+	tenant, err := s.tenantRepo.FindByDomain(ctx, normalizeDomain(host))
+	if err != nil {
+		if s.defaultDomain == "" {
+			return nil, ErrTenantNotFound
+		}
+		if tenant, err = s.tenantRepo.FindByDomain(ctx, s.defaultDomain); err != nil {
+			return nil, ErrTenantNotFound
+		}
+	}
+	return checkTenantActive(tenant)
+}
+
+// ResolveByIdentifier returns the tenant named explicitly by a client,
+// either by ID or by domain. There is no fallback.
+func (s *TenantService) ResolveByIdentifier(ctx context.Context, identifier string) (*models.Tenant, error) {
+	var tenant *models.Tenant
+	var err error
+	if id, parseErr := uuid.Parse(identifier); parseErr == nil {
+		tenant, err = s.tenantRepo.FindByID(ctx, id)
+	} else {
+		tenant, err = s.tenantRepo.FindByDomain(ctx, normalizeDomain(identifier))
+	}
+	if err != nil {
+		return nil, ErrTenantNotFound
+	}
+	return checkTenantActive(tenant)
+}
+
+func (s *TenantService) ListTenants(ctx context.Context) ([]*models.Tenant, error) {
+	return s.tenantRepo.FindAll(ctx)
+}
+
+func (s *TenantService) GetTenant(ctx context.Context, tenantID string) (*models.Tenant, error) {
+	id, err := uuid.Parse(tenantID)
+	if err != nil {
+		return nil, ErrTenantNotFound
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, ErrTenantNotFound
+	}
+	return tenant, nil
+}
+
+func (s *TenantService) CreateTenant(ctx context.Context, actor *models.User, input TenantInput, client ClientInfo) (*models.Tenant, error) {
+	tenant := &models.Tenant{
+		IsActive:         true,
+		AuthPolicyConfig: "{}",
+		BrandingConfig:   "{}",
+	}
+	if err := s.applyTenantInput(ctx, tenant, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.tenantRepo.Create(ctx, tenant); err != nil {
+		return nil, err
+	}
+
+	s.recordTenantAction(ctx, actor, AuditActionTenantCreate, tenant, map[string]interface{}{
+		"name":   tenant.Name,
+		"domain": tenant.Domain,
+	}, client)
+
+	return tenant, nil
+}
+
+func (s *TenantService) UpdateTenant(ctx context.Context, actor *models.User, tenantID string, input TenantInput, client ClientInfo) (*models.Tenant, error) {
+	tenant, err := s.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyTenantInput(ctx, tenant, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.tenantRepo.Update(ctx, tenant); err != nil {
+		return nil, err
+	}
+
+	s.recordTenantAction(ctx, actor, AuditActionTenantUpdate, tenant, map[string]interface{}{
+		"name":      tenant.Name,
+		"domain":    tenant.Domain,
+		"is_active": tenant.IsActive,
+	}, client)
+
+	return tenant, nil
+}
+
+func (s *TenantService) DeleteTenant(ctx context.Context, actor *models.User, tenantID string, client ClientInfo) error {
+	tenant, err := s.GetTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if tenant.ID == actor.TenantID {
+		return ErrCannotDeleteTenant
+	}
+
+	if err := s.tenantRepo.Delete(ctx, tenant.ID); err != nil {
+		return err
+	}
+
+	s.recordTenantAction(ctx, actor, AuditActionTenantDelete, tenant, map[string]interface{}{
+		"domain": tenant.Domain,
+	}, client)
+
+	return nil
+}
+
+func (s *TenantService) applyTenantInput(ctx context.Context, tenant *models.Tenant, input TenantInput) error {
+	if input.Name != nil {
+		tenant.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Domain != nil {
+		domain := normalizeDomain(*input.Domain)
+		if existing, err := s.tenantRepo.FindByDomain(ctx, domain); err == nil && existing.ID != tenant.ID {
+			return ErrTenantDomainTaken
+		}
+		tenant.Domain = domain
+	}
+	if input.IsActive != nil {
+		tenant.IsActive = *input.IsActive
+	}
+	return nil
+}
+
+func (s *TenantService) recordTenantAction(ctx context.Context, actor *models.User, action string, tenant *models.Tenant, details map[string]interface{}, client ClientInfo) {
+	s.auditService.Record(ctx, AuditEntry{
+		ActorID:    actor.ID,
+		Action:     action,
+		Resource:   AuditResourceTenant,
+		ResourceID: tenant.ID.String(),
+		Details:    details,
+		Client:     client,
+	})
+}
+
+func checkTenantActive(tenant *models.Tenant) (*models.Tenant, error) {
+	if !tenant.IsActive {
+		return nil, ErrTenantInactive
+	}
+	return tenant, nil
+}
+
+// normalizeDomain lower-cases a host name and strips any port.
+func normalizeDomain(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
@@ -0,0 +1,42 @@
+package user_management
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// opaqueTokenSize is the number of random bytes in refresh and password
+// reset tokens.
+const opaqueTokenSize = 32
+
+// TokenHasher derives the keyed hashes under which refresh and password
+// reset tokens are stored. Only the client ever holds the token itself, so
+// rows read from the database cannot be presented as tokens, and without
+// the key they cannot be matched against guesses either.
+type TokenHasher struct {
+	key []byte
+}
+
+func NewTokenHasher(key []byte) *TokenHasher {
+	return &TokenHasher{key: key}
+}
+
+// NewToken returns a random opaque token and its hash.
+func (h *TokenHasher) NewToken() (string, string, error) {
+	raw, err := generateRandomBytes(opaqueTokenSize)
+	if err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return token, h.Hash(token), nil
+}
+
+// Hash returns the HMAC-SHA256 of token, hex encoded.
+func (h *TokenHasher) Hash(token string) string {
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
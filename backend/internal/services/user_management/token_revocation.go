@@ -0,0 +1,183 @@
+package user_management
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// revocationSweepInterval is how often the in-memory store drops expired
+// entries.
+const revocationSweepInterval = time.Minute
+
+// RevocationStore keeps access token revocations until the tokens they
+// cover have expired. Each entry maps a key to a time, and a token matching
+// the key is revoked if it was issued at or before that time.
+type RevocationStore interface {
+	// Put records that tokens matching key issued at or before revokedAt
+	// are revoked. The entry may be dropped after expiresAt.
+	Put(ctx context.Context, key string, revokedAt, expiresAt time.Time) error
+	// Get returns the revocation time of each key, or the zero time for
+	// keys without an entry.
+	Get(ctx context.Context, keys []string) ([]time.Time, error)
+}
+
+// TokenRevocationService revokes access tokens before they expire, either
+// one token by its jti or every token of a user or session issued up to
+// now. Access tokens are checked against it on every request.
+type TokenRevocationService struct {
+	store RevocationStore
+}
+
+func NewTokenRevocationService(store RevocationStore) *TokenRevocationService {
+	return &TokenRevocationService{store: store}
+}
+
+// RevokeToken revokes the access token claims were read from.
+func (s *TokenRevocationService) RevokeToken(ctx context.Context, claims *AccessClaims) error {
+	return s.store.Put(ctx, revocationKey("jti", claims.Jti), claims.Expiration, claims.Expiration)
+}
+
+// RevokeUser revokes every access token issued to the user so far.
+func (s *TokenRevocationService) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	return s.revokeIssuedUntilNow(ctx, revocationKey("user", userID.String()))
+}
+
+// RevokeSession revokes every access token issued in the session so far.
+func (s *TokenRevocationService) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	return s.revokeIssuedUntilNow(ctx, revocationKey("sid", sessionID.String()))
+}
+
+// ExemptIssued exempts the access token claims are about to be signed with
+// from the revocations of its user and session made so far. Token issue
+// times only have second precision, so a token issued in the same second
+// as a revocation counts as revoked unless exempted by its jti; otherwise
+// signing in again right after signing out everywhere would yield a token
+// that is rejected at once.
+func (s *TokenRevocationService) ExemptIssued(ctx context.Context, claims *AccessClaims) error {
+	revokedAt, err := s.store.Get(ctx, subjectRevocationKeys(claims))
+	if err != nil {
+		return err
+	}
+
+	var latest time.Time
+	for _, at := range revokedAt {
+		if at.After(latest) {
+			latest = at
+		}
+	}
+	if latest.IsZero() || latest.Truncate(time.Second).Before(claims.IssuedAt.Truncate(time.Second)) {
+		return nil
+	}
+	return s.store.Put(ctx, revocationKey("exempt", claims.Jti), latest, claims.Expiration)
+}
+
+// IsRevoked reports whether the access token claims were read from has been
+// revoked by its jti, its user or its session. Issue and revocation times
+// are compared to the second, so tokens issued in the second of a
+// revocation count as revoked unless ExemptIssued exempted them from it.
+func (s *TokenRevocationService) IsRevoked(ctx context.Context, claims *AccessClaims) (bool, error) {
+	keys := append([]string{
+		revocationKey("jti", claims.Jti),
+		revocationKey("exempt", claims.Jti),
+	}, subjectRevocationKeys(claims)...)
+
+	revokedAt, err := s.store.Get(ctx, keys)
+	if err != nil {
+		return false, err
+	}
+
+	if !revokedAt[0].IsZero() {
+		return true, nil
+	}
+	exemptUntil := revokedAt[1]
+	issuedAt := claims.IssuedAt.Truncate(time.Second)
+	for _, at := range revokedAt[2:] {
+		if at.IsZero() || at.Truncate(time.Second).Before(issuedAt) {
+			continue
+		}
+		if exemptUntil.IsZero() || at.After(exemptUntil) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// subjectRevocationKeys returns the keys of the revocations of the user
+// and session of claims.
+func subjectRevocationKeys(claims *AccessClaims) []string {
+	keys := []string{revocationKey("user", claims.UserID.String())}
+	if claims.SessionID != uuid.Nil {
+		keys = append(keys, revocationKey("sid", claims.SessionID.String()))
+	}
+	return keys
+}
+
+// revokeIssuedUntilNow revokes the tokens matching key issued so far. The
+// entry is kept for as long as any tenant's access tokens can live.
+func (s *TokenRevocationService) revokeIssuedUntilNow(ctx context.Context, key string) error {
+	now := time.Now()
+	return s.store.Put(ctx, key, now, now.Add(maxAccessTokenSeconds*time.Second))
+}
+
+func revocationKey(kind, id string) string {
+	return kind + ":" + id
+}
+
+type revocationEntry struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+// MemoryRevocationStore is a RevocationStore local to the process. It is
+// only suitable for single-instance deployments, since revocations made by
+// one instance are not seen by the others.
+type MemoryRevocationStore struct {
+	mu        sync.Mutex
+	entries   map[string]revocationEntry
+	lastSweep time.Time
+}
+
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		entries:   make(map[string]revocationEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryRevocationStore) Put(ctx context.Context, key string, revokedAt, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= revocationSweepInterval {
+		for k, entry := range s.entries {
+			if entry.expiresAt.Before(now) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if existing, ok := s.entries[key]; ok && existing.revokedAt.After(revokedAt) {
+		revokedAt = existing.revokedAt
+	}
+	s.entries[key] = revocationEntry{revokedAt: revokedAt, expiresAt: expiresAt}
+	return nil
+}
+
+func (s *MemoryRevocationStore) Get(ctx context.Context, keys []string) ([]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	revokedAt := make([]time.Time, len(keys))
+	for i, key := range keys {
+		if entry, ok := s.entries[key]; ok && entry.expiresAt.After(now) {
+			revokedAt[i] = entry.revokedAt
+		}
+	}
+	return revokedAt, nil
+}
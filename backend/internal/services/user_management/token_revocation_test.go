@@ -0,0 +1,114 @@
+package user_management
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/o1egl/paseto"
+)
+
+func newAccessClaims(issuedAt time.Time) *AccessClaims {
+	return &AccessClaims{
+		JSONToken: paseto.JSONToken{
+			Jti:        uuid.NewString(),
+			IssuedAt:   issuedAt,
+			Expiration: issuedAt.Add(time.Hour),
+		},
+		UserID:    uuid.New(),
+		SessionID: uuid.New(),
+	}
+}
+
+func TestRevocationCoversTokensIssuedInTheSameSecond(t *testing.T) {
+	ctx := context.Background()
+	revocations := NewTokenRevocationService(NewMemoryRevocationStore())
+
+	claims := newAccessClaims(time.Now().Truncate(time.Second))
+	if err := revocations.RevokeUser(ctx, claims.UserID); err != nil {
+		t.Fatalf("failed to revoke user: %v", err)
+	}
+	if revoked, err := revocations.IsRevoked(ctx, claims); err != nil || !revoked {
+		t.Fatalf("token issued in the second of the revocation was not revoked (err %v)", err)
+	}
+
+	later := *claims
+	later.IssuedAt = claims.IssuedAt.Add(2 * time.Second)
+	if revoked, err := revocations.IsRevoked(ctx, &later); err != nil || revoked {
+		t.Fatalf("token issued after the revocation was revoked (err %v)", err)
+	}
+}
+
+func TestExemptIssuedOnlyCoversEarlierRevocations(t *testing.T) {
+	ctx := context.Background()
+	revocations := NewTokenRevocationService(NewMemoryRevocationStore())
+
+	claims := newAccessClaims(time.Now())
+	if err := revocations.RevokeSession(ctx, claims.SessionID); err != nil {
+		t.Fatalf("failed to revoke session: %v", err)
+	}
+	issued := newAccessClaims(time.Now())
+	issued.UserID, issued.SessionID = claims.UserID, claims.SessionID
+	if err := revocations.ExemptIssued(ctx, issued); err != nil {
+		t.Fatalf("failed to exempt token: %v", err)
+	}
+
+	if revoked, err := revocations.IsRevoked(ctx, issued); err != nil || revoked {
+		t.Fatalf("token issued after the revocation was revoked (err %v)", err)
+	}
+	if revoked, err := revocations.IsRevoked(ctx, claims); err != nil || !revoked {
+		t.Fatalf("exempting one token exempted another (err %v)", err)
+	}
+
+	time.Sleep(time.Millisecond)
+	if err := revocations.RevokeUser(ctx, issued.UserID); err != nil {
+		t.Fatalf("failed to revoke user: %v", err)
+	}
+	if revoked, err := revocations.IsRevoked(ctx, issued); err != nil || !revoked {
+		t.Fatalf("exempted token survived a later revocation (err %v)", err)
+	}
+}
+
+func TestRevokeTokenRevokesOnlyThatToken(t *testing.T) {
+	ctx := context.Background()
+	revocations := NewTokenRevocationService(NewMemoryRevocationStore())
+
+	claims := newAccessClaims(time.Now())
+	other := newAccessClaims(time.Now())
+	other.UserID, other.SessionID = claims.UserID, claims.SessionID
+	if err := revocations.RevokeToken(ctx, claims); err != nil {
+		t.Fatalf("failed to revoke token: %v", err)
+	}
+
+	if revoked, err := revocations.IsRevoked(ctx, claims); err != nil || !revoked {
+		t.Fatalf("revoked token was accepted (err %v)", err)
+	}
+	if revoked, err := revocations.IsRevoked(ctx, other); err != nil || revoked {
+		t.Fatalf("another token of the session was revoked (err %v)", err)
+	}
+}
+
+func TestAccessTokensAreCheckedAgainstRevocations(t *testing.T) {
+	st := newServiceTest(t)
+	user := st.createUser(t, "lee@acme.test", "Secret-pass-1")
+
+	accessToken, _, err := st.authService.GenerateTokens(st.ctx, user, AuthMethodPassword, ClientInfo{})
+	if err != nil {
+		t.Fatalf("failed to generate tokens: %v", err)
+	}
+	if err := st.revocations.RevokeUser(st.ctx, user.ID); err != nil {
+		t.Fatalf("failed to revoke user: %v", err)
+	}
+	if _, err := st.authService.ValidateToken(st.ctx, accessToken); err != ErrInvalidAccessToken {
+		t.Fatalf("token issued before the revocation returned %v, want ErrInvalidAccessToken", err)
+	}
+
+	accessToken, _, err = st.authService.GenerateTokens(st.ctx, user, AuthMethodPassword, ClientInfo{})
+	if err != nil {
+		t.Fatalf("failed to generate tokens: %v", err)
+	}
+	if _, err := st.authService.ValidateToken(st.ctx, accessToken); err != nil {
+		t.Fatalf("token issued right after the revocation was rejected: %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package user_management
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/o1egl/paseto"
+	"golang.org/x/crypto/blake2b"
+)
+
+// PASERK headers of v2 public keys and of their key IDs.
+const (
+	paserkPublicHeader = "k2.public."
+	paserkPIDHeader    = "k2.pid."
+)
+
+var ErrUnknownSigningKey = errors.New("token is not signed by a known key")
+
+// tokenFooter is the footer of every token AdminSuite signs. It names the
+// key that verifies the token.
+type tokenFooter struct {
+	KeyID string `json:"kid"`
+}
+
+// SigningKey is an Ed25519 key of the key ring that signs v2.public tokens.
+// ID is the PASERK ID of its public key, which tokens carry as their kid.
+// RetireAt is when a verify-only key stops verifying tokens.
+type SigningKey struct {
+	ID         string
+	PrivateKey ed25519.PrivateKey
+	State      SigningKeyState
+	CreatedAt  time.Time
+	RetireAt   *time.Time
+}
+
+// NewSigningKey returns privateKey as a new active key.
+func NewSigningKey(privateKey ed25519.PrivateKey) *SigningKey {
+	return &SigningKey{
+		ID:         PublicKeyID(privateKey.Public().(ed25519.PublicKey)),
+		PrivateKey: privateKey,
+		State:      SigningKeyStateActive,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// GenerateSigningKey returns a new random active key.
+func GenerateSigningKey() (*SigningKey, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewSigningKey(privateKey), nil
+}
+
+// PublicKey returns the public half of the key.
+func (k *SigningKey) PublicKey() PublicKey {
+	return PublicKey{ID: k.ID, Key: k.PrivateKey.Public().(ed25519.PublicKey)}
+}
+
+// Verifies reports whether the key verifies tokens at the given time.
+func (k *SigningKey) Verifies(at time.Time) bool {
+	switch k.State {
+	case SigningKeyStateActive:
+		return true
+	case SigningKeyStateVerifyOnly:
+		return k.RetireAt == nil || at.Before(*k.RetireAt)
+	default:
+		return false
+	}
+}
+
+// PublicKey is a key that verifies AdminSuite tokens, as published in the
+// key set.
+type PublicKey struct {
+	ID  string
+	Key ed25519.PublicKey
+}
+
+// PASERK returns the key in PASERK k2.public form.
+func (k PublicKey) PASERK() string {
+	return paserkPublicKey(k.Key)
+}
+
+// PublicKeyID returns the PASERK ID (k2.pid) of an Ed25519 public key.
+func PublicKeyID(publicKey ed25519.PublicKey) string {
+	hash, _ := blake2b.New(33, nil)
+	hash.Write([]byte(paserkPIDHeader))
+	hash.Write([]byte(paserkPublicKey(publicKey)))
+	return paserkPIDHeader + base64.RawURLEncoding.EncodeToString(hash.Sum(nil))
+}
+
+func paserkPublicKey(publicKey ed25519.PublicKey) string {
+	return paserkPublicHeader + base64.RawURLEncoding.EncodeToString(publicKey)
+}
+
+// TokenSigner signs tokens as v2.public PASETOs with a kid footer and
+// verifies them by the key the footer names, so services that fetch the
+// public key set can verify tokens offline. Keys come from a key ring, so
+// tokens signed before a rotation keep verifying during its grace period.
+type TokenSigner struct {
+	paseto paseto.V2
+	ring   *KeyRing
+}
+
+func NewTokenSigner(ring *KeyRing) *TokenSigner {
+	return &TokenSigner{
+		paseto: paseto.V2{},
+		ring:   ring,
+	}
+}
+
+// Sign signs payload with the active key of the ring.
+func (s *TokenSigner) Sign(payload interface{}) (string, error) {
+	key := s.ring.Active()
+	return s.paseto.Sign(key.PrivateKey, payload, tokenFooter{KeyID: key.ID})
+}
+
+// Verify checks the signature of token against the key named in its footer
+// and decodes its payload into payload. Claims are not validated.
+func (s *TokenSigner) Verify(token string, payload interface{}) error {
+	var footer tokenFooter
+	if err := paseto.ParseFooter(token, &footer); err != nil {
+		return err
+	}
+
+	key, ok := s.ring.Lookup(footer.KeyID)
+	if !ok {
+		return ErrUnknownSigningKey
+	}
+
+	return s.paseto.Verify(token, key.PublicKey().Key, payload, nil)
+}
+
+// PublicKeys returns the keys tokens signed by s can currently be verified
+// with: the active keys and the verify-only keys still in their grace period.
+func (s *TokenSigner) PublicKeys() []PublicKey {
+	keys := s.ring.VerifyingKeys()
+	publicKeys := make([]PublicKey, 0, len(keys))
+	for _, key := range keys {
+		publicKeys = append(publicKeys, key.PublicKey())
+	}
+	return publicKeys
+}
@@ -0,0 +1,454 @@
+package user_management
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Namespaces and algorithms of XML Signature. Only enveloped RSA-SHA256
+// signatures over exclusively canonicalized content are accepted.
+const (
+	xmlNamespace          = "http://www.w3.org/XML/1998/namespace"
+	xmlDSigNamespace      = "http://www.w3.org/2000/09/xmldsig#"
+	xmlExcC14NNamespace   = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	xmlAlgorithmExcC14N   = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	xmlAlgorithmEnveloped = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	xmlAlgorithmSHA256    = "http://www.w3.org/2001/04/xmlenc#sha256"
+	xmlAlgorithmRSASHA256 = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+)
+
+var (
+	ErrInvalidXML          = errors.New("document is not well-formed XML")
+	ErrInvalidXMLSignature = errors.New("XML signature is missing or invalid")
+)
+
+// xmlNode is an element of a parsed XML document. Names are kept as
+// written, with their prefix; namespaces are resolved on demand against
+// the declarations of the element and its ancestors, as canonicalization
+// needs both.
+type xmlNode struct {
+	Prefix     string
+	Local      string
+	Namespaces map[string]string
+	Attrs      []xmlAttr
+	Content    []xmlContent
+	Parent     *xmlNode
+}
+
+type xmlAttr struct {
+	Prefix string
+	Local  string
+	Value  string
+}
+
+// xmlContent is a child of an element: either an element or text.
+type xmlContent struct {
+	Element *xmlNode
+	Text    string
+}
+
+// parseXML parses the document in data and returns its root element.
+// Documents with a DTD are rejected, so entity expansion never happens;
+// comments and processing instructions are dropped, as exclusive
+// canonicalization without comments drops them too. IDs must be unique,
+// so a signature reference names exactly one element.
+func parseXML(data []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var root, current *xmlNode
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidXML
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			if current == nil && root != nil {
+				return nil, ErrInvalidXML
+			}
+			node := &xmlNode{
+				Prefix:     token.Name.Space,
+				Local:      token.Name.Local,
+				Namespaces: map[string]string{},
+				Parent:     current,
+			}
+			for _, attr := range token.Attr {
+				switch {
+				case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+					node.Namespaces[""] = attr.Value
+				case attr.Name.Space == "xmlns":
+					node.Namespaces[attr.Name.Local] = attr.Value
+				default:
+					node.Attrs = append(node.Attrs, xmlAttr{Prefix: attr.Name.Space, Local: attr.Name.Local, Value: attr.Value})
+				}
+			}
+			if current == nil {
+				root = node
+			} else {
+				current.Content = append(current.Content, xmlContent{Element: node})
+			}
+			current = node
+		case xml.EndElement:
+			if current == nil || token.Name.Space != current.Prefix || token.Name.Local != current.Local {
+				return nil, ErrInvalidXML
+			}
+			current = current.Parent
+		case xml.CharData:
+			if current != nil {
+				current.Content = append(current.Content, xmlContent{Text: string(token)})
+			} else if len(bytes.TrimSpace(token)) != 0 {
+				return nil, ErrInvalidXML
+			}
+		case xml.Directive:
+			return nil, ErrInvalidXML
+		}
+	}
+	if root == nil || current != nil {
+		return nil, ErrInvalidXML
+	}
+
+	if err := root.checkNames(map[string]bool{}); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// checkNames checks that all prefixes are declared and that no two
+// elements have the same ID.
+func (n *xmlNode) checkNames(ids map[string]bool) error {
+	if _, ok := n.namespaceURI(n.Prefix); !ok {
+		return ErrInvalidXML
+	}
+	for _, attr := range n.Attrs {
+		if _, ok := n.namespaceURI(attr.Prefix); !ok && attr.Prefix != "" {
+			return ErrInvalidXML
+		}
+		if attr.Prefix == "" && attr.Local == "ID" {
+			if ids[attr.Value] {
+				return ErrInvalidXML
+			}
+			ids[attr.Value] = true
+		}
+	}
+	for _, child := range n.children() {
+		if err := child.checkNames(ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// namespaceURI resolves prefix in the scope of n. The empty prefix is the
+// default namespace, which is "" if none is declared.
+func (n *xmlNode) namespaceURI(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return xmlNamespace, true
+	}
+	for node := n; node != nil; node = node.Parent {
+		if uri, ok := node.Namespaces[prefix]; ok {
+			return uri, true
+		}
+	}
+	return "", prefix == ""
+}
+
+// is reports whether n is the element local in namespace.
+func (n *xmlNode) is(namespace, local string) bool {
+	uri, _ := n.namespaceURI(n.Prefix)
+	return n.Local == local && uri == namespace
+}
+
+func (n *xmlNode) children() []*xmlNode {
+	var children []*xmlNode
+	for _, content := range n.Content {
+		if content.Element != nil {
+			children = append(children, content.Element)
+		}
+	}
+	return children
+}
+
+// childrenNamed returns the child elements local in namespace.
+func (n *xmlNode) childrenNamed(namespace, local string) []*xmlNode {
+	var children []*xmlNode
+	for _, child := range n.children() {
+		if child.is(namespace, local) {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// child returns the only child element local in namespace, or nil if
+// there is none or more than one.
+func (n *xmlNode) child(namespace, local string) *xmlNode {
+	children := n.childrenNamed(namespace, local)
+	if len(children) != 1 {
+		return nil
+	}
+	return children[0]
+}
+
+// attr returns the value of the unqualified attribute local.
+func (n *xmlNode) attr(local string) string {
+	for _, attr := range n.Attrs {
+		if attr.Prefix == "" && attr.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// text returns the text directly inside n. Text split by comments is
+// joined, as canonicalization joins it.
+func (n *xmlNode) text() string {
+	var text strings.Builder
+	for _, content := range n.Content {
+		if content.Element == nil {
+			text.WriteString(content.Text)
+		}
+	}
+	return strings.TrimSpace(text.String())
+}
+
+// canonicalize writes the exclusive XML canonicalization without comments
+// of n to buf, leaving out the element exclude. Namespace declarations are
+// only rendered where they are used, plus those of inclusivePrefixes;
+// rendered holds the declarations already in effect in the output.
+func (n *xmlNode) canonicalize(buf *bytes.Buffer, rendered map[string]string, inclusivePrefixes []string, exclude *xmlNode) {
+	used := map[string]bool{}
+	if n.Prefix != "xml" {
+		used[n.Prefix] = true
+	}
+	for _, attr := range n.Attrs {
+		if attr.Prefix != "" && attr.Prefix != "xml" {
+			used[attr.Prefix] = true
+		}
+	}
+	for _, prefix := range inclusivePrefixes {
+		if _, ok := n.namespaceURI(prefix); ok {
+			used[prefix] = true
+		}
+	}
+
+	prefixes := make([]string, 0, len(used))
+	for prefix := range used {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	scope := make(map[string]string, len(rendered)+len(prefixes))
+	for prefix, uri := range rendered {
+		scope[prefix] = uri
+	}
+
+	buf.WriteByte('<')
+	buf.WriteString(qualifiedName(n.Prefix, n.Local))
+	for _, prefix := range prefixes {
+		uri, _ := n.namespaceURI(prefix)
+		if current, ok := rendered[prefix]; ok && current == uri || !ok && prefix == "" && uri == "" {
+			continue
+		}
+		scope[prefix] = uri
+		buf.WriteString(" xmlns")
+		if prefix != "" {
+			buf.WriteString(":" + prefix)
+		}
+		buf.WriteString(`="`)
+		escapeAttributeValue(buf, uri)
+		buf.WriteByte('"')
+	}
+
+	attrs := make([]xmlAttr, len(n.Attrs))
+	copy(attrs, n.Attrs)
+	attrURI := func(attr xmlAttr) string {
+		if attr.Prefix == "" {
+			return ""
+		}
+		uri, _ := n.namespaceURI(attr.Prefix)
+		return uri
+	}
+	sort.SliceStable(attrs, func(i, j int) bool {
+		iURI, jURI := attrURI(attrs[i]), attrURI(attrs[j])
+		if iURI != jURI {
+			return iURI < jURI
+		}
+		return attrs[i].Local < attrs[j].Local
+	})
+	for _, attr := range attrs {
+		buf.WriteString(" ")
+		buf.WriteString(qualifiedName(attr.Prefix, attr.Local))
+		buf.WriteString(`="`)
+		escapeAttributeValue(buf, attr.Value)
+		buf.WriteByte('"')
+	}
+	buf.WriteByte('>')
+
+	for _, content := range n.Content {
+		switch {
+		case content.Element == nil:
+			escapeText(buf, content.Text)
+		case content.Element != exclude:
+			content.Element.canonicalize(buf, scope, inclusivePrefixes, exclude)
+		}
+	}
+
+	buf.WriteString("</")
+	buf.WriteString(qualifiedName(n.Prefix, n.Local))
+	buf.WriteByte('>')
+}
+
+func qualifiedName(prefix, local string) string {
+	if prefix == "" {
+		return local
+	}
+	return prefix + ":" + local
+}
+
+func escapeAttributeValue(buf *bytes.Buffer, value string) {
+	for _, r := range value {
+		switch r {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '"':
+			buf.WriteString("&quot;")
+		case '\t':
+			buf.WriteString("&#x9;")
+		case '\n':
+			buf.WriteString("&#xA;")
+		case '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+}
+
+func escapeText(buf *bytes.Buffer, text string) {
+	for _, r := range text {
+		switch r {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '>':
+			buf.WriteString("&gt;")
+		case '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+}
+
+// verifyEnvelopedSignature checks that element carries a signature over
+// itself, made with the key of one of certificates. The signature must be
+// a direct child of element with a single reference to element's ID, so
+// it cannot vouch for content elsewhere in the document.
+func verifyEnvelopedSignature(element *xmlNode, certificates []*x509.Certificate) error {
+	signature := element.child(xmlDSigNamespace, "Signature")
+	if signature == nil {
+		return ErrInvalidXMLSignature
+	}
+	signedInfo := signature.child(xmlDSigNamespace, "SignedInfo")
+	signatureValue := signature.child(xmlDSigNamespace, "SignatureValue")
+	if signedInfo == nil || signatureValue == nil {
+		return ErrInvalidXMLSignature
+	}
+
+	c14nMethod := signedInfo.child(xmlDSigNamespace, "CanonicalizationMethod")
+	signatureMethod := signedInfo.child(xmlDSigNamespace, "SignatureMethod")
+	reference := signedInfo.child(xmlDSigNamespace, "Reference")
+	if c14nMethod == nil || c14nMethod.attr("Algorithm") != xmlAlgorithmExcC14N ||
+		signatureMethod == nil || signatureMethod.attr("Algorithm") != xmlAlgorithmRSASHA256 ||
+		reference == nil {
+		return ErrInvalidXMLSignature
+	}
+
+	id := element.attr("ID")
+	if id == "" || reference.attr("URI") != "#"+id {
+		return ErrInvalidXMLSignature
+	}
+
+	transforms := reference.child(xmlDSigNamespace, "Transforms")
+	if transforms == nil {
+		return ErrInvalidXMLSignature
+	}
+	transformList := transforms.childrenNamed(xmlDSigNamespace, "Transform")
+	if len(transformList) != 2 ||
+		transformList[0].attr("Algorithm") != xmlAlgorithmEnveloped ||
+		transformList[1].attr("Algorithm") != xmlAlgorithmExcC14N {
+		return ErrInvalidXMLSignature
+	}
+
+	digestMethod := reference.child(xmlDSigNamespace, "DigestMethod")
+	digestValue := reference.child(xmlDSigNamespace, "DigestValue")
+	if digestMethod == nil || digestMethod.attr("Algorithm") != xmlAlgorithmSHA256 || digestValue == nil {
+		return ErrInvalidXMLSignature
+	}
+
+	var canonical bytes.Buffer
+	signedInfo.canonicalize(&canonical, map[string]string{}, inclusiveNamespacePrefixes(c14nMethod), nil)
+	signed := sha256.Sum256(canonical.Bytes())
+	signatureBytes, err := decodeXMLBase64(signatureValue.text())
+	if err != nil {
+		return ErrInvalidXMLSignature
+	}
+	verified := false
+	for _, certificate := range certificates {
+		publicKey, ok := certificate.PublicKey.(*rsa.PublicKey)
+		if ok && rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, signed[:], signatureBytes) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return ErrInvalidXMLSignature
+	}
+
+	canonical.Reset()
+	element.canonicalize(&canonical, map[string]string{}, inclusiveNamespacePrefixes(transformList[1]), signature)
+	digest := sha256.Sum256(canonical.Bytes())
+	expected, err := decodeXMLBase64(digestValue.text())
+	if err != nil || !bytes.Equal(digest[:], expected) {
+		return ErrInvalidXMLSignature
+	}
+	return nil
+}
+
+// inclusiveNamespacePrefixes returns the prefixes an exclusive
+// canonicalization method or transform renders wherever they are in
+// scope. #default stands for the default namespace.
+func inclusiveNamespacePrefixes(method *xmlNode) []string {
+	inclusive := method.child(xmlExcC14NNamespace, "InclusiveNamespaces")
+	if inclusive == nil {
+		return nil
+	}
+	prefixes := strings.Fields(inclusive.attr("PrefixList"))
+	for i, prefix := range prefixes {
+		if prefix == "#default" {
+			prefixes[i] = ""
+		}
+	}
+	return prefixes
+}
+
+// decodeXMLBase64 decodes base64 content, which may be wrapped over
+// several lines.
+func decodeXMLBase64(value string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+}
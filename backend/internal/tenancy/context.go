@@ -0,0 +1,41 @@
+// Package tenancy carries the current tenant through a request and enforces
+// that database access stays inside it.
+package tenancy
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type contextKey int
+
+const (
+	tenantKey contextKey = iota
+	allTenantsKey
+)
+
+// WithTenant returns a context whose database queries are restricted to the
+// given tenant.
+func WithTenant(ctx context.Context, tenantID uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantKey, tenantID)
+}
+
+// WithAllTenants returns a context whose database queries are not restricted
+// to any tenant. It is the explicit opt-out for super-admin operations and
+// background jobs and must not be derived from untrusted input.
+func WithAllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allTenantsKey, true)
+}
+
+// TenantID returns the tenant carried by ctx, if any.
+func TenantID(ctx context.Context) (uuid.UUID, bool) {
+	tenantID, ok := ctx.Value(tenantKey).(uuid.UUID)
+	return tenantID, ok
+}
+
+// IsAllTenants reports whether ctx opted out of tenant scoping.
+func IsAllTenants(ctx context.Context) bool {
+	allTenants, _ := ctx.Value(allTenantsKey).(bool)
+	return allTenants
+}
@@ -0,0 +1,122 @@
+package tenancy
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	ErrMissingTenant    = errors.New("tenancy: no tenant in context for a tenant-scoped query")
+	ErrCrossTenantWrite = errors.New("tenancy: cannot write a row that belongs to another tenant")
+)
+
+const tenantField = "TenantID"
+
+// Plugin scopes every query, update and delete on a model with a TenantID
+// field to the tenant carried by the statement context, and stamps that
+// tenant on created rows. Statements on such models fail with
+// ErrMissingTenant unless the context names a tenant or opts out with
+// WithAllTenants, so a repository that forgets to pass the request context
+// fails closed instead of reading across tenants.
+type Plugin struct{}
+
+func (Plugin) Name() string {
+	return "tenancy"
+}
+
+func (Plugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+
+	if err := callbacks.Create().Before("gorm:create").Register("tenancy:create", assignTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenancy:query", scopeTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenancy:update", scopeUpdate); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("tenancy:delete", scopeTenant); err != nil {
+		return err
+	}
+	return callbacks.Row().Before("gorm:row").Register("tenancy:row", scopeTenant)
+}
+
+// statementTenant returns the tenant field of the statement's model and the
+// tenant it must be restricted to. ok is false when no scoping applies.
+func statementTenant(db *gorm.DB) (field *schema.Field, tenantID uuid.UUID, ok bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil, uuid.Nil, false
+	}
+
+	field = db.Statement.Schema.LookUpField(tenantField)
+	if field == nil {
+		return nil, uuid.Nil, false
+	}
+
+	ctx := db.Statement.Context
+	if IsAllTenants(ctx) {
+		return nil, uuid.Nil, false
+	}
+
+	tenantID, found := TenantID(ctx)
+	if !found {
+		db.AddError(ErrMissingTenant)
+		return nil, uuid.Nil, false
+	}
+
+	return field, tenantID, true
+}
+
+func scopeTenant(db *gorm.DB) {
+	field, tenantID, ok := statementTenant(db)
+	if !ok {
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+}
+
+// scopeUpdate restricts an update to the current tenant and, for updates of
+// whole rows, makes sure the row is not moved into another tenant.
+func scopeUpdate(db *gorm.DB) {
+	scopeTenant(db)
+	assignTenant(db)
+}
+
+func assignTenant(db *gorm.DB) {
+	field, tenantID, ok := statementTenant(db)
+	if !ok {
+		return
+	}
+
+	switch value := db.Statement.ReflectValue; value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			assignTenantTo(db, field, reflect.Indirect(value.Index(i)), tenantID)
+		}
+	case reflect.Struct:
+		assignTenantTo(db, field, value, tenantID)
+	}
+}
+
+func assignTenantTo(db *gorm.DB, field *schema.Field, row reflect.Value, tenantID uuid.UUID) {
+	ctx := db.Statement.Context
+
+	current, isZero := field.ValueOf(ctx, row)
+	if isZero {
+		db.AddError(field.Set(ctx, row, tenantID))
+		return
+	}
+
+	if current != tenantID {
+		db.AddError(ErrCrossTenantWrite)
+	}
+}
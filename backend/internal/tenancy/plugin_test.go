@@ -0,0 +1,189 @@
+package tenancy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/database/databasetest"
+	"github.com/josy-coder/adminsuite/internal/models"
+	"github.com/josy-coder/adminsuite/internal/repositories/user_management"
+	"github.com/josy-coder/adminsuite/internal/tenancy"
+)
+
+type widget struct {
+	ID       uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TenantID uuid.UUID `gorm:"type:uuid;index"`
+	Name     string
+}
+
+type setting struct {
+	ID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name string
+}
+
+type pluginTest struct {
+	db      *gorm.DB
+	tenantA uuid.UUID
+	tenantB uuid.UUID
+	ctxA    context.Context
+	ctxB    context.Context
+}
+
+func newPluginTest(t *testing.T) *pluginTest {
+	t.Helper()
+
+	db := databasetest.Open(t, &widget{}, &setting{}, &models.User{}, &models.Role{}, &models.Permission{})
+
+	pt := &pluginTest{db: db, tenantA: uuid.New(), tenantB: uuid.New()}
+	pt.ctxA = tenancy.WithTenant(context.Background(), pt.tenantA)
+	pt.ctxB = tenancy.WithTenant(context.Background(), pt.tenantB)
+	return pt
+}
+
+func (pt *pluginTest) create(t *testing.T, ctx context.Context, name string) *widget {
+	t.Helper()
+
+	w := &widget{ID: uuid.New(), Name: name}
+	if err := pt.db.WithContext(ctx).Create(w).Error; err != nil {
+		t.Fatalf("failed to create widget %s: %v", name, err)
+	}
+	return w
+}
+
+func (pt *pluginTest) names(t *testing.T, db *gorm.DB) map[string]bool {
+	t.Helper()
+
+	var widgets []widget
+	if err := db.Find(&widgets).Error; err != nil {
+		t.Fatalf("failed to list widgets: %v", err)
+	}
+	names := make(map[string]bool, len(widgets))
+	for _, w := range widgets {
+		names[w.Name] = true
+	}
+	return names
+}
+
+func TestPluginScopesReadsAndWritesToTenant(t *testing.T) {
+	pt := newPluginTest(t)
+
+	a := pt.create(t, pt.ctxA, "a")
+	b := pt.create(t, pt.ctxB, "b")
+	if a.TenantID != pt.tenantA || b.TenantID != pt.tenantB {
+		t.Fatalf("created rows were not stamped with their tenant: %v, %v", a.TenantID, b.TenantID)
+	}
+
+	if names := pt.names(t, pt.db.WithContext(pt.ctxA)); len(names) != 1 || !names["a"] {
+		t.Fatalf("tenant A sees %v, want only a", names)
+	}
+
+	var count int64
+	if err := pt.db.WithContext(pt.ctxA).Model(&widget{}).Count(&count).Error; err != nil || count != 1 {
+		t.Fatalf("tenant A counts %d widgets (err %v), want 1", count, err)
+	}
+
+	if err := pt.db.WithContext(pt.ctxA).Model(&widget{}).Where("1 = 1").Update("name", "renamed").Error; err != nil {
+		t.Fatalf("failed to update widgets: %v", err)
+	}
+	if err := pt.db.WithContext(pt.ctxA).Where("1 = 1").Delete(&widget{}).Error; err != nil {
+		t.Fatalf("failed to delete widgets: %v", err)
+	}
+
+	if names := pt.names(t, pt.db.WithContext(pt.ctxB)); len(names) != 1 || !names["b"] {
+		t.Fatalf("writes in tenant A changed tenant B's rows, which now are %v", names)
+	}
+	if names := pt.names(t, pt.db.WithContext(pt.ctxA)); len(names) != 0 {
+		t.Fatalf("tenant A still sees %v after deleting its rows", names)
+	}
+}
+
+func TestPluginRejectsWritesIntoAnotherTenant(t *testing.T) {
+	pt := newPluginTest(t)
+
+	w := &widget{ID: uuid.New(), TenantID: pt.tenantB, Name: "b"}
+	if err := pt.db.WithContext(pt.ctxA).Create(w).Error; !errors.Is(err, tenancy.ErrCrossTenantWrite) {
+		t.Fatalf("creating a row of another tenant returned %v, want ErrCrossTenantWrite", err)
+	}
+
+	a := pt.create(t, pt.ctxA, "a")
+	a.TenantID = pt.tenantB
+	if err := pt.db.WithContext(pt.ctxA).Save(a).Error; !errors.Is(err, tenancy.ErrCrossTenantWrite) {
+		t.Fatalf("moving a row into another tenant returned %v, want ErrCrossTenantWrite", err)
+	}
+}
+
+func TestPluginRequiresTenant(t *testing.T) {
+	pt := newPluginTest(t)
+	ctx := context.Background()
+
+	var widgets []widget
+	if err := pt.db.WithContext(ctx).Find(&widgets).Error; !errors.Is(err, tenancy.ErrMissingTenant) {
+		t.Fatalf("query without tenant returned %v, want ErrMissingTenant", err)
+	}
+	if err := pt.db.WithContext(ctx).Create(&widget{ID: uuid.New(), Name: "x"}).Error; !errors.Is(err, tenancy.ErrMissingTenant) {
+		t.Fatalf("create without tenant returned %v, want ErrMissingTenant", err)
+	}
+	if err := pt.db.WithContext(ctx).Model(&widget{}).Where("1 = 1").Update("name", "x").Error; !errors.Is(err, tenancy.ErrMissingTenant) {
+		t.Fatalf("update without tenant returned %v, want ErrMissingTenant", err)
+	}
+	if err := pt.db.WithContext(ctx).Where("1 = 1").Delete(&widget{}).Error; !errors.Is(err, tenancy.ErrMissingTenant) {
+		t.Fatalf("delete without tenant returned %v, want ErrMissingTenant", err)
+	}
+
+	// Models without a tenant are not affected.
+	if err := pt.db.WithContext(ctx).Create(&setting{ID: uuid.New(), Name: "x"}).Error; err != nil {
+		t.Fatalf("failed to create a model without tenant: %v", err)
+	}
+}
+
+func TestPluginHidesRowsOfOtherTenantsByID(t *testing.T) {
+	pt := newPluginTest(t)
+
+	b := pt.create(t, pt.ctxB, "b")
+
+	var found widget
+	if err := pt.db.WithContext(pt.ctxA).First(&found, "id = ?", b.ID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("loading another tenant's row by ID returned %v, want ErrRecordNotFound", err)
+	}
+	if err := pt.db.WithContext(pt.ctxB).First(&found, "id = ?", b.ID).Error; err != nil {
+		t.Fatalf("failed to load own row by ID: %v", err)
+	}
+
+	users := user_management.NewUserRepository(pt.db)
+	user := &models.User{Email: "b@example.com", Username: "b"}
+	if err := users.Create(pt.ctxB, user); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if _, err := users.FindByID(pt.ctxA, user.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("FindByID of another tenant's user returned %v, want ErrRecordNotFound", err)
+	}
+	if _, err := users.FindByID(pt.ctxB, user.ID); err != nil {
+		t.Fatalf("FindByID of own user failed: %v", err)
+	}
+}
+
+func TestPluginOnlyAllTenantsOptsOut(t *testing.T) {
+	pt := newPluginTest(t)
+
+	pt.create(t, pt.ctxA, "a")
+	pt.create(t, pt.ctxB, "b")
+
+	if names := pt.names(t, pt.db.WithContext(tenancy.WithAllTenants(context.Background()))); len(names) != 2 {
+		t.Fatalf("WithAllTenants sees %v, want both tenants' rows", names)
+	}
+
+	for name, db := range map[string]*gorm.DB{
+		"filter on another tenant": pt.db.WithContext(pt.ctxA).Where("tenant_id = ?", pt.tenantB),
+		"unscoped":                 pt.db.WithContext(pt.ctxA).Unscoped(),
+		"skipped hooks":            pt.db.WithContext(pt.ctxA).Session(&gorm.Session{SkipHooks: true}),
+		"nil tenant":               pt.db.WithContext(tenancy.WithTenant(context.Background(), uuid.Nil)),
+	} {
+		if names := pt.names(t, db); names["b"] {
+			t.Errorf("%s: tenant A sees tenant B's rows: %v", name, names)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/josy-coder/adminsuite/internal/config"
 	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
 )
 
 func main() {
@@ -31,15 +32,35 @@ func main() {
 		&models.Role{},
 		&models.Permission{},
 		&models.Token{},
+		&models.Session{},
 		&models.PasswordReset{},
 		&models.LoginAttempt{},
 		&models.AuditLog{},
 		&models.APIKey{},
 		&models.Device{},
+		&models.AccessPolicy{},
+		&models.SigningKey{},
+		&models.OAuthClient{},
+		&models.OAuthScope{},
+		&models.OAuthConsent{},
+		&models.OAuthAuthorizationCode{},
+		&models.IdentityProvider{},
+		&models.FederatedIdentity{},
+		&models.FederatedLoginState{},
+		&models.SAMLProvider{},
+		&models.SAMLRequest{},
 	)
 	if err != nil {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
+	if err := hashStoredTokens(db, services.NewTokenHasher(cfg.TokenHashKey)); err != nil {
+		log.Fatalf("Failed to hash stored tokens: %v", err)
+	}
+
+	if err := flagAdminRole(db, cfg.DefaultTenantDomain); err != nil {
+		log.Fatalf("Failed to flag the Admin role: %v", err)
+	}
+
 	log.Println("Migrations completed successfully")
 }
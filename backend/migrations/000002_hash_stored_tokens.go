@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+// hashStoredTokens converts refresh and password reset tokens stored in
+// plaintext in tokens.token to keyed hashes and drops the plaintext column.
+// It does nothing once the column is gone.
+func hashStoredTokens(db *gorm.DB, tokenHasher *services.TokenHasher) error {
+	if !db.Migrator().HasColumn(&models.Token{}, "token") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			ID    uuid.UUID
+			Token string
+		}
+		if err := tx.Table("tokens").Select("id, token").Where("token IS NOT NULL AND token <> ''").Scan(&rows).Error; err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if err := tx.Table("tokens").Where("id = ?", row.ID).Update("token_hash", tokenHasher.Hash(row.Token)).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Migrator().DropColumn(&models.Token{}, "token")
+	})
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/josy-coder/adminsuite/internal/models"
+	services "github.com/josy-coder/adminsuite/internal/services/user_management"
+)
+
+// flagAdminRole marks the Admin role seeded for the default tenant, which
+// used to hold every permission by its name alone. Roles other tenants
+// named Admin are not trusted and stay ordinary roles. It does nothing once
+// a role is flagged.
+func flagAdminRole(db *gorm.DB, defaultDomain string) error {
+	var flagged int64
+	if err := db.Model(&models.Role{}).Where("is_admin = ?", true).Count(&flagged).Error; err != nil {
+		return err
+	}
+	if flagged > 0 {
+		return nil
+	}
+
+	var tenant models.Tenant
+	if err := db.Where("domain = ?", defaultDomain).First(&tenant).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return db.Model(&models.Role{}).
+		Where("tenant_id = ? AND name = ?", tenant.ID, services.AdminRoleName).
+		Update("is_admin", true).Error
+}